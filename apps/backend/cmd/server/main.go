@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"easywms/internal/config"
 	"easywms/internal/database"
+	"easywms/internal/handler"
+	"easywms/internal/migrate"
 	"easywms/internal/router"
 
 	"github.com/gin-gonic/gin"
@@ -24,12 +27,32 @@ func main() {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
 
+	// 数据库迁移子命令: server migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(db, os.Args[2:])
+		return
+	}
+
+	// 数据库结构落后于程序版本时拒绝启动
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(); err != nil {
+		log.Fatalf("%v; run `migrate up` first", err)
+	}
+
 	// 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
 
 	// 初始化路由
 	r := router.SetupRouter(cfg, db)
 
+	// 启动循环盘点计划任务
+	if cfg.CycleCount.Enabled {
+		handler.NewCycleCountHandler(db, cfg).StartScheduler()
+	}
+
 	// 启动服务器
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	log.Printf("Server starting on %s", addr)
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"easywms/internal/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `用法: server migrate <command>
+
+命令:
+  up        执行所有未执行的迁移
+  down [n]  回滚最近执行的 n 个迁移，默认 1 个
+  status    查看迁移执行状态`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+		for _, mig := range done {
+			log.Printf("Applied %04d_%s", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(done) == 0 {
+			log.Println("Database schema is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("Invalid step count: %s", args[1])
+			}
+		}
+		for i := 0; i < steps; i++ {
+			mig, err := migrator.Down()
+			if err != nil {
+				log.Fatalf("Rollback failed: %v", err)
+			}
+			if mig == nil {
+				log.Println("No migration to roll back")
+				break
+			}
+			log.Printf("Rolled back %04d_%s", mig.Version, mig.Name)
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,42 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyScopeGuardsEveryRoute(t *testing.T) {
+	s := apitest.New(t)
+
+	var outbound document
+	s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+		"purpose": "研发测试领用",
+		"items":   []gin.H{{"productId": apitest.ProductGlove, "quantity": 5}},
+	}).OK().Decode(&outbound)
+	outboundPath := fmt.Sprintf("/api/outbounds/%d", outbound.ID)
+
+	// 仓管员拥有审批和发货权限，但只读密钥不能修改出库单
+	readOnly := s.APIKey(apitest.Keeper, "OUTBOUND_VIEW")
+	readOnly.Get("/api/outbounds").OK()
+	readOnly.Get(outboundPath).OK()
+	readOnly.Put(outboundPath, gin.H{"status": "approved", "purpose": "研发测试领用"}).
+		Fails(http.StatusForbidden, "API密钥无权调用该接口")
+	readOnly.Delete(outboundPath).Fails(http.StatusForbidden, "API密钥无权调用该接口")
+	readOnly.Get("/api/products").Fails(http.StatusForbidden, "API密钥无权调用该接口")
+
+	// 个人账号等未登记的接口，即使密钥权限齐全也不能调用
+	readOnly.Get("/api/user/info").Fails(http.StatusForbidden, "API密钥无权调用该接口")
+
+	// 密钥权限不能超出绑定用户角色的权限
+	s.APIKey(apitest.Staff, "OUTBOUND_VIEW", "OUTBOUND_APPROVE", "OUTBOUND_EXECUTE").
+		Put(outboundPath, gin.H{"status": "approved", "purpose": "研发测试领用"}).
+		Fails(http.StatusForbidden, "API密钥无权调用该接口")
+
+	s.APIKey(apitest.Keeper, "OUTBOUND_VIEW", "OUTBOUND_APPROVE", "OUTBOUND_EXECUTE").
+		Put(outboundPath, gin.H{"status": "approved", "purpose": "研发测试领用"}).OK()
+}
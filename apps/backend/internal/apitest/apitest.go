@@ -0,0 +1,352 @@
+// Package apitest 接口测试工具：在迁移好的临时 SQLite 内存库上启动完整路由，
+// 预置部门、用户、供应商和产品等基础数据，并按角色发起已登录的请求
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"easywms/internal/config"
+	"easywms/internal/database"
+	"easywms/internal/middleware"
+	"easywms/internal/migrate"
+	"easywms/internal/model"
+	"easywms/internal/router"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Password 所有预置用户的登录密码
+const Password = "123456"
+
+// Role 预置用户，值为登录用户名
+type Role string
+
+// 预置用户，分别对应基线数据中的四个内置角色
+const (
+	Admin  Role = "admin"  // 系统管理员 ADMIN，基线数据自带
+	Buyer  Role = "buyer"  // 采购专员 BUYER
+	Keeper Role = "keeper" // 仓库管理员 W_MGR
+	Staff  Role = "staff"  // 部门员工 STAFF，属于研发部
+)
+
+// 预置数据的ID
+const (
+	DeptWarehouse int64 = 2 // 仓储部
+	DeptRD        int64 = 3 // 研发部
+
+	SupplierID int64 = 1
+
+	CategoryID   int64 = 1
+	ProductBolt  int64 = 1 // 螺栓，基本单位“个”，1盒折合50个，初始库存0
+	ProductGlove int64 = 2 // 手套，基本单位“双”，初始库存20
+)
+
+// Server 测试用的完整后端服务
+type Server struct {
+	t      *testing.T
+	Router *gin.Engine
+	DB     *gorm.DB
+	tokens map[Role]string
+}
+
+// New 创建临时数据库，执行迁移、预置基础数据并启动路由，测试结束时关闭数据库
+func New(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: database.DriverSQLite, File: ":memory:"},
+		JWT:      config.JWTConfig{Secret: "apitest-secret"},
+	}
+	db, err := database.InitDB(cfg)
+	if err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	if err := seed(db); err != nil {
+		t.Fatalf("seed fixtures: %v", err)
+	}
+
+	return &Server{
+		t:      t,
+		Router: router.SetupRouter(cfg, db),
+		DB:     db,
+		tokens: make(map[Role]string),
+	}
+}
+
+// seed 预置基础数据，用户密码与基线中的管理员相同
+func seed(db *gorm.DB) error {
+	statements := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO base_department (id, name, parent_id) VALUES (?, '仓储部', 1), (?, '研发部', 1)",
+			[]interface{}{DeptWarehouse, DeptRD}},
+		{"INSERT INTO sys_user (username, password, real_name, dept_id, role_code, status) " +
+			"SELECT ?, password, '采购员', ?, 'BUYER', 1 FROM sys_user WHERE username = 'admin'",
+			[]interface{}{string(Buyer), DeptWarehouse}},
+		{"INSERT INTO sys_user (username, password, real_name, dept_id, role_code, status) " +
+			"SELECT ?, password, '仓管员', ?, 'W_MGR', 1 FROM sys_user WHERE username = 'admin'",
+			[]interface{}{string(Keeper), DeptWarehouse}},
+		{"INSERT INTO sys_user (username, password, real_name, dept_id, role_code, status) " +
+			"SELECT ?, password, '研发员工', ?, 'STAFF', 1 FROM sys_user WHERE username = 'admin'",
+			[]interface{}{string(Staff), DeptRD}},
+		{"INSERT INTO base_supplier (id, name, contact, phone, status) VALUES (?, '五金供应商', '张三', '13800000000', 1)",
+			[]interface{}{SupplierID}},
+		{"INSERT INTO base_category (id, name, parent_id) VALUES (?, '五金耗材', 0)",
+			[]interface{}{CategoryID}},
+		{"INSERT INTO base_product (id, category_id, sku_code, name, unit, stock_qty, alert_threshold, status) VALUES " +
+			"(?, ?, 'SKU-BOLT', '螺栓', '个', 0, 10, 1), (?, ?, 'SKU-GLOVE', '手套', '双', 20, 5, 1)",
+			[]interface{}{ProductBolt, CategoryID, ProductGlove, CategoryID}},
+		{"INSERT INTO base_product_unit (product_id, unit, factor) VALUES (?, '盒', 50)",
+			[]interface{}{ProductBolt}},
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range statements {
+			if err := tx.Exec(s.sql, s.args...).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Guest 返回未登录的 Client，用于访问登录等公开接口
+func (s *Server) Guest() *Client {
+	return &Client{s: s}
+}
+
+// As 以预置用户身份发起请求，首次使用时登录
+func (s *Server) As(role Role) *Client {
+	s.t.Helper()
+	token, ok := s.tokens[role]
+	if !ok {
+		resp := s.Guest().Post("/api/auth/login", gin.H{"username": string(role), "password": Password}).OK()
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		resp.Decode(&data)
+		if data.AccessToken == "" {
+			s.t.Fatalf("login as %s: %s", role, resp.Body)
+		}
+		token = data.AccessToken
+		s.tokens[role] = token
+	}
+	return &Client{s: s, token: token}
+}
+
+// APIKey 为预置用户创建指定权限范围的API密钥，返回用该密钥发起请求的 Client
+func (s *Server) APIKey(role Role, permissions ...string) *Client {
+	s.t.Helper()
+	key, err := utils.RandomToken(24)
+	if err != nil {
+		s.t.Fatalf("generate api key: %v", err)
+	}
+	userID := s.UserID(role)
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		record := map[string]interface{}{
+			"name": "apitest", "key_prefix": key[:8], "key_hash": utils.HashToken(key),
+			"user_id": userID, "status": 1, "created_by": userID,
+		}
+		if err := tx.Table("sys_api_key").Create(record).Error; err != nil {
+			return err
+		}
+		var keyID int64
+		if err := tx.Table("sys_api_key").Where("key_hash = ?", utils.HashToken(key)).Pluck("id", &keyID).Error; err != nil {
+			return err
+		}
+		for _, code := range permissions {
+			if err := tx.Table("sys_api_key_permission").Create(map[string]interface{}{
+				"api_key_id": keyID, "permission_code": code,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.t.Fatalf("create api key: %v", err)
+	}
+	return s.Guest().WithHeader(middleware.APIKeyHeader, key)
+}
+
+// StockQty 产品当前库存
+func (s *Server) StockQty(productID int64) float64 {
+	s.t.Helper()
+	var product model.Product
+	if err := s.DB.Unscoped().First(&product, productID).Error; err != nil {
+		s.t.Fatalf("load product %d: %v", productID, err)
+	}
+	return product.StockQty
+}
+
+// StockLogs 单据产生的库存流水，按记录顺序排列
+func (s *Server) StockLogs(relatedNo string) []model.StockLog {
+	s.t.Helper()
+	var logs []model.StockLog
+	if err := s.DB.Where("related_no = ?", relatedNo).Order("id").Find(&logs).Error; err != nil {
+		s.t.Fatalf("load stock logs of %s: %v", relatedNo, err)
+	}
+	return logs
+}
+
+// CountStockLogs 库存流水总数
+func (s *Server) CountStockLogs() int64 {
+	s.t.Helper()
+	var count int64
+	if err := s.DB.Model(&model.StockLog{}).Count(&count).Error; err != nil {
+		s.t.Fatalf("count stock logs: %v", err)
+	}
+	return count
+}
+
+// UserID 预置用户的ID
+func (s *Server) UserID(role Role) int64 {
+	s.t.Helper()
+	var ids []int64
+	s.DB.Table("sys_user").Where("username = ?", string(role)).Pluck("id", &ids)
+	if len(ids) == 0 {
+		s.t.Fatalf("user %s not found", role)
+	}
+	return ids[0]
+}
+
+// Client 以某个用户身份发起请求，未登录的 Client 只能访问公开接口
+type Client struct {
+	s      *Server
+	token  string
+	header http.Header
+}
+
+// WithHeader 返回附带请求头的 Client
+func (c *Client) WithHeader(key, value string) *Client {
+	header := c.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(key, value)
+	return &Client{s: c.s, token: c.token, header: header}
+}
+
+// Get 发起 GET 请求
+func (c *Client) Get(path string) *Response {
+	return c.Do(http.MethodGet, path, nil)
+}
+
+// Post 发起 POST 请求，body 编码为 JSON
+func (c *Client) Post(path string, body interface{}) *Response {
+	return c.Do(http.MethodPost, path, body)
+}
+
+// Put 发起 PUT 请求，body 编码为 JSON
+func (c *Client) Put(path string, body interface{}) *Response {
+	return c.Do(http.MethodPut, path, body)
+}
+
+// Delete 发起 DELETE 请求
+func (c *Client) Delete(path string) *Response {
+	return c.Do(http.MethodDelete, path, nil)
+}
+
+// Do 发起请求并解析统一响应结构
+func (c *Client) Do(method, path string, body interface{}) *Response {
+	t := c.s.t
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+
+	w := httptest.NewRecorder()
+	c.s.Router.ServeHTTP(w, req)
+
+	resp := &Response{t: t, Method: method, Path: path, Status: w.Code, Header: w.Header(), Body: w.Body.String()}
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err == nil {
+		resp.Code = envelope.Code
+		resp.Message = envelope.Message
+		resp.Data = envelope.Data
+	}
+	return resp
+}
+
+// Response 接口响应
+type Response struct {
+	t       *testing.T
+	Method  string
+	Path    string
+	Status  int
+	Header  http.Header
+	Body    string
+	Code    int
+	Message string
+	Data    json.RawMessage
+}
+
+// OK 断言请求成功
+func (r *Response) OK() *Response {
+	r.t.Helper()
+	if r.Status != http.StatusOK || r.Code != 0 {
+		r.t.Fatalf("%s %s: %d %s", r.Method, r.Path, r.Status, r.Body)
+	}
+	return r
+}
+
+// Fails 断言请求以指定状态码和提示失败
+func (r *Response) Fails(status int, message string) *Response {
+	r.t.Helper()
+	if r.Status != status || r.Message != message {
+		r.t.Fatalf("%s %s: got %d %q, want %d %q", r.Method, r.Path, r.Status, r.Message, status, message)
+	}
+	return r
+}
+
+// Decode 把响应中的 data 解析到 v
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		r.t.Fatalf("%s %s: decode data: %v; body %s", r.Method, r.Path, err, r.Body)
+	}
+	return r
+}
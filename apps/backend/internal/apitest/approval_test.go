@@ -0,0 +1,74 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestStatusTransitionsRequirePermission 审批、发货、下单和确认入库都要校验对应权限，员工只能提交申请
+func TestStatusTransitionsRequirePermission(t *testing.T) {
+	s := apitest.New(t)
+	glove := apitest.ProductGlove
+	staff := s.As(apitest.Staff)
+
+	// 出库：员工不能审批、驳回或发货自己的申请，但可以修改用途
+	var outbound document
+	staff.Post("/api/outbounds", gin.H{
+		"purpose": "领用",
+		"items":   []gin.H{{"productId": glove, "quantity": 5}},
+	}).OK().Decode(&outbound)
+	outboundPath := fmt.Sprintf("/api/outbounds/%d", outbound.ID)
+	staff.Put(outboundPath, gin.H{"status": "approved", "purpose": "领用"}).Fails(http.StatusForbidden, "无出库审核权限")
+	staff.Put(outboundPath, gin.H{"status": "cancelled", "purpose": "领用"}).Fails(http.StatusForbidden, "无出库审核权限")
+	staff.Put(outboundPath, gin.H{"status": "completed", "purpose": "领用"}).Fails(http.StatusForbidden, "无出库执行权限")
+	staff.Put(outboundPath, gin.H{"status": "pending", "purpose": "研发领用"}).OK()
+
+	s.As(apitest.Keeper).Put(outboundPath, gin.H{"status": "approved", "purpose": "研发领用"}).OK()
+	staff.Put(outboundPath, gin.H{"status": "completed", "purpose": "研发领用"}).Fails(http.StatusForbidden, "无出库执行权限")
+	wantStock(t, s, map[int64]float64{glove: 20})
+	s.As(apitest.Keeper).Put(outboundPath, gin.H{"status": "completed", "purpose": "研发领用"}).OK()
+	wantStock(t, s, map[int64]float64{glove: 15})
+
+	// 采购：采购员不能审批自己的申请，仓管员不能下单
+	var procurement document
+	s.As(apitest.Buyer).Post("/api/procurements", gin.H{
+		"supplierId": apitest.SupplierID,
+		"reason":     "补货",
+		"items":      []gin.H{{"productId": glove, "quantity": 10, "price": 3.5}},
+	}).OK().Decode(&procurement)
+	procurementPath := fmt.Sprintf("/api/procurements/%d", procurement.ID)
+	s.As(apitest.Buyer).Put(procurementPath, gin.H{"status": "APPROVED", "reason": "补货"}).
+		Fails(http.StatusForbidden, "无采购审批权限")
+	s.As(apitest.Buyer).Put(procurementPath, gin.H{"status": "PENDING", "reason": "季度补货"}).OK()
+	s.As(apitest.Admin).Put(procurementPath, gin.H{"status": "APPROVED", "reason": "季度补货"}).OK()
+	s.As(apitest.Keeper).Put(procurementPath, gin.H{"status": "ORDERED", "reason": "季度补货"}).
+		Fails(http.StatusForbidden, "无采购下单权限")
+	s.As(apitest.Buyer).Put(procurementPath, gin.H{"status": "ORDERED", "reason": "季度补货"}).OK()
+
+	var procurementDetail struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	s.As(apitest.Buyer).Get(procurementPath).OK().Decode(&procurementDetail)
+	if procurementDetail.Status != "ORDERED" || procurementDetail.Reason != "季度补货" {
+		t.Errorf("procurement = %+v", procurementDetail)
+	}
+
+	// 入库：员工和采购员都不能确认入库
+	var inbound document
+	s.As(apitest.Keeper).Post("/api/inbounds", gin.H{
+		"items": []gin.H{{"productId": glove, "quantity": 10}},
+	}).OK().Decode(&inbound)
+	inboundPath := fmt.Sprintf("/api/inbounds/%d", inbound.ID)
+	completion := gin.H{"status": "completed", "items": []gin.H{{"productId": glove, "quantity": 10}}}
+	staff.Put(inboundPath, completion).Fails(http.StatusForbidden, "无入库审核权限")
+	s.As(apitest.Buyer).Put(inboundPath, completion).Fails(http.StatusForbidden, "无入库审核权限")
+	wantStock(t, s, map[int64]float64{glove: 15})
+	s.As(apitest.Keeper).Put(inboundPath, completion).OK()
+	wantStock(t, s, map[int64]float64{glove: 25})
+}
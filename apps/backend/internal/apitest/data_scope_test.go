@@ -0,0 +1,97 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestStaffDataScope 部门员工只能看到和操作本部门的出库单，库存流水和仪表盘也按出库单归属过滤
+func TestStaffDataScope(t *testing.T) {
+	s := apitest.New(t)
+	glove := apitest.ProductGlove
+
+	issue := func(role apitest.Role, qty float64) document {
+		t.Helper()
+		var outbound document
+		s.As(role).Post("/api/outbounds", gin.H{
+			"purpose": "领用",
+			"items":   []gin.H{{"productId": glove, "quantity": qty}},
+		}).OK().Decode(&outbound)
+		path := fmt.Sprintf("/api/outbounds/%d", outbound.ID)
+		s.As(apitest.Keeper).Put(path, gin.H{"status": "approved", "purpose": "领用"}).OK()
+		s.As(apitest.Keeper).Put(path, gin.H{"status": "completed", "purpose": "领用"}).OK()
+		return outbound
+	}
+	own := issue(apitest.Staff, 2)
+	issue(apitest.Keeper, 3)
+
+	var pending document
+	s.As(apitest.Keeper).Post("/api/outbounds", gin.H{
+		"purpose": "仓储部领用",
+		"items":   []gin.H{{"productId": glove, "quantity": 1}},
+	}).OK().Decode(&pending)
+	pendingPath := fmt.Sprintf("/api/outbounds/%d", pending.ID)
+
+	// 其他部门的出库单不能查看、修改、删除和恢复
+	staff := s.As(apitest.Staff)
+	staff.Get(pendingPath).Fails(http.StatusNotFound, "出库单不存在")
+	staff.Put(pendingPath, gin.H{"purpose": "改用途"}).Fails(http.StatusNotFound, "出库单不存在")
+	staff.Delete(pendingPath).Fails(http.StatusNotFound, "出库单不存在")
+	s.As(apitest.Keeper).Delete(pendingPath).OK()
+	staff.Post(pendingPath+"/restore", nil).Fails(http.StatusNotFound, "已删除的出库单不存在")
+	s.As(apitest.Keeper).Post(pendingPath+"/restore", nil).OK()
+
+	// 库存流水只包含本部门出库单产生的流水
+	var logs struct {
+		Total int64 `json:"total"`
+		Items []struct {
+			RelatedNo string `json:"relatedNo"`
+		} `json:"items"`
+	}
+	staff.Get("/api/inventory/logs").OK().Decode(&logs)
+	if logs.Total != 1 || len(logs.Items) != 1 || logs.Items[0].RelatedNo != own.OrderNo {
+		t.Errorf("staff stock logs = %+v, want only %s", logs, own.OrderNo)
+	}
+	s.As(apitest.Keeper).Get("/api/inventory/logs").OK().Decode(&logs)
+	if logs.Total != 2 {
+		t.Errorf("keeper stock logs = %d, want 2", logs.Total)
+	}
+
+	var activities []struct {
+		OrderNo string `json:"orderNo"`
+	}
+	staff.Get("/api/dashboard/activities").OK().Decode(&activities)
+	if len(activities) != 1 || activities[0].OrderNo != own.OrderNo {
+		t.Errorf("staff activities = %+v, want only %s", activities, own.OrderNo)
+	}
+
+	var trend []struct {
+		Outbound float64 `json:"outbound"`
+	}
+	staff.Get("/api/dashboard/stock-trend").OK().Decode(&trend)
+	if len(trend) != 7 || trend[6].Outbound != 2 {
+		t.Errorf("staff stock trend = %+v, want 2 issued today", trend)
+	}
+
+	var overview struct {
+		PendingOutbound  int64 `json:"pendingOutbound"`
+		ProcurementCount int64 `json:"procurementCount"`
+	}
+	s.As(apitest.Buyer).Post("/api/procurements", gin.H{
+		"supplierId": apitest.SupplierID,
+		"items":      []gin.H{{"productId": glove, "quantity": 10, "price": 5}},
+	}).OK()
+	staff.Get("/api/dashboard/overview").OK().Decode(&overview)
+	if overview.ProcurementCount != 0 {
+		t.Errorf("staff overview = %+v, want no procurements", overview)
+	}
+	s.As(apitest.Buyer).Get("/api/dashboard/overview").OK().Decode(&overview)
+	if overview.ProcurementCount != 1 {
+		t.Errorf("buyer overview = %+v, want 1 procurement", overview)
+	}
+}
@@ -0,0 +1,89 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestKitIssueAndAssembly 套件领用按基本单位展开组件，组装消耗组件库存且只能完成一次
+func TestKitIssueAndAssembly(t *testing.T) {
+	s := apitest.New(t)
+	glove := apitest.ProductGlove
+	keeperID := s.UserID(apitest.Keeper)
+
+	var kit struct {
+		ID int64 `json:"id"`
+	}
+	s.As(apitest.Admin).Post("/api/products", gin.H{
+		"categoryId": apitest.CategoryID,
+		"code":       "SKU-KIT",
+		"name":       "防护套装",
+		"unit":       "套",
+		"status":     1,
+	}).OK().Decode(&kit)
+	s.As(apitest.Admin).Put(fmt.Sprintf("/api/products/%d/kit", kit.ID), gin.H{
+		"components": []gin.H{{"productId": glove, "quantity": 2}},
+	}).OK()
+
+	// 领用套件：数量必须为正，展开的明细带组件的基本单位
+	for _, qty := range []float64{0, -1} {
+		s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+			"kits": []gin.H{{"kitId": kit.ID, "quantity": qty}},
+		}).Fails(http.StatusBadRequest, "套件数量必须大于0")
+	}
+	s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+		"items": []gin.H{{"productId": glove, "quantity": -1}},
+	}).Fails(http.StatusBadRequest, "领用数量必须大于0")
+
+	var outbound document
+	s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+		"kits": []gin.H{{"kitId": kit.ID, "quantity": 3}},
+	}).OK().Decode(&outbound)
+	var detail struct {
+		Items []struct {
+			ProductID    int64    `json:"productId"`
+			Quantity     float64  `json:"quantity"`
+			Unit         string   `json:"unit"`
+			UnitQuantity *float64 `json:"unitQuantity"`
+			KitID        *int64   `json:"kitId"`
+		} `json:"items"`
+	}
+	s.As(apitest.Staff).Get(fmt.Sprintf("/api/outbounds/%d", outbound.ID)).OK().Decode(&detail)
+	if len(detail.Items) != 1 {
+		t.Fatalf("outbound items = %+v", detail.Items)
+	}
+	if item := detail.Items[0]; item.ProductID != glove || item.Quantity != 6 || item.Unit != "双" ||
+		item.UnitQuantity == nil || *item.UnitQuantity != 6 || item.KitID == nil || *item.KitID != kit.ID {
+		t.Errorf("exploded item = %+v", item)
+	}
+
+	// 组件库存不足时不能组装，组装单保持待处理
+	var short document
+	s.As(apitest.Keeper).Post("/api/kit-assemblies", gin.H{"kitId": kit.ID, "quantity": 11}).OK().Decode(&short)
+	s.As(apitest.Keeper).Put(fmt.Sprintf("/api/kit-assemblies/%d", short.ID), gin.H{"status": "completed"}).
+		Fails(http.StatusBadRequest, "组件库存不足: 手套")
+	wantStock(t, s, map[int64]float64{glove: 20, kit.ID: 0})
+	wantLogs(t, s, short.OrderNo, keeperID)
+
+	var assembly document
+	s.As(apitest.Keeper).Post("/api/kit-assemblies", gin.H{"kitId": kit.ID, "quantity": 4}).OK().Decode(&assembly)
+	assemblyPath := fmt.Sprintf("/api/kit-assemblies/%d", assembly.ID)
+	s.As(apitest.Keeper).Put(assemblyPath, gin.H{"status": "completed"}).OK()
+	wantStock(t, s, map[int64]float64{glove: 12, kit.ID: 4})
+	wantLogs(t, s, assembly.OrderNo, keeperID,
+		stockLog("KIT_OUT", glove, -8, 12),
+		stockLog("KIT_IN", kit.ID, 4, 4),
+	)
+
+	s.As(apitest.Keeper).Put(assemblyPath, gin.H{"status": "completed"}).
+		Fails(http.StatusBadRequest, "组装单已处理，不能修改")
+	wantStock(t, s, map[int64]float64{glove: 12, kit.ID: 4})
+
+	// 库存不足的组装单仍可取消
+	s.As(apitest.Keeper).Put(fmt.Sprintf("/api/kit-assemblies/%d", short.ID), gin.H{"status": "cancelled"}).OK()
+}
@@ -0,0 +1,395 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+	"easywms/internal/middleware"
+	"easywms/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// document 创建单据接口返回的单据
+type document struct {
+	ID      int64  `json:"id"`
+	OrderNo string `json:"orderNo"`
+	CheckNo string `json:"checkNo"`
+	Status  string `json:"status"`
+}
+
+// wantStock 断言产品库存
+func wantStock(t *testing.T, s *apitest.Server, stock map[int64]float64) {
+	t.Helper()
+	for productID, want := range stock {
+		if got := s.StockQty(productID); got != want {
+			t.Errorf("stock of product %d = %v, want %v", productID, got, want)
+		}
+	}
+}
+
+// wantLogs 断言单据产生的库存流水：类型、产品、变动数量和变动后库存
+func wantLogs(t *testing.T, s *apitest.Server, relatedNo string, operatorID int64, want ...model.StockLog) {
+	t.Helper()
+	got := s.StockLogs(relatedNo)
+	if len(got) != len(want) {
+		t.Fatalf("stock logs of %s = %+v, want %d rows", relatedNo, got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Type != w.Type || g.ProductID != w.ProductID || g.ChangeQty != w.ChangeQty || g.SnapshotQty != w.SnapshotQty {
+			t.Errorf("stock log %d of %s = {%s product %d change %v snapshot %v}, want {%s product %d change %v snapshot %v}",
+				i, relatedNo, g.Type, g.ProductID, g.ChangeQty, g.SnapshotQty, w.Type, w.ProductID, w.ChangeQty, w.SnapshotQty)
+		}
+		if g.OperatorID == nil || *g.OperatorID != operatorID {
+			t.Errorf("stock log %d of %s operator = %v, want %d", i, relatedNo, g.OperatorID, operatorID)
+		}
+	}
+}
+
+func stockLog(logType string, productID int64, change, snapshot float64) model.StockLog {
+	return model.StockLog{Type: logType, ProductID: productID, ChangeQty: change, SnapshotQty: snapshot}
+}
+
+// TestProcurementToInventoryCheckLifecycle 采购 → 入库 → 领用出库 → 盘点的完整流程，
+// 每一步后核对库存和库存流水
+func TestProcurementToInventoryCheckLifecycle(t *testing.T) {
+	s := apitest.New(t)
+	bolt, glove := apitest.ProductBolt, apitest.ProductGlove
+	keeperID := s.UserID(apitest.Keeper)
+
+	// 1. 采购员提交采购申请，管理员审批后下单；采购不影响库存
+	var procurement document
+	s.As(apitest.Buyer).Post("/api/procurements", gin.H{
+		"supplierId":   apitest.SupplierID,
+		"reason":       "季度备货",
+		"expectedDate": "2026-12-01",
+		"items": []gin.H{
+			{"productId": bolt, "quantity": 2, "price": 40, "unit": "盒"},
+			{"productId": glove, "quantity": 10, "price": 3.5},
+		},
+	}).OK().Decode(&procurement)
+	if procurement.OrderNo == "" || procurement.Status != "PENDING" {
+		t.Fatalf("procurement = %+v", procurement)
+	}
+	procurementPath := fmt.Sprintf("/api/procurements/%d", procurement.ID)
+	s.As(apitest.Admin).Put(procurementPath, gin.H{"status": "APPROVED", "supplierId": apitest.SupplierID}).OK()
+	s.As(apitest.Buyer).Put(procurementPath, gin.H{"status": "ORDERED", "supplierId": apitest.SupplierID}).OK()
+
+	var procurementDetail struct {
+		Status string `json:"status"`
+		Items  []struct {
+			ProductID int64    `json:"productId"`
+			Quantity  float64  `json:"quantity"`
+			Price     float64  `json:"price"`
+			UnitPrice *float64 `json:"unitPrice"`
+			Amount    float64  `json:"amount"`
+		} `json:"items"`
+	}
+	s.As(apitest.Buyer).Get(procurementPath).OK().Decode(&procurementDetail)
+	if procurementDetail.Status != "ORDERED" || len(procurementDetail.Items) != 2 || procurementDetail.Items[0].Quantity != 100 {
+		t.Fatalf("procurement detail = %+v", procurementDetail)
+	}
+	// 单价按基本单位换算保存，同时保留录入单位下的单价
+	if item := procurementDetail.Items[0]; item.Price != 0.8 || item.UnitPrice == nil || *item.UnitPrice != 40 || item.Amount != 80 {
+		t.Errorf("bolt procurement item = %+v", item)
+	}
+	wantStock(t, s, map[int64]float64{bolt: 0, glove: 20})
+	if n := s.CountStockLogs(); n != 0 {
+		t.Fatalf("stock logs after procurement = %d, want 0", n)
+	}
+
+	// 2. 仓管员按采购单到货入库：草稿不影响库存，确认入库后按基本单位增加库存
+	var inbound document
+	s.As(apitest.Keeper).Post("/api/inbounds", gin.H{
+		"sourceId": procurement.ID,
+		"items": []gin.H{
+			{"productId": bolt, "quantity": 2, "unit": "盒", "location": "A-01"},
+			{"productId": glove, "quantity": 10, "location": "A-02"},
+		},
+	}).OK().Decode(&inbound)
+	wantStock(t, s, map[int64]float64{bolt: 0, glove: 20})
+	wantLogs(t, s, inbound.OrderNo, keeperID)
+
+	inboundPath := fmt.Sprintf("/api/inbounds/%d", inbound.ID)
+	complete := s.As(apitest.Keeper).WithHeader(middleware.IdempotencyKeyHeader, "inbound-complete-1")
+	complete.Put(inboundPath, gin.H{"sourceId": procurement.ID, "status": "completed", "remark": "到货验收"}).OK()
+	// 网络重试重放首次结果，不会重复入库
+	replay := complete.Put(inboundPath, gin.H{"sourceId": procurement.ID, "status": "completed", "remark": "到货验收"}).OK()
+	if replay.Header.Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Errorf("retried completion was not replayed: %s", replay.Body)
+	}
+
+	wantStock(t, s, map[int64]float64{bolt: 100, glove: 30})
+	wantLogs(t, s, inbound.OrderNo, keeperID,
+		stockLog("IN", bolt, 100, 100),
+		stockLog("IN", glove, 10, 30),
+	)
+
+	var inboundList struct {
+		Items []struct {
+			OrderNo       string  `json:"orderNo"`
+			Status        string  `json:"status"`
+			Type          string  `json:"type"`
+			SourceOrderNo string  `json:"sourceOrderNo"`
+			TotalQuantity float64 `json:"totalQuantity"`
+		} `json:"items"`
+	}
+	s.As(apitest.Keeper).Get("/api/inbounds?status=completed").OK().Decode(&inboundList)
+	if len(inboundList.Items) != 1 || inboundList.Items[0].SourceOrderNo != procurement.OrderNo ||
+		inboundList.Items[0].Type != "purchase" || inboundList.Items[0].TotalQuantity != 110 {
+		t.Errorf("completed inbounds = %+v", inboundList.Items)
+	}
+	s.As(apitest.Keeper).Put(inboundPath, gin.H{"status": "completed"}).Fails(http.StatusBadRequest, "已完成的入库单不能修改")
+
+	// 3. 员工申领，仓管员审批、发货；发货后扣减库存
+	var outbound document
+	s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+		"purpose": "研发测试领用",
+		"items": []gin.H{
+			{"productId": bolt, "quantity": 30},
+			{"productId": glove, "quantity": 5},
+		},
+	}).OK().Decode(&outbound)
+	if outbound.Status != "PENDING" {
+		t.Fatalf("outbound = %+v", outbound)
+	}
+
+	outboundPath := fmt.Sprintf("/api/outbounds/%d", outbound.ID)
+	s.As(apitest.Keeper).Put(outboundPath, gin.H{"status": "approved", "purpose": "研发测试领用"}).OK()
+	wantStock(t, s, map[int64]float64{bolt: 100, glove: 30})
+	wantLogs(t, s, outbound.OrderNo, keeperID)
+
+	s.As(apitest.Keeper).Put(outboundPath, gin.H{"status": "completed", "purpose": "研发测试领用"}).OK()
+	wantStock(t, s, map[int64]float64{bolt: 70, glove: 25})
+	wantLogs(t, s, outbound.OrderNo, keeperID,
+		stockLog("OUT", bolt, -30, 70),
+		stockLog("OUT", glove, -5, 25),
+	)
+	// 重复发货不会再次扣减库存
+	s.As(apitest.Keeper).Put(outboundPath, gin.H{"status": "completed", "purpose": "研发测试领用"}).
+		Fails(http.StatusBadRequest, "已完成的出库单不能修改")
+	wantStock(t, s, map[int64]float64{bolt: 70, glove: 25})
+
+	var outboundDetail struct {
+		Status string `json:"status"`
+		Items  []struct {
+			PickedQuantity *float64 `json:"pickedQuantity"`
+		} `json:"items"`
+	}
+	s.As(apitest.Staff).Get(outboundPath).OK().Decode(&outboundDetail)
+	if len(outboundDetail.Items) != 2 || outboundDetail.Items[0].PickedQuantity == nil || *outboundDetail.Items[0].PickedQuantity != 30 {
+		t.Errorf("outbound detail = %+v", outboundDetail)
+	}
+
+	// 4. 冻结盘点：盘点期间冻结产品不能出入库，完成后只按盈亏差额调整库存
+	var check document
+	s.As(apitest.Keeper).Post("/api/inventory/checks", gin.H{
+		"freeze": true,
+		"remark": "月末盘点",
+		"items":  []gin.H{{"productId": bolt}, {"productId": glove}},
+	}).OK().Decode(&check)
+	if check.CheckNo == "" {
+		t.Fatalf("check = %+v", check)
+	}
+
+	var frozen document
+	s.As(apitest.Keeper).Post("/api/inbounds", gin.H{
+		"items": []gin.H{{"productId": bolt, "quantity": 1}},
+	}).OK().Decode(&frozen)
+	s.As(apitest.Keeper).Put(fmt.Sprintf("/api/inbounds/%d", frozen.ID), gin.H{"status": "completed"}).
+		Fails(http.StatusBadRequest, "产品正在盘点中("+check.CheckNo+")，暂不能入库")
+	wantStock(t, s, map[int64]float64{bolt: 70, glove: 25})
+
+	checkPath := fmt.Sprintf("/api/inventory/checks/%d", check.ID)
+	s.As(apitest.Keeper).Put(checkPath, gin.H{"status": "completed"}).
+		Fails(http.StatusBadRequest, "仍有未盘明细，不能完成盘点")
+	s.As(apitest.Keeper).Put(checkPath, gin.H{
+		"status": "completed",
+		"remark": "月末盘点",
+		"items": []gin.H{
+			{"productId": bolt, "actualQuantity": 68},
+			{"productId": glove, "actualQuantity": 25},
+		},
+	}).OK()
+
+	wantStock(t, s, map[int64]float64{bolt: 68, glove: 25})
+	wantLogs(t, s, check.CheckNo, keeperID, stockLog("ADJUST", bolt, -2, 68))
+
+	var checkDetail struct {
+		Status string `json:"status"`
+		Items  []struct {
+			ProductID          int64   `json:"productId"`
+			SystemQuantity     float64 `json:"systemQuantity"`
+			DifferenceQuantity float64 `json:"differenceQuantity"`
+		} `json:"items"`
+	}
+	s.As(apitest.Keeper).Get(checkPath).OK().Decode(&checkDetail)
+	if checkDetail.Status != "completed" || len(checkDetail.Items) != 2 ||
+		checkDetail.Items[0].SystemQuantity != 70 || checkDetail.Items[0].DifferenceQuantity != -2 {
+		t.Errorf("check detail = %+v", checkDetail)
+	}
+
+	// 盘点结束后解冻，此前被拦下的入库可以完成
+	s.As(apitest.Keeper).Put(fmt.Sprintf("/api/inbounds/%d", frozen.ID), gin.H{"status": "completed"}).OK()
+	wantStock(t, s, map[int64]float64{bolt: 69, glove: 25})
+	wantLogs(t, s, frozen.OrderNo, keeperID, stockLog("IN", bolt, 1, 69))
+
+	// 库存流水接口与库存余额一致
+	var logs struct {
+		Total int64 `json:"total"`
+	}
+	s.As(apitest.Admin).Get(fmt.Sprintf("/api/inventory/logs?productId=%d", bolt)).OK().Decode(&logs)
+	if logs.Total != 4 {
+		t.Errorf("bolt stock logs = %d, want 4", logs.Total)
+	}
+	var stock struct {
+		Quantity float64 `json:"quantity"`
+	}
+	s.As(apitest.Staff).Get(fmt.Sprintf("/api/inventory/stock/%d", bolt)).OK().Decode(&stock)
+	if stock.Quantity != 69 {
+		t.Errorf("bolt stock via API = %v, want 69", stock.Quantity)
+	}
+}
+
+// TestInventoryCheckBlindForCounter 没有监盘权限的员工看不到盘点账面数量，也不能发起复盘
+func TestInventoryCheckBlindForCounter(t *testing.T) {
+	s := apitest.New(t)
+
+	var check document
+	s.As(apitest.Keeper).Post("/api/inventory/checks", gin.H{
+		"tolerance": 1,
+		"items":     []gin.H{{"productId": apitest.ProductGlove}},
+	}).OK().Decode(&check)
+	checkPath := fmt.Sprintf("/api/inventory/checks/%d", check.ID)
+
+	var detail struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	s.As(apitest.Staff).Get(checkPath).OK().Decode(&detail)
+	if len(detail.Items) != 1 {
+		t.Fatalf("items = %+v", detail.Items)
+	}
+	if _, ok := detail.Items[0]["systemQuantity"]; ok {
+		t.Errorf("staff sees book quantity: %+v", detail.Items[0])
+	}
+
+	s.As(apitest.Keeper).Get(checkPath).OK().Decode(&detail)
+	if detail.Items[0]["systemQuantity"] != float64(20) {
+		t.Errorf("keeper item = %+v", detail.Items[0])
+	}
+
+	s.As(apitest.Staff).Post(checkPath+"/recount", nil).Fails(http.StatusForbidden, "无监盘权限")
+	wantStock(t, s, map[int64]float64{apitest.ProductGlove: 20})
+}
+
+// TestInventoryCheckRecount 超出容差的明细进入下一轮复盘，复盘轮次只接受被标记的明细
+func TestInventoryCheckRecount(t *testing.T) {
+	s := apitest.New(t)
+	bolt, glove := apitest.ProductBolt, apitest.ProductGlove
+	keeper := s.As(apitest.Keeper)
+
+	var check document
+	keeper.Post("/api/inventory/checks", gin.H{
+		"tolerance": 1,
+		"items":     []gin.H{{"productId": bolt}, {"productId": glove}},
+	}).OK().Decode(&check)
+	checkPath := fmt.Sprintf("/api/inventory/checks/%d", check.ID)
+
+	keeper.Put(checkPath, gin.H{
+		"items": []gin.H{{"productId": bolt, "actualQuantity": 0}, {"productId": glove, "actualQuantity": 15}},
+	}).OK()
+	keeper.Put(checkPath, gin.H{"status": "completed"}).Fails(http.StatusBadRequest, "存在超出容差的差异，请先发起复盘")
+
+	var recount struct {
+		Round        int `json:"round"`
+		RecountCount int `json:"recountCount"`
+	}
+	keeper.Post(checkPath+"/recount", nil).OK().Decode(&recount)
+	if recount.Round != 2 || recount.RecountCount != 1 {
+		t.Fatalf("recount = %+v", recount)
+	}
+
+	keeper.Put(checkPath, gin.H{
+		"items": []gin.H{{"productId": bolt, "actualQuantity": 3}},
+	}).Fails(http.StatusBadRequest, fmt.Sprintf("该产品不在第2轮复盘范围内: %d", bolt))
+	keeper.Put(checkPath, gin.H{
+		"status": "completed",
+		"items":  []gin.H{{"productId": glove, "actualQuantity": 16}},
+	}).OK()
+	wantStock(t, s, map[int64]float64{bolt: 0, glove: 16})
+	wantLogs(t, s, check.CheckNo, s.UserID(apitest.Keeper), stockLog("ADJUST", glove, -4, 16))
+
+	keeper.Post(checkPath+"/recount", nil).Fails(http.StatusBadRequest, "盘点已结束")
+}
+
+// TestInventoryCheckWithoutFreeze 不冻结的盘点：实盘前后发生的出库都保留，完成时只调整实盘差异
+func TestInventoryCheckWithoutFreeze(t *testing.T) {
+	s := apitest.New(t)
+	glove := apitest.ProductGlove
+	keeperID := s.UserID(apitest.Keeper)
+
+	issue := func(qty float64) {
+		t.Helper()
+		var outbound document
+		s.As(apitest.Staff).Post("/api/outbounds", gin.H{
+			"purpose": "领用",
+			"items":   []gin.H{{"productId": glove, "quantity": qty}},
+		}).OK().Decode(&outbound)
+		path := fmt.Sprintf("/api/outbounds/%d", outbound.ID)
+		s.As(apitest.Keeper).Put(path, gin.H{"status": "approved", "purpose": "领用"}).OK()
+		s.As(apitest.Keeper).Put(path, gin.H{"status": "completed", "purpose": "领用"}).OK()
+	}
+
+	var check document
+	s.As(apitest.Keeper).Post("/api/inventory/checks", gin.H{
+		"items": []gin.H{{"productId": glove}},
+	}).OK().Decode(&check)
+	checkPath := fmt.Sprintf("/api/inventory/checks/%d", check.ID)
+
+	// 快照20，实盘前出库5，实盘时点数14（丢失1），实盘后再出库3
+	issue(5)
+	s.As(apitest.Keeper).Put(checkPath, gin.H{
+		"items": []gin.H{{"productId": glove, "actualQuantity": 13}, {"productId": apitest.ProductBolt, "actualQuantity": 1}},
+	}).Fails(http.StatusBadRequest, fmt.Sprintf("盘点单中没有该产品: %d", apitest.ProductBolt))
+	s.As(apitest.Keeper).Put(checkPath, gin.H{
+		"items": []gin.H{{"productId": glove, "actualQuantity": 14}},
+	}).OK()
+	issue(3)
+	wantStock(t, s, map[int64]float64{glove: 12})
+
+	s.As(apitest.Keeper).Put(checkPath, gin.H{"status": "completed"}).OK()
+	wantStock(t, s, map[int64]float64{glove: 11})
+	wantLogs(t, s, check.CheckNo, keeperID, stockLog("ADJUST", glove, -1, 11))
+
+	// 已完成的盘点不能再录入，重复提交完成也不会再次调整库存
+	s.As(apitest.Keeper).Put(checkPath, gin.H{
+		"items": []gin.H{{"productId": glove, "actualQuantity": 10}},
+	}).Fails(http.StatusBadRequest, "盘点已结束，不能录入实盘数量")
+	s.As(apitest.Keeper).Put(checkPath, gin.H{"status": "completed"}).OK()
+	wantStock(t, s, map[int64]float64{glove: 11})
+	wantLogs(t, s, check.CheckNo, keeperID, stockLog("ADJUST", glove, -1, 11))
+
+	// 创建时直接给出实盘数量，之后的出库同样保留
+	var counted document
+	s.As(apitest.Keeper).Post("/api/inventory/checks", gin.H{
+		"items": []gin.H{{"productId": glove, "actualQuantity": 11}},
+	}).OK().Decode(&counted)
+	issue(2)
+	s.As(apitest.Keeper).Put(fmt.Sprintf("/api/inventory/checks/%d", counted.ID), gin.H{"status": "completed"}).OK()
+	wantStock(t, s, map[int64]float64{glove: 9})
+	wantLogs(t, s, counted.CheckNo, keeperID)
+}
+
+// TestCycleCountRequiresCheckPermission 重算ABC分类和生成循环盘点计划需要盘点权限
+func TestCycleCountRequiresCheckPermission(t *testing.T) {
+	s := apitest.New(t)
+
+	s.As(apitest.Staff).Post("/api/inventory/abc/classify", nil).Fails(http.StatusForbidden, "无权限访问")
+	s.As(apitest.Staff).Post("/api/inventory/cycle-count/plan", nil).Fails(http.StatusForbidden, "无权限访问")
+	s.As(apitest.Staff).Get("/api/inventory/cycle-count").OK()
+
+	s.As(apitest.Keeper).Post("/api/inventory/abc/classify", nil).OK()
+}
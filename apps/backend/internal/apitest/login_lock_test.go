@@ -0,0 +1,54 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestLoginLockout 同一账号连续失败5次后锁定，管理员解锁后恢复；IP阈值更高，不影响同一IP的其他账号
+func TestLoginLockout(t *testing.T) {
+	s := apitest.New(t)
+	admin := s.As(apitest.Admin)
+	guest := s.Guest()
+	login := func(username, password string) *apitest.Response {
+		return guest.Post("/api/auth/login", gin.H{"username": username, "password": password})
+	}
+
+	for i := 0; i < 5; i++ {
+		login(string(apitest.Keeper), "wrong-password").Fails(http.StatusUnauthorized, "用户名或密码错误")
+	}
+	// 锁定期内正确的密码也不能登录
+	login(string(apitest.Keeper), apitest.Password).Fails(http.StatusTooManyRequests, "登录失败次数过多，请5分钟后再试")
+	login(string(apitest.Buyer), apitest.Password).OK()
+
+	var locks []struct {
+		ID          int64  `json:"id"`
+		SubjectType string `json:"subjectType"`
+		Subject     string `json:"subject"`
+	}
+	admin.Get("/api/login-locks").OK().Decode(&locks)
+	if len(locks) != 1 || locks[0].SubjectType != "USERNAME" || locks[0].Subject != string(apitest.Keeper) {
+		t.Fatalf("login locks = %+v", locks)
+	}
+
+	admin.Post(fmt.Sprintf("/api/users/%d/unlock", s.UserID(apitest.Keeper)), nil).OK()
+	login(string(apitest.Keeper), apitest.Password).OK()
+
+	// 解锁账号不清零IP计数，同一IP累计失败20次后锁定该IP，所有账号都不能从该IP登录
+	for i := 0; i < 15; i++ {
+		login(fmt.Sprintf("nobody-%d", i), "wrong-password").Fails(http.StatusUnauthorized, "用户名或密码错误")
+	}
+	login(string(apitest.Staff), apitest.Password).Fails(http.StatusTooManyRequests, "登录失败次数过多，请5分钟后再试")
+
+	admin.Get("/api/login-locks").OK().Decode(&locks)
+	if len(locks) != 1 || locks[0].SubjectType != "IP" {
+		t.Fatalf("login locks = %+v", locks)
+	}
+	admin.Delete(fmt.Sprintf("/api/login-locks/%d", locks[0].ID)).OK()
+	login(string(apitest.Staff), apitest.Password).OK()
+}
@@ -0,0 +1,99 @@
+package apitest_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+)
+
+// TestTwoFactorLogin 启用两步验证后密码登录只返回临时令牌，凭验证码或一次性恢复码换取访问令牌
+func TestTwoFactorLogin(t *testing.T) {
+	s := apitest.New(t)
+	keeper := s.As(apitest.Keeper)
+
+	var setup struct {
+		Secret string `json:"secret"`
+	}
+	keeper.Post("/api/auth/2fa/setup", nil).OK().Decode(&setup)
+	keeper.Post("/api/auth/2fa/confirm", gin.H{"code": "12345"}).Fails(http.StatusBadRequest, "验证码错误")
+
+	totpCode := func() string {
+		t.Helper()
+		code, err := totp.GenerateCode(setup.Secret, time.Now())
+		if err != nil {
+			t.Fatalf("generate totp code: %v", err)
+		}
+		return code
+	}
+	var confirmed struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	keeper.Post("/api/auth/2fa/confirm", gin.H{"code": totpCode()}).OK().Decode(&confirmed)
+	if len(confirmed.RecoveryCodes) != 10 {
+		t.Fatalf("recovery codes = %v", confirmed.RecoveryCodes)
+	}
+
+	guest := s.Guest()
+	passwordStep := func() string {
+		t.Helper()
+		var step struct {
+			AccessToken string `json:"accessToken"`
+			MFARequired bool   `json:"mfaRequired"`
+			MFAToken    string `json:"mfaToken"`
+		}
+		guest.Post("/api/auth/login", gin.H{"username": string(apitest.Keeper), "password": apitest.Password}).OK().Decode(&step)
+		if !step.MFARequired || step.MFAToken == "" || step.AccessToken != "" {
+			t.Fatalf("password step = %+v", step)
+		}
+		return step.MFAToken
+	}
+	codeStep := func(mfaToken, code string) *apitest.Response {
+		return guest.Post("/api/auth/login/2fa", gin.H{"mfaToken": mfaToken, "code": code})
+	}
+	authorized := func(resp *apitest.Response) *apitest.Client {
+		t.Helper()
+		var tokens struct {
+			AccessToken string `json:"accessToken"`
+		}
+		resp.OK().Decode(&tokens)
+		if tokens.AccessToken == "" {
+			t.Fatalf("code step: %s", resp.Body)
+		}
+		return guest.WithHeader("Authorization", "Bearer "+tokens.AccessToken)
+	}
+
+	// 临时令牌不能当作访问令牌使用，伪造的临时令牌也不能通过第二步
+	mfaToken := passwordStep()
+	guest.WithHeader("Authorization", "Bearer "+mfaToken).Get("/api/user/info").
+		Fails(http.StatusUnauthorized, "认证令牌无效或已过期")
+	codeStep("forged", totpCode()).Fails(http.StatusUnauthorized, "登录已超时，请重新输入密码")
+	codeStep(mfaToken, "12345").Fails(http.StatusUnauthorized, "验证码错误")
+	authorized(codeStep(mfaToken, totpCode())).Get("/api/user/info").OK()
+
+	// 恢复码只能使用一次
+	recovery := confirmed.RecoveryCodes[0]
+	session := authorized(codeStep(passwordStep(), recovery))
+	codeStep(passwordStep(), recovery).Fails(http.StatusUnauthorized, "验证码错误")
+
+	var status struct {
+		Enabled            bool  `json:"enabled"`
+		RecoveryCodesCount int64 `json:"recoveryCodesCount"`
+	}
+	session.Get("/api/auth/2fa/status").OK().Decode(&status)
+	if !status.Enabled || status.RecoveryCodesCount != 9 {
+		t.Errorf("2fa status = %+v", status)
+	}
+
+	// 重新生成后旧恢复码全部作废
+	var regenerated struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	session.Post("/api/auth/2fa/recovery-codes", gin.H{"code": totpCode()}).OK().Decode(&regenerated)
+	codeStep(passwordStep(), confirmed.RecoveryCodes[1]).Fails(http.StatusUnauthorized, "验证码错误")
+	authorized(codeStep(passwordStep(), regenerated.RecoveryCodes[0])).Get("/api/user/info").OK()
+}
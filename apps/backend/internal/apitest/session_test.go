@@ -0,0 +1,63 @@
+package apitest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"easywms/internal/apitest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRefreshCookieIsSecure 未配置 cookieSecure 时，非 debug 模式下刷新令牌Cookie只允许 HTTPS 发送
+func TestRefreshCookieIsSecure(t *testing.T) {
+	s := apitest.New(t)
+
+	resp := s.Guest().Post("/api/auth/login", gin.H{"username": string(apitest.Keeper), "password": apitest.Password}).OK()
+	cookie := resp.Header.Get("Set-Cookie")
+	if !strings.HasPrefix(cookie, "refresh_token=") || !strings.Contains(cookie, "; Secure") || !strings.Contains(cookie, "; HttpOnly") {
+		t.Errorf("refresh cookie = %q", cookie)
+	}
+}
+
+// TestRefreshTokenReuseRevokesSession 已轮换的刷新令牌被再次使用视为泄露，该用户的全部会话立即失效
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := apitest.New(t)
+	guest := s.Guest()
+	admin := s.As(apitest.Admin)
+
+	var login struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	guest.Post("/api/auth/login", gin.H{"username": string(apitest.Keeper), "password": apitest.Password}).OK().Decode(&login)
+	bearer := func(token string) *apitest.Client {
+		return guest.WithHeader("Authorization", "Bearer "+token)
+	}
+
+	// 正常轮换：旧访问令牌在有效期内仍可用，新令牌可用
+	var rotated struct {
+		AccessToken  string `json:"data"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	resp := guest.Post("/api/auth/refresh", gin.H{"refreshToken": login.RefreshToken}).OK()
+	if err := json.Unmarshal([]byte(resp.Body), &rotated); err != nil || rotated.AccessToken == "" || rotated.RefreshToken == "" {
+		t.Fatalf("refresh: %s", resp.Body)
+	}
+	bearer(rotated.AccessToken).Get("/api/user/info").OK()
+
+	// 重放已轮换的刷新令牌，新旧访问令牌和最新的刷新令牌全部失效
+	guest.Post("/api/auth/refresh", gin.H{"refreshToken": login.RefreshToken}).
+		Fails(http.StatusUnauthorized, "刷新令牌已失效，请重新登录")
+	guest.Post("/api/auth/refresh", gin.H{"refreshToken": rotated.RefreshToken}).
+		Fails(http.StatusUnauthorized, "刷新令牌已失效，请重新登录")
+	bearer(rotated.AccessToken).Get("/api/user/info").Fails(http.StatusUnauthorized, "认证令牌已失效")
+	bearer(login.AccessToken).Get("/api/user/info").Fails(http.StatusUnauthorized, "认证令牌已失效")
+
+	// 其他用户的会话不受影响，该用户重新登录后恢复正常
+	admin.Get("/api/user/info").OK()
+	guest.Post("/api/auth/login", gin.H{"username": string(apitest.Keeper), "password": apitest.Password}).OK().Decode(&login)
+	bearer(login.AccessToken).Get("/api/user/info").OK()
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"log"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// 认证来源
+const (
+	SourceLocal = "local"
+	SourceLDAP  = "ldap"
+)
+
+var (
+	// ErrUserNotFound 认证器中不存在该账号，继续尝试下一个认证器
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidCredentials 账号存在但密码错误
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity 认证通过的用户身份
+type Identity struct {
+	Source     string
+	Username   string
+	Name       string
+	Email      string
+	RoleCode   string // 外部目录映射出的角色，为空表示未映射
+	Department string // 外部目录中的部门名称
+}
+
+// Authenticator 用户名密码认证器
+type Authenticator interface {
+	Name() string
+	Authenticate(username, password string) (*Identity, error)
+}
+
+// Chain 认证器链，按顺序尝试直到有一个认证通过
+type Chain []Authenticator
+
+// NewChain 按配置创建认证器链，未配置时只使用本地密码认证
+func NewChain(db *gorm.DB, cfg config.AuthConfig) Chain {
+	names := cfg.Authenticators
+	if len(names) == 0 {
+		names = []string{SourceLocal}
+	}
+
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case SourceLocal:
+			chain = append(chain, NewLocalAuthenticator(db))
+		case SourceLDAP:
+			chain = append(chain, NewLDAPAuthenticator(cfg.LDAP))
+		default:
+			log.Printf("auth: unknown authenticator %q ignored", name)
+		}
+	}
+	return chain
+}
+
+// Authenticate 依次尝试各认证器。全部失败时，只要有认证器确认账号存在就返回
+// ErrInvalidCredentials；目录服务不可用等错误在没有更明确的结果时原样返回
+func (ch Chain) Authenticate(username, password string) (*Identity, error) {
+	result := ErrUserNotFound
+	for _, a := range ch {
+		identity, err := a.Authenticate(username, password)
+		if err == nil {
+			return identity, nil
+		}
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+		case errors.Is(err, ErrInvalidCredentials):
+			result = ErrInvalidCredentials
+		default:
+			log.Printf("auth: %s authenticator: %v", a.Name(), err)
+			if errors.Is(result, ErrUserNotFound) {
+				result = err
+			}
+		}
+	}
+	return nil, result
+}
+
+// LocalAuthenticator 本地密码认证（sys_user 中的 bcrypt 密码）
+type LocalAuthenticator struct {
+	db *gorm.DB
+}
+
+// NewLocalAuthenticator 创建本地密码认证器
+func NewLocalAuthenticator(db *gorm.DB) *LocalAuthenticator {
+	return &LocalAuthenticator{db: db}
+}
+
+// Name 认证器名称
+func (a *LocalAuthenticator) Name() string {
+	return SourceLocal
+}
+
+// Authenticate 校验本地密码
+func (a *LocalAuthenticator) Authenticate(username, password string) (*Identity, error) {
+	var user model.User
+	if err := a.db.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !utils.CheckPassword(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{
+		Source:   SourceLocal,
+		Username: user.Username,
+		Name:     user.RealName,
+		Email:    user.Email,
+	}, nil
+}
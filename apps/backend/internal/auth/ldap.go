@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"easywms/internal/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// errLDAPNotConfigured 未配置LDAP地址
+var errLDAPNotConfigured = errors.New("ldap url is not configured")
+
+// LDAPAuthenticator LDAP/AD 认证：先用服务账号查出用户DN，再用用户密码绑定验证
+type LDAPAuthenticator struct {
+	cfg config.LDAPConfig
+}
+
+// NewLDAPAuthenticator 创建LDAP认证器，未配置的属性使用常见默认值
+func NewLDAPAuthenticator(cfg config.LDAPConfig) *LDAPAuthenticator {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = "cn"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = "memberOf"
+	}
+	return &LDAPAuthenticator{cfg: cfg}
+}
+
+// Name 认证器名称
+func (a *LDAPAuthenticator) Name() string {
+	return SourceLDAP
+}
+
+// Authenticate 在目录中查找用户并用其密码绑定
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*Identity, error) {
+	// 空密码在多数目录上会被当作匿名绑定而成功，必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if a.cfg.URL == "" {
+		return nil, errLDAPNotConfigured
+	}
+
+	conn, err := ldap.DialURL(a.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	defer conn.Close()
+
+	if a.cfg.StartTLS {
+		if err := conn.StartTLS(&tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify}); err != nil {
+			return nil, fmt.Errorf("ldap starttls: %w", err)
+		}
+	}
+
+	if a.cfg.BindDN != "" {
+		if err := conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+
+	attributes := []string{a.cfg.NameAttribute, a.cfg.EmailAttribute, a.cfg.GroupAttribute}
+	if a.cfg.DepartmentAttribute != "" {
+		attributes = append(attributes, a.cfg.DepartmentAttribute)
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(a.cfg.UserFilter, ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+	if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("ldap search: %d entries match %q", len(result.Entries), username)
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user bind: %w", err)
+	}
+
+	identity := &Identity{
+		Source:   SourceLDAP,
+		Username: username,
+		Name:     entry.GetAttributeValue(a.cfg.NameAttribute),
+		Email:    entry.GetAttributeValue(a.cfg.EmailAttribute),
+		RoleCode: a.mapRole(entry.GetAttributeValues(a.cfg.GroupAttribute)),
+	}
+	if a.cfg.DepartmentAttribute != "" {
+		identity.Department = entry.GetAttributeValue(a.cfg.DepartmentAttribute)
+	}
+	return identity, nil
+}
+
+// mapRole 按配置顺序取第一个匹配的组对应的角色，都不匹配时使用默认角色
+func (a *LDAPAuthenticator) mapRole(groups []string) string {
+	for _, mapping := range a.cfg.GroupRoles {
+		for _, group := range groups {
+			if strings.EqualFold(strings.TrimSpace(group), strings.TrimSpace(mapping.Group)) {
+				return mapping.Role
+			}
+		}
+	}
+	return a.cfg.DefaultRole
+}
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"easywms/internal/config"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// stubEntry LDAP桩中的目录条目
+type stubEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// ldapStub 进程内LDAP桩，只实现认证所需的 Bind、Search 和 Unbind
+type ldapStub struct {
+	listener net.Listener
+	entries  []stubEntry
+}
+
+const (
+	stubServiceDN       = "cn=readonly,dc=example,dc=com"
+	stubServicePassword = "readonly"
+)
+
+func newLDAPStub(t *testing.T) *ldapStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &ldapStub{
+		listener: listener,
+		entries: []stubEntry{
+			{
+				dn:       "uid=zhangsan,ou=people,dc=example,dc=com",
+				password: "secret",
+				attributes: map[string][]string{
+					"uid":              {"zhangsan"},
+					"cn":               {"张三"},
+					"mail":             {"zhangsan@example.com"},
+					"departmentNumber": {"仓储部"},
+					"memberOf": {
+						"cn=all-staff,ou=groups,dc=example,dc=com",
+						"CN=WMS-Warehouse,OU=Groups,DC=example,DC=com",
+					},
+				},
+			},
+			{
+				dn:       "uid=lisi,ou=people,dc=example,dc=com",
+				password: "secret",
+				attributes: map[string][]string{
+					"uid":  {"lisi"},
+					"cn":   {"李四"},
+					"mail": {"lisi@example.com"},
+				},
+			},
+		},
+	}
+	go stub.serve()
+	t.Cleanup(func() { listener.Close() })
+	return stub
+}
+
+func (s *ldapStub) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *ldapStub) handle(conn net.Conn) {
+	defer conn.Close()
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			code := ldap.LDAPResultInvalidCredentials
+			if dn == stubServiceDN && password == stubServicePassword {
+				code = ldap.LDAPResultSuccess
+			}
+			for _, e := range s.entries {
+				if e.dn == dn && e.password == password {
+					code = ldap.LDAPResultSuccess
+				}
+			}
+			if code == ldap.LDAPResultSuccess {
+				boundDN = dn
+			}
+			s.write(conn, messageID, resultPacket(ldap.ApplicationBindResponse, code))
+
+		case ldap.ApplicationSearchRequest:
+			if boundDN != stubServiceDN {
+				s.write(conn, messageID, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				s.write(conn, messageID, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultOperationsError))
+				continue
+			}
+			for _, e := range s.entries {
+				if filter == "(uid="+e.attributes["uid"][0]+")" {
+					s.write(conn, messageID, entryPacket(e))
+				}
+			}
+			s.write(conn, messageID, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *ldapStub) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	_, _ = conn.Write(packet.Bytes())
+}
+
+func resultPacket(tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return op
+}
+
+func entryPacket(e stubEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "objectName"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range e.attributes {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, v := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+func newStubAuthenticator(stub *ldapStub) *LDAPAuthenticator {
+	return NewLDAPAuthenticator(config.LDAPConfig{
+		URL:                 stub.url(),
+		BindDN:              stubServiceDN,
+		BindPassword:        stubServicePassword,
+		BaseDN:              "ou=people,dc=example,dc=com",
+		DepartmentAttribute: "departmentNumber",
+		GroupRoles: []config.LDAPGroupRole{
+			{Group: "cn=wms-admins,ou=groups,dc=example,dc=com", Role: "ADMIN"},
+			{Group: "cn=wms-warehouse,ou=groups,dc=example,dc=com", Role: "W_MGR"},
+		},
+		DefaultRole: "STAFF",
+	})
+}
+
+func TestLDAPAuthenticateMapsGroupAndDepartment(t *testing.T) {
+	a := newStubAuthenticator(newLDAPStub(t))
+
+	identity, err := a.Authenticate("zhangsan", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Source != SourceLDAP || identity.Username != "zhangsan" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+	if identity.Name != "张三" || identity.Email != "zhangsan@example.com" {
+		t.Fatalf("unexpected profile: %+v", identity)
+	}
+	if identity.RoleCode != "W_MGR" {
+		t.Fatalf("role = %q, want W_MGR (group DNs compare case-insensitively)", identity.RoleCode)
+	}
+	if identity.Department != "仓储部" {
+		t.Fatalf("department = %q, want 仓储部", identity.Department)
+	}
+}
+
+func TestLDAPAuthenticateDefaultRole(t *testing.T) {
+	a := newStubAuthenticator(newLDAPStub(t))
+
+	identity, err := a.Authenticate("lisi", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.RoleCode != "STAFF" || identity.Department != "" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+}
+
+func TestLDAPAuthenticateFailures(t *testing.T) {
+	a := newStubAuthenticator(newLDAPStub(t))
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+		want     error
+	}{
+		{"wrong password", "zhangsan", "wrong", ErrInvalidCredentials},
+		{"empty password", "zhangsan", "", ErrInvalidCredentials},
+		{"unknown user", "wangwu", "secret", ErrUserNotFound},
+		{"filter injection", "*", "secret", ErrUserNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := a.Authenticate(tc.username, tc.password)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateServiceBindFailure(t *testing.T) {
+	stub := newLDAPStub(t)
+	a := newStubAuthenticator(stub)
+	a.cfg.BindPassword = "wrong"
+
+	_, err := a.Authenticate("zhangsan", "secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("err = %v, want a service bind error", err)
+	}
+	if !strings.Contains(err.Error(), "service bind") {
+		t.Fatalf("err = %v, want a service bind error", err)
+	}
+}
+
+// fakeAuthenticator 固定返回结果的认证器
+type fakeAuthenticator struct {
+	name string
+	err  error
+}
+
+func (f fakeAuthenticator) Name() string { return f.name }
+
+func (f fakeAuthenticator) Authenticate(username, password string) (*Identity, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Identity{Source: f.name, Username: username}, nil
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	unavailable := errors.New("directory unavailable")
+
+	cases := []struct {
+		name       string
+		chain      Chain
+		wantSource string
+		wantErr    error
+	}{
+		{"falls through to ldap", Chain{fakeAuthenticator{"local", ErrUserNotFound}, fakeAuthenticator{"ldap", nil}}, "ldap", nil},
+		{"wrong local password still tries ldap", Chain{fakeAuthenticator{"local", ErrInvalidCredentials}, fakeAuthenticator{"ldap", nil}}, "ldap", nil},
+		{"first success wins", Chain{fakeAuthenticator{"local", nil}, fakeAuthenticator{"ldap", nil}}, "local", nil},
+		{"invalid beats not found", Chain{fakeAuthenticator{"local", ErrInvalidCredentials}, fakeAuthenticator{"ldap", ErrUserNotFound}}, "", ErrInvalidCredentials},
+		{"invalid beats outage", Chain{fakeAuthenticator{"ldap", unavailable}, fakeAuthenticator{"local", ErrInvalidCredentials}}, "", ErrInvalidCredentials},
+		{"outage reported", Chain{fakeAuthenticator{"local", ErrUserNotFound}, fakeAuthenticator{"ldap", unavailable}}, "", unavailable},
+		{"not found", Chain{fakeAuthenticator{"local", ErrUserNotFound}}, "", ErrUserNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := tc.chain.Authenticate("zhangsan", "secret")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity.Source != tc.wantSource {
+				t.Fatalf("source = %q, want %q", identity.Source, tc.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"easywms/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP 本地模拟的OIDC身份提供商
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+// mockGrant 已签发的授权码
+type mockGrant struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, codes: make(map[string]mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在身份提供商登录并同意授权，返回授权码
+func (idp *mockIdP) authorize(t *testing.T, authURL, subject, email string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("authorization url has no PKCE challenge: %s", authURL)
+	}
+
+	code = "code-" + subject
+	idp.mu.Lock()
+	idp.codes[code] = mockGrant{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		subject:   subject,
+		email:     email,
+	}
+	idp.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	idp.mu.Lock()
+	grant, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                "easywms",
+		"sub":                grant.subject,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              grant.nonce,
+		"email":              grant.email,
+		"email_verified":     true,
+		"preferred_username": strings.Split(grant.email, "@")[0],
+		"name":               "张三",
+	})
+	idToken.Header["kid"] = "test"
+	signed, err := idToken.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"access_token": "access-" + grant.subject,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestClient(idp *mockIdP) *OIDCClient {
+	return NewOIDCClient(config.OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    "easywms",
+		RedirectURL: "http://localhost/api/auth/oidc/callback",
+	})
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	client := newTestClient(idp)
+	ctx := context.Background()
+
+	verifier := GenerateCodeVerifier()
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := idp.authorize(t, authURL, "u-1001", "zhangsan@example.com")
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+
+	identity, err := client.Exchange(ctx, code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Subject != "u-1001" || identity.Email != "zhangsan@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+	if identity.Username != "zhangsan" || identity.Name != "张三" {
+		t.Fatalf("unexpected profile claims: %+v", identity)
+	}
+}
+
+func TestOIDCRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	client := newTestClient(idp)
+	ctx := context.Background()
+
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", GenerateCodeVerifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := idp.authorize(t, authURL, "u-1001", "zhangsan@example.com")
+
+	if _, err := client.Exchange(ctx, code, GenerateCodeVerifier(), "nonce-1"); err == nil {
+		t.Fatal("expected exchange with a different code_verifier to fail")
+	}
+}
+
+func TestOIDCRejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	client := newTestClient(idp)
+	ctx := context.Background()
+
+	verifier := GenerateCodeVerifier()
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := idp.authorize(t, authURL, "u-1001", "zhangsan@example.com")
+
+	if _, err := client.Exchange(ctx, code, verifier, "nonce-2"); err == nil {
+		t.Fatal("expected nonce mismatch to fail")
+	}
+}
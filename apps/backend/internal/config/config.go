@@ -3,27 +3,43 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
-	CORS     CORSConfig     `mapstructure:"cors"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	Log         LogConfig         `mapstructure:"log"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+	CycleCount  CycleCountConfig  `mapstructure:"cycleCount"`
+	Login       LoginConfig       `mapstructure:"login"`
+	MFA         MFAConfig         `mapstructure:"mfa"`
+	Password    PasswordConfig    `mapstructure:"password"`
+	OIDC        OIDCConfig        `mapstructure:"oidc"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Numbering   NumberingConfig   `mapstructure:"numbering"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// TrustedProxies 可信反向代理的IP或网段，只有来自这些地址的请求才采用
+	// X-Forwarded-For 中的客户端IP；默认为空，即始终使用连接的对端地址
+	TrustedProxies []string `mapstructure:"trustedProxies"`
+	// CookieSecure 刷新令牌等Cookie是否只在 HTTPS 下发送，未配置时仅 debug 模式下关闭
+	CookieSecure *bool `mapstructure:"cookieSecure"`
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Driver       string `mapstructure:"driver"` // mysql(默认) 或 sqlite
+	File         string `mapstructure:"file"`   // SQLite 数据库文件，:memory: 为内存库
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
 	Username     string `mapstructure:"username"`
@@ -38,8 +54,29 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret      string `mapstructure:"secret"`
-	ExpireHours int    `mapstructure:"expireHours"`
+	Secret              string `mapstructure:"secret"`
+	ExpireHours         int    `mapstructure:"expireHours"`
+	AccessExpireMinutes int    `mapstructure:"accessExpireMinutes"`
+	RefreshExpireHours  int    `mapstructure:"refreshExpireHours"`
+}
+
+// AccessTTL 访问令牌有效期，未配置 accessExpireMinutes 时沿用 expireHours
+func (c JWTConfig) AccessTTL() time.Duration {
+	if c.AccessExpireMinutes > 0 {
+		return time.Duration(c.AccessExpireMinutes) * time.Minute
+	}
+	if c.ExpireHours > 0 {
+		return time.Duration(c.ExpireHours) * time.Hour
+	}
+	return 30 * time.Minute
+}
+
+// RefreshTTL 刷新令牌有效期，默认7天
+func (c JWTConfig) RefreshTTL() time.Duration {
+	if c.RefreshExpireHours > 0 {
+		return time.Duration(c.RefreshExpireHours) * time.Hour
+	}
+	return 7 * 24 * time.Hour
 }
 
 // LogConfig 日志配置
@@ -62,6 +99,103 @@ type CORSConfig struct {
 	MaxAge           int      `mapstructure:"maxAge"`
 }
 
+// CycleCountConfig 循环盘点配置
+type CycleCountConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	IntervalHours int     `mapstructure:"intervalHours"`
+	LookbackDays  int     `mapstructure:"lookbackDays"`
+	ClassAPercent float64 `mapstructure:"classAPercent"`
+	ClassBPercent float64 `mapstructure:"classBPercent"`
+	ClassAMonths  int     `mapstructure:"classAMonths"`
+	ClassBMonths  int     `mapstructure:"classBMonths"`
+	ClassCMonths  int     `mapstructure:"classCMonths"`
+	Freeze        bool    `mapstructure:"freeze"`
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	MaxFailures    int `mapstructure:"maxFailures"`
+	MaxIPFailures  int `mapstructure:"maxIPFailures"`
+	WindowMinutes  int `mapstructure:"windowMinutes"`
+	LockMinutes    int `mapstructure:"lockMinutes"`
+	MaxLockMinutes int `mapstructure:"maxLockMinutes"`
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer        string   `mapstructure:"issuer"`
+	RequiredRoles []string `mapstructure:"requiredRoles"`
+}
+
+// PasswordConfig 密码策略配置
+type PasswordConfig struct {
+	MinLength          int  `mapstructure:"minLength"`
+	RequireUpper       bool `mapstructure:"requireUpper"`
+	RequireLower       bool `mapstructure:"requireLower"`
+	RequireDigit       bool `mapstructure:"requireDigit"`
+	RequireSymbol      bool `mapstructure:"requireSymbol"`
+	HistoryCount       int  `mapstructure:"historyCount"`
+	ExpireDays         int  `mapstructure:"expireDays"`
+	ForceChangeOnReset bool `mapstructure:"forceChangeOnReset"`
+}
+
+// OIDCConfig 企业身份提供商单点登录配置
+type OIDCConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	Issuer            string   `mapstructure:"issuer"`
+	ClientID          string   `mapstructure:"clientId"`
+	ClientSecret      string   `mapstructure:"clientSecret"`
+	RedirectURL       string   `mapstructure:"redirectUrl"`
+	Scopes            []string `mapstructure:"scopes"`
+	PostLoginRedirect string   `mapstructure:"postLoginRedirect"`
+	MatchByEmail      bool     `mapstructure:"matchByEmail"`
+	AutoProvision     bool     `mapstructure:"autoProvision"`
+	DefaultRole       string   `mapstructure:"defaultRole"`
+	DefaultDeptID     int64    `mapstructure:"defaultDeptId"`
+}
+
+// AuthConfig 用户名密码登录认证配置
+type AuthConfig struct {
+	Authenticators []string   `mapstructure:"authenticators"` // 按顺序尝试: local, ldap
+	LDAP           LDAPConfig `mapstructure:"ldap"`
+}
+
+// LDAPConfig LDAP/AD 认证配置
+type LDAPConfig struct {
+	URL                 string          `mapstructure:"url"`
+	StartTLS            bool            `mapstructure:"startTLS"`
+	InsecureSkipVerify  bool            `mapstructure:"insecureSkipVerify"`
+	BindDN              string          `mapstructure:"bindDN"`
+	BindPassword        string          `mapstructure:"bindPassword"`
+	BaseDN              string          `mapstructure:"baseDN"`
+	UserFilter          string          `mapstructure:"userFilter"`
+	NameAttribute       string          `mapstructure:"nameAttribute"`
+	EmailAttribute      string          `mapstructure:"emailAttribute"`
+	GroupAttribute      string          `mapstructure:"groupAttribute"`
+	DepartmentAttribute string          `mapstructure:"departmentAttribute"`
+	GroupRoles          []LDAPGroupRole `mapstructure:"groupRoles"`
+	DefaultRole         string          `mapstructure:"defaultRole"`
+	DefaultDeptID       int64           `mapstructure:"defaultDeptId"`
+	AutoProvision       bool            `mapstructure:"autoProvision"`
+}
+
+// LDAPGroupRole LDAP组与角色的映射，按配置顺序取第一个匹配
+type LDAPGroupRole struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
+// NumberingConfig 单据编号配置
+type NumberingConfig struct {
+	Warehouse string            `mapstructure:"warehouse"` // 仓库代码，对应格式中的 {wh}
+	Formats   map[string]string `mapstructure:"formats"`   // 按单据类型配置编号格式
+}
+
+// IdempotencyConfig 幂等键配置
+type IdempotencyConfig struct {
+	TTLHours int `mapstructure:"ttlHours"` // 首次响应的保留时长，默认24小时
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config")
@@ -87,6 +221,12 @@ func LoadConfig() (*Config, error) {
 // overrideFromEnv 从环境变量覆盖配置
 func overrideFromEnv(config *Config) {
 	// 数据库配置
+	if v := getEnv("DB_DRIVER"); v != "" {
+		config.Database.Driver = v
+	}
+	if v := getEnv("DB_FILE"); v != "" {
+		config.Database.File = v
+	}
 	if v := getEnv("DB_HOST"); v != "" {
 		config.Database.Host = v
 	}
@@ -7,27 +7,28 @@ import (
 	"easywms/internal/config"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// 支持的数据库驱动
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
 // DB 全局数据库连接
 var DB *gorm.DB
 
 // InitDB 初始化数据库连接
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
-		cfg.Database.Username,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DBName,
-		cfg.Database.Charset,
-		cfg.Database.ParseTime,
-		cfg.Database.Loc,
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+	dialector, err := Dialector(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
@@ -40,11 +41,47 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	// 设置连接池
-	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	if dialector.Name() == DriverSQLite {
+		// SQLite 同时只允许一个写入者，内存库的每个连接又是独立的数据库，
+		// 因此只保留一个常驻连接
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	}
 
-	log.Println("Database connected successfully")
+	log.Printf("Database connected successfully (%s)", dialector.Name())
 	DB = db
 	return db, nil
 }
 
+// Dialector 按配置的驱动创建数据库方言，未配置驱动时使用 MySQL
+func Dialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		// clientFoundRows 使条件更新的影响行数按命中行计算，值未变化的更新不会被误判为未命中
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s&clientFoundRows=true",
+			cfg.Username,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.DBName,
+			cfg.Charset,
+			cfg.ParseTime,
+			cfg.Loc,
+		)
+		return mysql.Open(dsn), nil
+
+	case DriverSQLite:
+		file := cfg.File
+		if file == "" {
+			file = "easywms.db"
+		}
+		// 等待锁而不是立即返回 database is locked
+		return sqlite.Open(file + "?_busy_timeout=5000"), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
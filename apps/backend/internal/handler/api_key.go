@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix API密钥前缀，便于识别和扫描泄露
+const apiKeyPrefix = "ewms_"
+
+// APIKey API密钥模型（只保存摘要）
+type APIKey struct {
+	ID         int64      `json:"id" gorm:"column:id;primaryKey"`
+	Name       string     `json:"name" gorm:"column:name"`
+	KeyPrefix  string     `json:"keyPrefix" gorm:"column:key_prefix"` // 明文前几位，用于辨认
+	KeyHash    string     `json:"-" gorm:"column:key_hash"`
+	UserID     int64      `json:"userId" gorm:"column:user_id"` // 调用时代表的用户
+	Status     int        `json:"status" gorm:"column:status"`
+	ExpiresAt  *time.Time `json:"expireTime" gorm:"column:expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedTime" gorm:"column:last_used_at"`
+	LastUsedIP string     `json:"lastUsedIp" gorm:"column:last_used_ip"`
+	CreatedBy  int64      `json:"createdBy" gorm:"column:created_by"`
+	CreatedAt  time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	// 关联字段
+	Username    string   `json:"username" gorm:"-"`
+	Permissions []string `json:"permissions" gorm:"-"`
+}
+
+func (APIKey) TableName() string {
+	return "sys_api_key"
+}
+
+// APIKeyPermission API密钥权限范围
+type APIKeyPermission struct {
+	ID             int64  `json:"id" gorm:"column:id;primaryKey"`
+	APIKeyID       int64  `json:"apiKeyId" gorm:"column:api_key_id"`
+	PermissionCode string `json:"permissionCode" gorm:"column:permission_code"`
+}
+
+func (APIKeyPermission) TableName() string {
+	return "sys_api_key_permission"
+}
+
+// APIKeyLog API密钥调用日志
+type APIKeyLog struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey"`
+	APIKeyID   int64     `json:"apiKeyId" gorm:"column:api_key_id"`
+	Method     string    `json:"method" gorm:"column:method"`
+	Path       string    `json:"path" gorm:"column:path"`
+	StatusCode int       `json:"statusCode" gorm:"column:status_code"`
+	IP         string    `json:"ip" gorm:"column:ip"`
+	CreatedAt  time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (APIKeyLog) TableName() string {
+	return "sys_api_key_log"
+}
+
+// APIKeyHandler API密钥管理处理器
+type APIKeyHandler struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyHandler 创建API密钥管理处理器
+func NewAPIKeyHandler(db *gorm.DB) *APIKeyHandler {
+	return &APIKeyHandler{db: db}
+}
+
+// GetAPIKeyList 获取API密钥列表
+func (h *APIKeyHandler) GetAPIKeyList(c *gin.Context) {
+	var keys []APIKey
+	h.db.Order("id DESC").Find(&keys)
+
+	userIDs := make([]int64, 0, len(keys))
+	keyIDs := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		userIDs = append(userIDs, k.UserID)
+		keyIDs = append(keyIDs, k.ID)
+	}
+
+	userMap := make(map[int64]string)
+	if len(userIDs) > 0 {
+		var users []model.User
+		h.db.Select("id", "username").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			userMap[u.ID] = u.Username
+		}
+	}
+
+	permMap := make(map[int64][]string)
+	if len(keyIDs) > 0 {
+		var perms []APIKeyPermission
+		h.db.Where("api_key_id IN ?", keyIDs).Find(&perms)
+		for _, p := range perms {
+			permMap[p.APIKeyID] = append(permMap[p.APIKeyID], p.PermissionCode)
+		}
+	}
+
+	for i := range keys {
+		keys[i].Username = userMap[keys[i].UserID]
+		keys[i].Permissions = permMap[keys[i].ID]
+		if keys[i].Permissions == nil {
+			keys[i].Permissions = []string{}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": keys})
+}
+
+// apiKeyRequest API密钥新增/修改请求
+type apiKeyRequest struct {
+	Name        string     `json:"name"`
+	UserID      int64      `json:"userId"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expireTime"`
+	Status      *int       `json:"status"`
+}
+
+// CreateAPIKey 创建API密钥，明文密钥仅在创建时返回一次
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if msg := h.validateAPIKey(req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	raw, err := utils.RandomToken(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成密钥失败"})
+		return
+	}
+	plain := apiKeyPrefix + raw
+
+	creatorID, _ := c.Get("userID")
+	key := APIKey{
+		Name:      req.Name,
+		KeyPrefix: plain[:len(apiKeyPrefix)+6],
+		KeyHash:   utils.HashToken(plain),
+		UserID:    req.UserID,
+		Status:    1,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: creatorID.(int64),
+	}
+
+	tx := h.db.Begin()
+	if err := tx.Create(&key).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+		return
+	}
+	if err := replaceAPIKeyPermissions(tx, key.ID, req.Permissions); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存权限失败"})
+		return
+	}
+	tx.Commit()
+
+	key.Permissions = req.Permissions
+	recordAudit(c, h.db, auditCreate, "api_key", key.ID, nil, key)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功，请妥善保存密钥，关闭后将无法再次查看",
+		"data": gin.H{
+			"apiKey": key,
+			"key":    plain,
+		},
+	})
+}
+
+// UpdateAPIKey 更新API密钥的名称、权限范围、有效期和状态
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	var key APIKey
+	if err := h.db.First(&key, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "API密钥不存在"})
+		return
+	}
+
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+	// 绑定用户不可修改
+	req.UserID = key.UserID
+
+	if msg := h.validateAPIKey(req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":       req.Name,
+		"expires_at": req.ExpiresAt,
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	before := h.snapshot(key.ID)
+	tx := h.db.Begin()
+	if err := tx.Model(&key).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新失败"})
+		return
+	}
+	if err := replaceAPIKeyPermissions(tx, key.ID, req.Permissions); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存权限失败"})
+		return
+	}
+	tx.Commit()
+	recordAudit(c, h.db, auditUpdate, "api_key", key.ID, before, h.snapshot(key.ID))
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
+
+// DeleteAPIKey 删除API密钥，调用日志保留
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	var key APIKey
+	if err := h.db.First(&key, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "API密钥不存在"})
+		return
+	}
+
+	before := h.snapshot(key.ID)
+	tx := h.db.Begin()
+	tx.Where("api_key_id = ?", key.ID).Delete(&APIKeyPermission{})
+	tx.Delete(&key)
+	tx.Commit()
+	recordAudit(c, h.db, auditDelete, "api_key", key.ID, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// GetAPIKeyLogs 获取API密钥调用日志
+func (h *APIKeyHandler) GetAPIKeyLogs(c *gin.Context) {
+	id := c.Param("id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	query := h.db.Model(&APIKeyLog{}).Where("api_key_id = ?", id)
+
+	var total int64
+	query.Count(&total)
+
+	var logs []APIKeyLog
+	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&logs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"items": logs,
+			"total": total,
+		},
+	})
+}
+
+// snapshot API密钥审计快照，包含权限范围
+func (h *APIKeyHandler) snapshot(id int64) *APIKey {
+	var key APIKey
+	if err := h.db.First(&key, id).Error; err != nil {
+		return nil
+	}
+	key.Permissions = make([]string, 0)
+	h.db.Model(&APIKeyPermission{}).Where("api_key_id = ?", key.ID).Pluck("permission_code", &key.Permissions)
+	return &key
+}
+
+// validateAPIKey 校验API密钥参数，权限范围不能超出绑定用户的角色权限
+func (h *APIKeyHandler) validateAPIKey(req apiKeyRequest) string {
+	if req.Name == "" {
+		return "名称不能为空"
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return "过期时间不能早于当前时间"
+	}
+
+	var user model.User
+	if err := h.db.First(&user, req.UserID).Error; err != nil || user.Status != 1 {
+		return "绑定用户不存在或已禁用"
+	}
+
+	granted := loadPermissions(h.db, user.RoleCode)
+	for _, code := range req.Permissions {
+		if !containsString(granted, code) {
+			return "绑定用户不具备权限: " + code
+		}
+	}
+	return ""
+}
+
+// replaceAPIKeyPermissions 覆盖保存API密钥的权限范围
+func replaceAPIKeyPermissions(tx *gorm.DB, apiKeyID int64, codes []string) error {
+	if err := tx.Where("api_key_id = ?", apiKeyID).Delete(&APIKeyPermission{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		if err := tx.Create(&APIKeyPermission{APIKeyID: apiKeyID, PermissionCode: code}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
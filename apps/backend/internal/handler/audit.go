@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 审计操作类型
+const (
+	auditCreate  = "CREATE"
+	auditUpdate  = "UPDATE"
+	auditDelete  = "DELETE"
+	auditRestore = "RESTORE"
+)
+
+// auditIgnoredFields 不参与差异比较的字段
+var auditIgnoredFields = map[string]bool{
+	"updateTime": true,
+	"updated_at": true,
+}
+
+// AuditLog 数据变更审计日志模型
+type AuditLog struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID    *int64    `json:"userId" gorm:"column:user_id"`
+	Username  string    `json:"username" gorm:"column:username"`
+	APIKeyID  *int64    `json:"apiKeyId" gorm:"column:api_key_id"`
+	Action    string    `json:"action" gorm:"column:action"`
+	Entity    string    `json:"entity" gorm:"column:entity"`
+	EntityID  string    `json:"entityId" gorm:"column:entity_id"`
+	Before    string    `json:"before" gorm:"column:before_data"`
+	After     string    `json:"after" gorm:"column:after_data"`
+	Diff      string    `json:"diff" gorm:"column:diff"`
+	IP        string    `json:"ip" gorm:"column:ip"`
+	RequestID string    `json:"requestId" gorm:"column:request_id"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AuditLog) TableName() string {
+	return "sys_audit_log"
+}
+
+// AuditHandler 审计日志处理器
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+// NewAuditHandler 创建审计日志处理器
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+// GetAuditLogs 查询审计日志，可按实体、实体ID、操作人、操作类型和时间过滤
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	entity := c.Query("entity")
+	entityID := c.Query("entityId")
+	userID := c.Query("userId")
+	username := c.Query("username")
+	action := c.Query("action")
+	requestID := c.Query("requestId")
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	query := h.db.Model(&AuditLog{})
+
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if startDate != "" {
+		query = query.Where("created_at >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("created_at <= ?", endDate+" 23:59:59")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var logs []AuditLog
+	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&logs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"items": logs,
+			"total": total,
+		},
+	})
+}
+
+// GetAuditLog 获取审计日志详情
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	id := c.Param("id")
+	var entry AuditLog
+	if err := h.db.First(&entry, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "审计记录不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": entry})
+}
+
+// recordAudit 记录一次数据变更。before/after 为变更前后的快照（新增时 before 为 nil，删除时 after 为 nil），
+// 审计失败只记日志，不影响业务操作
+func recordAudit(c *gin.Context, db *gorm.DB, action, entity string, entityID int64, before, after interface{}) {
+	beforeMap := auditSnapshot(before)
+	afterMap := auditSnapshot(after)
+
+	entry := AuditLog{
+		Action:    action,
+		Entity:    entity,
+		EntityID:  strconv.FormatInt(entityID, 10),
+		Before:    auditJSON(beforeMap),
+		After:     auditJSON(afterMap),
+		Diff:      auditJSON(auditDiff(beforeMap, afterMap)),
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("requestID"),
+		Username:  c.GetString("username"),
+	}
+	if userID, ok := c.Get("userID"); ok {
+		id := userID.(int64)
+		entry.UserID = &id
+	}
+	if apiKeyID, ok := c.Get("apiKeyID"); ok {
+		id := apiKeyID.(int64)
+		entry.APIKeyID = &id
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("audit: %s %s %d: %v", action, entity, entityID, err)
+	}
+}
+
+// auditDocument 读取单据快照：主表字段加 items 明细列表，单据不存在时返回 nil
+func auditDocument(db *gorm.DB, header interface{}, id int64, items interface{}, foreignKey string) map[string]interface{} {
+	if err := db.First(header, id).Error; err != nil {
+		return nil
+	}
+	db.Where(foreignKey+" = ?", id).Find(items)
+
+	fields := auditSnapshot(header)
+	fields["items"] = items
+	return fields
+}
+
+// auditSnapshot 将快照转为字段映射；非对象类型（如明细列表）放在 value 字段下
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var value interface{}
+		_ = json.Unmarshal(data, &value)
+		return map[string]interface{}{"value": value}
+	}
+	return fields
+}
+
+// auditDiff 计算变更字段，格式为 {字段: {"before": 旧值, "after": 新值}}
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	diff := make(map[string]interface{})
+	for k, b := range before {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		if a, ok := after[k]; !ok || !reflect.DeepEqual(a, b) {
+			diff[k] = gin.H{"before": b, "after": after[k]}
+		}
+	}
+	for k, a := range after {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		if _, ok := before[k]; !ok {
+			diff[k] = gin.H{"before": nil, "after": a}
+		}
+	}
+	return diff
+}
+
+// auditJSON 序列化审计数据，空值存为空串
+func auditJSON(v map[string]interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"easywms/internal/auth"
 	"easywms/internal/config"
 	"easywms/internal/model"
 	"easywms/internal/utils"
@@ -13,13 +15,26 @@ import (
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db    *gorm.DB
+	cfg   *config.Config
+	guard *loginGuard
+	sso   *auth.OIDCClient // 未启用单点登录时为nil
+
+	authenticators auth.Chain
 }
 
 // NewAuthHandler 创建认证处理器
 func NewAuthHandler(db *gorm.DB, cfg *config.Config) *AuthHandler {
-	return &AuthHandler{db: db, cfg: cfg}
+	h := &AuthHandler{
+		db:             db,
+		cfg:            cfg,
+		guard:          newLoginGuard(db, cfg.Login),
+		authenticators: auth.NewChain(db, cfg.Auth),
+	}
+	if cfg.OIDC.Enabled {
+		h.sso = auth.NewOIDCClient(cfg.OIDC)
+	}
+	return h
 }
 
 // LoginRequest 登录请求
@@ -30,7 +45,12 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken      string `json:"accessToken,omitempty"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	MFARequired      bool   `json:"mfaRequired,omitempty"`      // 需要输入两步验证码
+	MFAToken         string `json:"mfaToken,omitempty"`         // 两步登录临时令牌
+	MFASetupRequired bool   `json:"mfaSetupRequired,omitempty"` // 角色要求启用两步验证但尚未绑定
+	PasswordExpired  bool   `json:"passwordExpired,omitempty"`  // 密码已过期，须先修改密码
 }
 
 // Login 用户登录
@@ -45,13 +65,59 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 查询用户
-	var user model.User
-	if err := h.db.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "用户名或密码错误",
+	ip := c.ClientIP()
+
+	// 账号或IP处于锁定期
+	if until := h.guard.lockedUntil(req.Username, ip); until != nil {
+		h.guard.audit(c, nil, req.Username, false, "账号或IP已锁定")
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    429,
+			"message": lockedMessage(*until),
+		})
+		return
+	}
+
+	// 依次尝试配置的认证方式（本地密码、LDAP）
+	identity, err := h.authenticators.Authenticate(req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.guard.recordFailure(req.Username, ip)
+			h.guard.audit(c, nil, req.Username, false, "账号不存在")
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			h.guard.recordFailure(req.Username, ip)
+			h.guard.audit(c, h.userIDByName(req.Username), req.Username, false, "密码错误")
+		default:
+			h.guard.audit(c, nil, req.Username, false, "认证服务不可用")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"code":    503,
+				"message": "认证服务暂不可用，请稍后再试",
+			})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "用户名或密码错误",
+		})
+		return
+	}
+
+	// 查询用户，目录账号同步姓名、角色和部门
+	user, err := h.syncDirectoryUser(identity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.guard.audit(c, nil, req.Username, false, "账号未开通")
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "账号未开通，请联系管理员",
+			})
+			return
+		}
+		if errors.Is(err, errDirectoryNotLinked) {
+			h.guard.audit(c, nil, req.Username, false, "同名本地账号未关联LDAP身份")
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "本地已存在同名账号，请联系管理员关联LDAP账号",
 			})
 			return
 		}
@@ -64,6 +130,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// 检查用户状态
 	if user.Status != 1 {
+		h.guard.audit(c, &user.ID, req.Username, false, "账号已被禁用")
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    403,
 			"message": "账号已被禁用",
@@ -71,17 +138,29 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 验证密码
-	if !utils.CheckPassword(req.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "用户名或密码错误",
+	// 已启用两步验证，返回临时令牌等待输入验证码
+	_, mfaOn := mfaEnabled(h.db, user.ID)
+	if mfaOn {
+		mfaToken, _, err := utils.GenerateAccessToken(user.ID, user.Username, user.RoleCode, mfaSigningKey(h.cfg), mfaTokenTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "生成令牌失败",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"data": LoginResponse{MFARequired: true, MFAToken: mfaToken},
 		})
 		return
 	}
 
-	// 生成JWT
-	token, err := utils.GenerateToken(user.ID, user.Username, user.RoleCode, h.cfg.JWT.Secret, h.cfg.JWT.ExpireHours)
+	h.guard.recordSuccess(req.Username)
+	h.guard.audit(c, &user.ID, req.Username, true, "登录成功")
+
+	// 生成JWT和刷新令牌
+	tokens, err := issueTokens(c, h.db, h.cfg, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -89,17 +168,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		})
 		return
 	}
+	tokens.MFASetupRequired = mfaRequired(h.cfg, user.RoleCode)
+	tokens.PasswordExpired = passwordExpired(h.cfg.Password, user)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": LoginResponse{
-			AccessToken: token,
-		},
+		"data": tokens,
 	})
 }
 
-// Logout 用户登出
+// Logout 用户登出，吊销当前访问令牌和刷新令牌
 func (h *AuthHandler) Logout(c *gin.Context) {
+	revokeSession(c, h.db, h.cfg)
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
 		"message": "登出成功",
@@ -156,7 +236,7 @@ func (h *AuthHandler) GetUserInfo(c *gin.Context) {
 
 // GetAccessCodes 获取用户权限码
 func (h *AuthHandler) GetAccessCodes(c *gin.Context) {
-	roleCode, exists := c.Get("roleCode")
+	roleCode, exists := currentRoleCode(c, h.db)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
@@ -165,16 +245,7 @@ func (h *AuthHandler) GetAccessCodes(c *gin.Context) {
 		return
 	}
 
-	// 从数据库查询角色的权限码
-	var permissions []string
-	h.db.Table("sys_role_permission").
-		Where("role_code = ?", roleCode).
-		Pluck("permission_code", &permissions)
-
-	// 如果数据库没有数据，使用默认权限
-	if len(permissions) == 0 {
-		permissions = h.getDefaultPermissions(roleCode.(string))
-	}
+	permissions := loadPermissions(h.db, roleCode)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
@@ -182,18 +253,86 @@ func (h *AuthHandler) GetAccessCodes(c *gin.Context) {
 	})
 }
 
+// loadPermissions 查询角色的权限码，每次请求实时读取，角色授权变更即时生效
+func loadPermissions(db *gorm.DB, roleCode string) []string {
+	var role Role
+	if err := db.Where("code = ?", roleCode).First(&role).Error; err != nil {
+		// 角色表未初始化时使用默认权限
+		return getDefaultPermissions(roleCode)
+	}
+
+	// 停用的角色没有任何权限
+	permissions := make([]string, 0)
+	if role.Status != 1 {
+		return permissions
+	}
+
+	db.Table("sys_role_permission").
+		Where("role_code = ?", roleCode).
+		Pluck("permission_code", &permissions)
+	return permissions
+}
+
+// currentRoleCode 获取当前登录用户的角色，以数据库为准，用户改角色后无需重新登录
+func currentRoleCode(c *gin.Context, db *gorm.DB) (string, bool) {
+	roleCode, exists := c.Get("roleCode")
+	if !exists {
+		return "", false
+	}
+
+	if userID, ok := c.Get("userID"); ok {
+		var user model.User
+		if err := db.Select("role_code").First(&user, userID).Error; err == nil {
+			return user.RoleCode, true
+		}
+	}
+	return roleCode.(string), true
+}
+
+// hasPermission 判断当前登录用户是否拥有指定权限码，API密钥调用时还须在密钥权限范围内
+func hasPermission(c *gin.Context, db *gorm.DB, code string) bool {
+	roleCode, exists := currentRoleCode(c, db)
+	if !exists {
+		return false
+	}
+	if scopes, ok := c.Get("apiKeyPermissions"); ok && !containsString(scopes.([]string), code) {
+		return false
+	}
+	for _, p := range loadPermissions(db, roleCode) {
+		if p == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission 权限校验中间件，当前用户缺少权限码时返回403
+func RequirePermission(db *gorm.DB, code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasPermission(c, db, code) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "无权限访问",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // getDefaultPermissions 获取默认权限（数据库未初始化时使用）
-func (h *AuthHandler) getDefaultPermissions(roleCode string) []string {
+func getDefaultPermissions(roleCode string) []string {
 	switch roleCode {
 	case "ADMIN":
 		return []string{
 			"BASIC_VIEW", "BASIC_MANAGE",
 			"PRODUCT_VIEW", "PRODUCT_CREATE", "PRODUCT_EDIT", "PRODUCT_DELETE",
-			"SUPPLIER_MANAGE", "DEPARTMENT_MANAGE", "USER_MANAGE", "INIT_STOCK",
+			"SUPPLIER_MANAGE", "DEPARTMENT_MANAGE", "USER_MANAGE", "ROLE_MANAGE", "MENU_MANAGE", "API_KEY_MANAGE", "AUDIT_VIEW", "INIT_STOCK",
 			"PROCUREMENT_VIEW", "PROCUREMENT_CREATE", "PROCUREMENT_APPROVE", "PROCUREMENT_ORDER",
 			"INBOUND_VIEW", "INBOUND_CREATE", "INBOUND_APPROVE",
 			"OUTBOUND_VIEW", "OUTBOUND_CREATE", "OUTBOUND_APPROVE", "OUTBOUND_EXECUTE",
-			"INVENTORY_VIEW", "INVENTORY_CHECK", "INVENTORY_ADJUST",
+			"INVENTORY_VIEW", "INVENTORY_CHECK", "INVENTORY_ADJUST", "INVENTORY_SUPERVISE",
 			"REPORT_VIEW", "DASHBOARD_VIEW",
 		}
 	case "BUYER":
@@ -207,7 +346,7 @@ func (h *AuthHandler) getDefaultPermissions(roleCode string) []string {
 			"BASIC_VIEW", "PRODUCT_VIEW", "PRODUCT_CREATE", "PRODUCT_EDIT", "INIT_STOCK",
 			"INBOUND_VIEW", "INBOUND_CREATE", "INBOUND_APPROVE",
 			"OUTBOUND_VIEW", "OUTBOUND_APPROVE", "OUTBOUND_EXECUTE",
-			"INVENTORY_VIEW", "INVENTORY_CHECK", "INVENTORY_ADJUST",
+			"INVENTORY_VIEW", "INVENTORY_CHECK", "INVENTORY_ADJUST", "INVENTORY_SUPERVISE",
 			"DASHBOARD_VIEW",
 		}
 	case "STAFF":
@@ -220,449 +359,3 @@ func (h *AuthHandler) getDefaultPermissions(roleCode string) []string {
 		return []string{}
 	}
 }
-
-// MenuItem 菜单项
-type MenuItem struct {
-	Name      string     `json:"name"`
-	Path      string     `json:"path"`
-	Component string     `json:"component,omitempty"`
-	Meta      MenuMeta   `json:"meta"`
-	Children  []MenuItem `json:"children,omitempty"`
-}
-
-// MenuMeta 菜单元数据
-type MenuMeta struct {
-	Title     string   `json:"title"`
-	Icon      string   `json:"icon,omitempty"`
-	Order     int      `json:"order,omitempty"`
-	Authority []string `json:"authority,omitempty"`
-	AffixTab  bool     `json:"affixTab,omitempty"`
-}
-
-// GetMenus 获取用户菜单
-func (h *AuthHandler) GetMenus(c *gin.Context) {
-	roleCode, exists := c.Get("roleCode")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "未授权",
-		})
-		return
-	}
-
-	menus := h.buildMenus(roleCode.(string))
-
-	c.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"data": menus,
-	})
-}
-
-// buildMenus 根据角色构建菜单
-func (h *AuthHandler) buildMenus(roleCode string) []MenuItem {
-	var menus []MenuItem
-
-	// 概览菜单 - 所有角色都可见
-	dashboardMenu := MenuItem{
-		Name: "Dashboard",
-		Path: "/dashboard",
-		Meta: MenuMeta{
-			Title: "概览",
-			Icon:  "lucide:layout-dashboard",
-			Order: -1,
-		},
-		Children: []MenuItem{
-			{
-				Name:      "Analytics",
-				Path:      "/analytics",
-				Component: "#/views/dashboard/analytics/index.vue",
-				Meta: MenuMeta{
-					Title:    "分析页",
-					Icon:     "lucide:area-chart",
-					AffixTab: true,
-				},
-			},
-			{
-				Name:      "Workspace",
-				Path:      "/workspace",
-				Component: "#/views/dashboard/workspace/index.vue",
-				Meta: MenuMeta{
-					Title: "工作台",
-					Icon:  "carbon:workspace",
-				},
-			},
-		},
-	}
-	menus = append(menus, dashboardMenu)
-
-	// 根据角色添加不同的菜单
-	switch roleCode {
-	case "ADMIN":
-		menus = append(menus, h.getAdminMenus()...)
-	case "BUYER":
-		menus = append(menus, h.getBuyerMenus()...)
-	case "W_MGR":
-		menus = append(menus, h.getWarehouseManagerMenus()...)
-	case "STAFF":
-		menus = append(menus, h.getStaffMenus()...)
-	}
-
-	return menus
-}
-
-// getAdminMenus 系统管理员菜单
-func (h *AuthHandler) getAdminMenus() []MenuItem {
-	return []MenuItem{
-		// 基础数据管理
-		{
-			Name: "WmsBasicData",
-			Path: "/wms/basic",
-			Meta: MenuMeta{
-				Title: "基础数据",
-				Icon:  "mdi:package-variant-closed",
-				Order: 10,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProduct",
-					Path:      "/wms/basic/product",
-					Component: "#/views/wms/product/list.vue",
-					Meta: MenuMeta{
-						Title: "产品管理",
-						Icon:  "mdi:package-variant",
-					},
-				},
-			},
-		},
-		// 采购管理
-		{
-			Name: "WmsProcurement",
-			Path: "/wms/procurement",
-			Meta: MenuMeta{
-				Title: "采购管理",
-				Icon:  "mdi:cart-outline",
-				Order: 20,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProcurementList",
-					Path:      "/wms/procurement/list",
-					Component: "#/views/wms/procurement/list.vue",
-					Meta: MenuMeta{
-						Title: "采购单列表",
-						Icon:  "mdi:clipboard-list-outline",
-					},
-				},
-			},
-		},
-		// 入库管理
-		{
-			Name: "WmsInbound",
-			Path: "/wms/inbound",
-			Meta: MenuMeta{
-				Title: "入库管理",
-				Icon:  "mdi:package-down",
-				Order: 30,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInboundList",
-					Path:      "/wms/inbound/list",
-					Component: "#/views/wms/inbound/list.vue",
-					Meta: MenuMeta{
-						Title: "入库单列表",
-						Icon:  "mdi:clipboard-arrow-down-outline",
-					},
-				},
-			},
-		},
-		// 出库管理
-		{
-			Name: "WmsOutbound",
-			Path: "/wms/outbound",
-			Meta: MenuMeta{
-				Title: "出库管理",
-				Icon:  "mdi:package-up",
-				Order: 40,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsOutboundList",
-					Path:      "/wms/outbound/list",
-					Component: "#/views/wms/outbound/list.vue",
-					Meta: MenuMeta{
-						Title: "出库单列表",
-						Icon:  "mdi:clipboard-arrow-up-outline",
-					},
-				},
-			},
-		},
-		// 库存管理
-		{
-			Name: "WmsInventory",
-			Path: "/wms/inventory",
-			Meta: MenuMeta{
-				Title: "库存管理",
-				Icon:  "mdi:warehouse",
-				Order: 50,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInventoryStock",
-					Path:      "/wms/inventory/stock",
-					Component: "#/views/wms/inventory/stock/list.vue",
-					Meta: MenuMeta{
-						Title: "库存查询",
-						Icon:  "mdi:cube-outline",
-					},
-				},
-				{
-					Name:      "WmsInventoryCheck",
-					Path:      "/wms/inventory/check",
-					Component: "#/views/wms/inventory/check/list.vue",
-					Meta: MenuMeta{
-						Title: "库存盘点",
-						Icon:  "mdi:clipboard-check-outline",
-					},
-				},
-			},
-		},
-	}
-}
-
-// getBuyerMenus 采购专员菜单
-func (h *AuthHandler) getBuyerMenus() []MenuItem {
-	return []MenuItem{
-		// 基础数据（只读）
-		{
-			Name: "WmsBasicData",
-			Path: "/wms/basic",
-			Meta: MenuMeta{
-				Title: "基础数据",
-				Icon:  "mdi:package-variant-closed",
-				Order: 10,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProduct",
-					Path:      "/wms/basic/product",
-					Component: "#/views/wms/product/list.vue",
-					Meta: MenuMeta{
-						Title: "产品查看",
-						Icon:  "mdi:package-variant",
-					},
-				},
-			},
-		},
-		// 采购管理
-		{
-			Name: "WmsProcurement",
-			Path: "/wms/procurement",
-			Meta: MenuMeta{
-				Title: "采购管理",
-				Icon:  "mdi:cart-outline",
-				Order: 20,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProcurementList",
-					Path:      "/wms/procurement/list",
-					Component: "#/views/wms/procurement/list.vue",
-					Meta: MenuMeta{
-						Title: "采购单列表",
-						Icon:  "mdi:clipboard-list-outline",
-					},
-				},
-			},
-		},
-		// 库存查询
-		{
-			Name: "WmsInventory",
-			Path: "/wms/inventory",
-			Meta: MenuMeta{
-				Title: "库存管理",
-				Icon:  "mdi:warehouse",
-				Order: 50,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInventoryStock",
-					Path:      "/wms/inventory/stock",
-					Component: "#/views/wms/inventory/stock/list.vue",
-					Meta: MenuMeta{
-						Title: "库存查询",
-						Icon:  "mdi:cube-outline",
-					},
-				},
-			},
-		},
-	}
-}
-
-// getWarehouseManagerMenus 仓库管理员菜单
-func (h *AuthHandler) getWarehouseManagerMenus() []MenuItem {
-	return []MenuItem{
-		// 基础数据管理
-		{
-			Name: "WmsBasicData",
-			Path: "/wms/basic",
-			Meta: MenuMeta{
-				Title: "基础数据",
-				Icon:  "mdi:package-variant-closed",
-				Order: 10,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProduct",
-					Path:      "/wms/basic/product",
-					Component: "#/views/wms/product/list.vue",
-					Meta: MenuMeta{
-						Title: "产品管理",
-						Icon:  "mdi:package-variant",
-					},
-				},
-			},
-		},
-		// 入库管理
-		{
-			Name: "WmsInbound",
-			Path: "/wms/inbound",
-			Meta: MenuMeta{
-				Title: "入库管理",
-				Icon:  "mdi:package-down",
-				Order: 30,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInboundList",
-					Path:      "/wms/inbound/list",
-					Component: "#/views/wms/inbound/list.vue",
-					Meta: MenuMeta{
-						Title: "入库单列表",
-						Icon:  "mdi:clipboard-arrow-down-outline",
-					},
-				},
-			},
-		},
-		// 出库管理
-		{
-			Name: "WmsOutbound",
-			Path: "/wms/outbound",
-			Meta: MenuMeta{
-				Title: "出库管理",
-				Icon:  "mdi:package-up",
-				Order: 40,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsOutboundList",
-					Path:      "/wms/outbound/list",
-					Component: "#/views/wms/outbound/list.vue",
-					Meta: MenuMeta{
-						Title: "出库单列表",
-						Icon:  "mdi:clipboard-arrow-up-outline",
-					},
-				},
-			},
-		},
-		// 库存管理
-		{
-			Name: "WmsInventory",
-			Path: "/wms/inventory",
-			Meta: MenuMeta{
-				Title: "库存管理",
-				Icon:  "mdi:warehouse",
-				Order: 50,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInventoryStock",
-					Path:      "/wms/inventory/stock",
-					Component: "#/views/wms/inventory/stock/list.vue",
-					Meta: MenuMeta{
-						Title: "库存查询",
-						Icon:  "mdi:cube-outline",
-					},
-				},
-				{
-					Name:      "WmsInventoryCheck",
-					Path:      "/wms/inventory/check",
-					Component: "#/views/wms/inventory/check/list.vue",
-					Meta: MenuMeta{
-						Title: "库存盘点",
-						Icon:  "mdi:clipboard-check-outline",
-					},
-				},
-			},
-		},
-	}
-}
-
-// getStaffMenus 部门员工菜单
-func (h *AuthHandler) getStaffMenus() []MenuItem {
-	return []MenuItem{
-		// 产品查看
-		{
-			Name: "WmsBasicData",
-			Path: "/wms/basic",
-			Meta: MenuMeta{
-				Title: "基础数据",
-				Icon:  "mdi:package-variant-closed",
-				Order: 10,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsProduct",
-					Path:      "/wms/basic/product",
-					Component: "#/views/wms/product/list.vue",
-					Meta: MenuMeta{
-						Title: "产品查看",
-						Icon:  "mdi:package-variant",
-					},
-				},
-			},
-		},
-		// 出库管理（领用申请）
-		{
-			Name: "WmsOutbound",
-			Path: "/wms/outbound",
-			Meta: MenuMeta{
-				Title: "物资领用",
-				Icon:  "mdi:package-up",
-				Order: 40,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsOutboundList",
-					Path:      "/wms/outbound/list",
-					Component: "#/views/wms/outbound/list.vue",
-					Meta: MenuMeta{
-						Title: "领用申请",
-						Icon:  "mdi:clipboard-arrow-up-outline",
-					},
-				},
-			},
-		},
-		// 库存查询
-		{
-			Name: "WmsInventory",
-			Path: "/wms/inventory",
-			Meta: MenuMeta{
-				Title: "库存查询",
-				Icon:  "mdi:warehouse",
-				Order: 50,
-			},
-			Children: []MenuItem{
-				{
-					Name:      "WmsInventoryStock",
-					Path:      "/wms/inventory/stock",
-					Component: "#/views/wms/inventory/stock/list.vue",
-					Meta: MenuMeta{
-						Title: "库存查询",
-						Icon:  "mdi:cube-outline",
-					},
-				},
-			},
-		},
-	}
-}
@@ -32,7 +32,7 @@ func (h *CategoryHandler) GetCategoryList(c *gin.Context) {
 	}
 
 	offset := (page - 1) * pageSize
-	query := h.db.Model(&Category{})
+	query := h.db.Model(&Category{}).Scopes(trashScope(c))
 
 	if keyword != "" {
 		query = query.Where("name LIKE ?", "%"+keyword+"%")
@@ -122,6 +122,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
 		return
 	}
+	recordAudit(c, h.db, auditCreate, "category", category.ID, nil, category)
 
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": category})
 }
@@ -144,10 +145,13 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	before := category
 	h.db.Model(&category).Updates(map[string]interface{}{
 		"name":      req.Name,
 		"parent_id": req.ParentID,
 	})
+	h.db.First(&category, category.ID)
+	recordAudit(c, h.db, auditUpdate, "category", category.ID, before, category)
 
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
 }
@@ -155,6 +159,11 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 // DeleteCategory 删除分类
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	id := c.Param("id")
+	var category Category
+	if err := h.db.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "分类不存在"})
+		return
+	}
 
 	// 检查是否有子分类
 	var count int64
@@ -171,9 +180,35 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Delete(&Category{}, id).Error; err != nil {
+	if err := h.db.Delete(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败"})
 		return
 	}
+	recordAudit(c, h.db, auditDelete, "category", category.ID, category, nil)
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
 }
+
+// RestoreCategory 恢复已删除的分类，上级分类须先恢复
+func (h *CategoryHandler) RestoreCategory(c *gin.Context) {
+	var category Category
+	if !findDeleted(c, h.db, &category, c.Param("id"), "已删除的分类不存在") {
+		return
+	}
+
+	if category.ParentID != 0 {
+		var count int64
+		h.db.Model(&Category{}).Where("id = ?", category.ParentID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "上级分类已删除，请先恢复上级分类"})
+			return
+		}
+	}
+
+	if err := restoreRecord(h.db, &category); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "恢复失败"})
+		return
+	}
+	h.db.First(&category, category.ID)
+	recordAudit(c, h.db, auditRestore, "category", category.ID, nil, category)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+	"easywms/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// abcClasses ABC分类，按重要程度排列
+var abcClasses = []string{"A", "B", "C"}
+
+// CycleCountHandler 循环盘点处理器
+type CycleCountHandler struct {
+	db    *gorm.DB
+	cfg   config.CycleCountConfig
+	check *service.InventoryCheckService
+}
+
+// NewCycleCountHandler 创建循环盘点处理器
+func NewCycleCountHandler(db *gorm.DB, cfg *config.Config) *CycleCountHandler {
+	cc := cfg.CycleCount
+	// 未配置时使用默认值
+	if cc.IntervalHours <= 0 {
+		cc.IntervalHours = 24
+	}
+	if cc.LookbackDays <= 0 {
+		cc.LookbackDays = 365
+	}
+	if cc.ClassAPercent <= 0 {
+		cc.ClassAPercent = 80
+	}
+	if cc.ClassBPercent <= 0 {
+		cc.ClassBPercent = 95
+	}
+	if cc.ClassAMonths <= 0 {
+		cc.ClassAMonths = 1
+	}
+	if cc.ClassBMonths <= 0 {
+		cc.ClassBMonths = 3
+	}
+	if cc.ClassCMonths <= 0 {
+		cc.ClassCMonths = 12
+	}
+	store := repository.NewStore(db, sequence.New(cfg.Numbering))
+	return &CycleCountHandler{db: db, cfg: cc, check: service.NewInventoryCheckService(store)}
+}
+
+// abcValue 产品消耗金额
+type abcValue struct {
+	ProductID int64
+	Qty       float64
+	Value     float64
+}
+
+// ClassifyABC 按出库消耗金额重新计算ABC分类
+func (h *CycleCountHandler) ClassifyABC(c *gin.Context) {
+	summary, err := h.RunClassification(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "ABC分类失败: " + err.Error()})
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "abc_class", 0, nil, summary)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "分类完成", "data": summary})
+}
+
+// PlanCycleCount 立即执行一次循环盘点计划
+func (h *CycleCountHandler) PlanCycleCount(c *gin.Context) {
+	checks, err := h.RunPlanner(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成盘点计划失败: " + err.Error()})
+		return
+	}
+	for _, check := range checks {
+		id := check["id"].(int64)
+		recordAudit(c, h.db, auditCreate, "inventory_check", id,
+			nil, auditDocument(h.db, &InventoryCheck{}, id, &[]InventoryCheckItem{}, "check_id"))
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "计划完成", "data": checks})
+}
+
+// GetCycleCountStatus 获取各分类当前周期的盘点进度
+func (h *CycleCountHandler) GetCycleCountStatus(c *gin.Context) {
+	now := time.Now()
+	result := make([]gin.H, 0, len(abcClasses))
+
+	for _, class := range abcClasses {
+		start, end := cycleWindow(now, h.cadenceMonths(class))
+
+		var total int64
+		h.db.Model(&Product{}).Where("status = ? AND abc_class = ?", 1, class).Count(&total)
+
+		remaining := h.uncountedProducts(class, start)
+
+		result = append(result, gin.H{
+			"abcClass":    class,
+			"cycleMonths": h.cadenceMonths(class),
+			"cycleStart":  start.Format("2006-01-02"),
+			"cycleEnd":    end.AddDate(0, 0, -1).Format("2006-01-02"),
+			"total":       total,
+			"counted":     total - int64(len(remaining)),
+			"remaining":   len(remaining),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": result})
+}
+
+// RunClassification 统计回溯期内的出库消耗金额，按累计占比划分ABC类
+func (h *CycleCountHandler) RunClassification(now time.Time) (map[string]int, error) {
+	since := now.AddDate(0, 0, -h.cfg.LookbackDays)
+
+	// 出库消耗数量
+	var usages []struct {
+		ProductID int64   `gorm:"column:product_id"`
+		Qty       float64 `gorm:"column:qty"`
+	}
+	if err := h.db.Table("biz_stock_log").
+		Select("product_id, SUM(-change_qty) as qty").
+		Where("type = ? AND created_at >= ?", "OUT", since).
+		Group("product_id").
+		Find(&usages).Error; err != nil {
+		return nil, err
+	}
+
+	// 采购均价
+	var prices []struct {
+		ProductID int64   `gorm:"column:product_id"`
+		Price     float64 `gorm:"column:price"`
+	}
+	if err := h.db.Table("biz_procurement_item").
+		Select("product_id, AVG(unit_price) as price").
+		Where("unit_price > 0").
+		Group("product_id").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	priceMap := make(map[int64]float64)
+	for _, p := range prices {
+		priceMap[p.ProductID] = p.Price
+	}
+
+	var productIDs []int64
+	h.db.Model(&Product{}).Pluck("id", &productIDs)
+
+	usageMap := make(map[int64]float64)
+	for _, u := range usages {
+		usageMap[u.ProductID] = u.Qty
+	}
+
+	values := make([]abcValue, 0, len(productIDs))
+	for _, id := range productIDs {
+		qty := usageMap[id]
+		values = append(values, abcValue{ProductID: id, Qty: qty, Value: qty * priceMap[id]})
+	}
+
+	classMap := classifyABC(values, h.cfg.ClassAPercent, h.cfg.ClassBPercent)
+
+	grouped := make(map[string][]int64)
+	for id, class := range classMap {
+		grouped[class] = append(grouped[class], id)
+	}
+
+	summary := make(map[string]int)
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for _, class := range abcClasses {
+			ids := grouped[class]
+			summary[class] = len(ids)
+			if len(ids) == 0 {
+				continue
+			}
+			if err := tx.Model(&Product{}).Where("id IN ?", ids).Update("abc_class", class).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// RunPlanner 为每个分类生成当天应盘的盘点单
+// 每个分类按剩余天数均摊本周期尚未盘点的产品，保证每个SKU每周期盘点一次
+func (h *CycleCountHandler) RunPlanner(now time.Time) ([]gin.H, error) {
+	created := make([]gin.H, 0)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	for _, class := range abcClasses {
+		// 当天已生成过的分类跳过，计划任务可重复执行
+		var todayCount int64
+		h.db.Model(&InventoryCheck{}).
+			Where("cycle_class = ? AND created_at >= ?", class, today).
+			Count(&todayCount)
+		if todayCount > 0 {
+			continue
+		}
+
+		start, end := cycleWindow(now, h.cadenceMonths(class))
+		remaining := h.uncountedProducts(class, start)
+		if len(remaining) == 0 {
+			continue
+		}
+
+		daysLeft := int(math.Ceil(end.Sub(today).Hours() / 24))
+		if daysLeft < 1 {
+			daysLeft = 1
+		}
+		quota := int(math.Ceil(float64(len(remaining)) / float64(daysLeft)))
+		productIDs := remaining[:quota]
+
+		cycleClass := class
+		items := make([]service.InventoryCheckItemInput, 0, len(productIDs))
+		for _, id := range productIDs {
+			items = append(items, service.InventoryCheckItemInput{ProductID: id})
+		}
+		check, err := h.check.Create(service.InventoryCheckInput{
+			Freeze:     h.cfg.Freeze,
+			CycleClass: &cycleClass,
+			Remark:     fmt.Sprintf("%s类循环盘点 %s", class, today.Format("2006-01-02")),
+			Items:      items,
+		})
+		if err != nil {
+			return created, fmt.Errorf("%s类盘点单创建失败: %w", class, err)
+		}
+
+		created = append(created, gin.H{
+			"id":        check.ID,
+			"checkNo":   check.CheckNo,
+			"abcClass":  class,
+			"itemCount": len(productIDs),
+			"remaining": len(remaining) - len(productIDs),
+		})
+	}
+
+	return created, nil
+}
+
+// StartScheduler 启动后台循环盘点任务：先重新分类，再生成当天的盘点单
+func (h *CycleCountHandler) StartScheduler() {
+	run := func() {
+		now := time.Now()
+		if _, err := h.RunClassification(now); err != nil {
+			log.Printf("cycle count: classification failed: %v", err)
+			return
+		}
+		checks, err := h.RunPlanner(now)
+		if err != nil {
+			log.Printf("cycle count: planner failed: %v", err)
+			return
+		}
+		log.Printf("cycle count: %d checks created", len(checks))
+	}
+
+	go func() {
+		run()
+		ticker := time.NewTicker(time.Duration(h.cfg.IntervalHours) * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
+
+// cadenceMonths 分类对应的盘点周期（月）
+func (h *CycleCountHandler) cadenceMonths(class string) int {
+	switch class {
+	case "A":
+		return h.cfg.ClassAMonths
+	case "B":
+		return h.cfg.ClassBMonths
+	default:
+		return h.cfg.ClassCMonths
+	}
+}
+
+// uncountedProducts 查询分类中本周期尚未被任何有效盘点单覆盖的产品
+func (h *CycleCountHandler) uncountedProducts(class string, cycleStart time.Time) []int64 {
+	counted := h.db.Table("biz_inventory_check_item i").
+		Select("DISTINCT i.product_id").
+		Joins("JOIN biz_inventory_check c ON c.id = i.check_id").
+		Where("c.status <> ? AND c.deleted_at IS NULL AND c.created_at >= ?", "CANCELLED", cycleStart)
+
+	var productIDs []int64
+	h.db.Model(&Product{}).
+		Where("status = ? AND abc_class = ?", 1, class).
+		Where("id NOT IN (?)", counted).
+		Order("sku_code ASC").
+		Pluck("id", &productIDs)
+	return productIDs
+}
+
+// cycleWindow 计算当前所在周期的起止时间，周期从每年1月起按月数对齐
+func cycleWindow(now time.Time, months int) (time.Time, time.Time) {
+	if months < 1 {
+		months = 1
+	}
+	monthIndex := (int(now.Month()) - 1) / months * months
+	start := time.Date(now.Year(), time.Month(monthIndex+1), 1, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, months, 0)
+}
+
+// classifyABC 按消耗金额降序累计占比划分ABC类，无消耗的产品归为C类
+func classifyABC(values []abcValue, aPercent, bPercent float64) map[int64]string {
+	sorted := make([]abcValue, len(values))
+	copy(sorted, values)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			return sorted[i].Value > sorted[j].Value
+		}
+		return sorted[i].Qty > sorted[j].Qty
+	})
+
+	var total float64
+	for _, v := range sorted {
+		total += v.Value
+	}
+
+	result := make(map[int64]string, len(sorted))
+	var cumulative float64
+	for _, v := range sorted {
+		if total <= 0 || v.Value <= 0 {
+			result[v.ProductID] = "C"
+			continue
+		}
+		// 以加入前的累计占比判断，保证金额最高的产品总是A类
+		share := cumulative / total * 100
+		switch {
+		case share < aPercent:
+			result[v.ProductID] = "A"
+		case share < bPercent:
+			result[v.ProductID] = "B"
+		default:
+			result[v.ProductID] = "C"
+		}
+		cumulative += v.Value
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyABC(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []abcValue
+		want   map[int64]string
+	}{
+		{
+			name: "按累计占比划分",
+			// 总额100，按金额降序：1号加入前累计0%为A，2号50%为A，3号80%为B，4号95%为C
+			values: []abcValue{
+				{ProductID: 3, Value: 15},
+				{ProductID: 1, Value: 50},
+				{ProductID: 4, Value: 5},
+				{ProductID: 2, Value: 30},
+			},
+			want: map[int64]string{1: "A", 2: "A", 3: "B", 4: "C"},
+		},
+		{
+			name: "累计占比恰好等于界限时归入下一类",
+			// 1号累计0%为A，2号累计80%不小于80%为B，3号累计95%不小于95%为C
+			values: []abcValue{
+				{ProductID: 1, Value: 80},
+				{ProductID: 2, Value: 15},
+				{ProductID: 3, Value: 5},
+			},
+			want: map[int64]string{1: "A", 2: "B", 3: "C"},
+		},
+		{
+			name:   "金额最高的产品总是A类",
+			values: []abcValue{{ProductID: 1, Value: 99}, {ProductID: 2, Value: 1}},
+			want:   map[int64]string{1: "A", 2: "C"},
+		},
+		{
+			name: "金额相同时数量多的在前",
+			// 3号累计0%为A，2号数量多排在前面，累计90%为B，1号累计95%为C
+			values: []abcValue{
+				{ProductID: 1, Qty: 1, Value: 5},
+				{ProductID: 2, Qty: 5, Value: 5},
+				{ProductID: 3, Qty: 1, Value: 90},
+			},
+			want: map[int64]string{3: "A", 2: "B", 1: "C"},
+		},
+		{
+			name:   "无消耗的产品为C类",
+			values: []abcValue{{ProductID: 1, Value: 10}, {ProductID: 2}},
+			want:   map[int64]string{1: "A", 2: "C"},
+		},
+		{
+			name:   "全部无消耗",
+			values: []abcValue{{ProductID: 1}, {ProductID: 2}},
+			want:   map[int64]string{1: "C", 2: "C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyABC(tt.values, 80, 95)
+			if len(got) != len(tt.want) {
+				t.Fatalf("classifyABC() = %v, want %v", got, tt.want)
+			}
+			for id, class := range tt.want {
+				if got[id] != class {
+					t.Errorf("product %d = %q, want %q (all %v)", id, got[id], class, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCycleWindow(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		months    int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"每月一个周期", time.Date(2026, 5, 17, 15, 30, 0, 0, loc), 1, date(5, 1), date(6, 1)},
+		{"季度周期的首日", date(4, 1), 3, date(4, 1), date(7, 1)},
+		{"季度周期的最后一刻", time.Date(2026, 6, 30, 23, 59, 59, 0, loc), 3, date(4, 1), date(7, 1)},
+		{"半年周期跨到次年", date(11, 20), 6, date(7, 1), time.Date(2027, 1, 1, 0, 0, 0, 0, loc)},
+		{"一年一个周期", date(8, 8), 12, date(1, 1), time.Date(2027, 1, 1, 0, 0, 0, 0, loc)},
+		{"无效月数按每月计算", date(2, 14), 0, date(2, 1), date(3, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := cycleWindow(tt.now, tt.months)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("cycleWindow(%s, %d) = [%s, %s), want [%s, %s)",
+					tt.now.Format(time.RFC3339), tt.months, start.Format(time.RFC3339), end.Format(time.RFC3339),
+					tt.wantStart.Format(time.RFC3339), tt.wantEnd.Format(time.RFC3339))
+			}
+			if tt.now.Before(start) || !tt.now.Before(end) {
+				t.Errorf("now %s outside window [%s, %s)", tt.now, start, end)
+			}
+		})
+	}
+}
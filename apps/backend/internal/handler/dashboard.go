@@ -30,34 +30,36 @@ type OverviewStats struct {
 	ProcurementCount int64   `json:"procurementCount"` // 采购单数量
 }
 
-// GetOverviewStats 获取概览统计
+// GetOverviewStats 获取概览统计，出库单和采购单按当前用户的数据权限统计
 func (h *DashboardHandler) GetOverviewStats(c *gin.Context) {
 	var stats OverviewStats
+	scope := currentDataScope(c, h.db)
+	outboundScope := scope.filter("applicant_id", "dept_id")
 
 	// 产品数量
-	h.db.Table("base_product").Where("status = ?", 1).Count(&stats.ProductCount)
+	h.db.Table("base_product").Where("status = ? AND deleted_at IS NULL", 1).Count(&stats.ProductCount)
 
 	// 总库存量
-	h.db.Table("base_product").Select("COALESCE(SUM(stock_qty), 0)").Scan(&stats.TotalStock)
+	h.db.Table("base_product").Select("COALESCE(SUM(stock_qty), 0)").Where("deleted_at IS NULL").Scan(&stats.TotalStock)
 
 	// 待入库单数 (状态为待入库)
-	h.db.Table("biz_inbound").Where("status = ?", "PENDING").Count(&stats.PendingInbound)
+	h.db.Table("biz_inbound").Where("status = ? AND deleted_at IS NULL", "PENDING").Count(&stats.PendingInbound)
 
 	// 待出库单数 (状态为待出库)
-	h.db.Table("biz_outbound").Where("status = ?", "PENDING").Count(&stats.PendingOutbound)
+	h.db.Table("biz_outbound").Scopes(outboundScope).Where("status = ? AND deleted_at IS NULL", "PENDING").Count(&stats.PendingOutbound)
 
 	// 低库存预警数 (库存低于预警阈值)
-	h.db.Table("base_product").Where("stock_qty < alert_threshold AND status = ?", 1).Count(&stats.LowStockCount)
+	h.db.Table("base_product").Where("stock_qty < alert_threshold AND status = ? AND deleted_at IS NULL", 1).Count(&stats.LowStockCount)
 
 	// 今日入库数
-	today := time.Now().Format("2006-01-02")
-	h.db.Table("biz_inbound").Where("DATE(created_at) = ? AND status = ?", today, "COMPLETED").Count(&stats.TodayInbound)
+	start, end := dayRange(time.Now())
+	h.db.Table("biz_inbound").Where("created_at >= ? AND created_at < ? AND status = ? AND deleted_at IS NULL", start, end, "COMPLETED").Count(&stats.TodayInbound)
 
 	// 今日出库数
-	h.db.Table("biz_outbound").Where("DATE(created_at) = ? AND status = ?", today, "COMPLETED").Count(&stats.TodayOutbound)
+	h.db.Table("biz_outbound").Scopes(outboundScope).Where("created_at >= ? AND created_at < ? AND status = ? AND deleted_at IS NULL", start, end, "COMPLETED").Count(&stats.TodayOutbound)
 
 	// 采购单数量
-	h.db.Table("biz_procurement").Count(&stats.ProcurementCount)
+	h.db.Table("biz_procurement").Scopes(scope.filter("applicant_id", "")).Where("deleted_at IS NULL").Count(&stats.ProcurementCount)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
@@ -72,25 +74,28 @@ type StockTrendItem struct {
 	Outbound float64 `json:"outbound"`
 }
 
-// GetStockTrend 获取最近7天库存变化趋势
+// GetStockTrend 获取最近7天库存变化趋势，只统计当前用户有权查看的库存流水
 func (h *DashboardHandler) GetStockTrend(c *gin.Context) {
 	var trends []StockTrendItem
+	scope := currentDataScope(c, h.db).stockLogFilter("")
 
 	// 获取最近7天的日期
 	for i := 6; i >= 0; i-- {
-		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
+		day := time.Now().AddDate(0, 0, -i)
+		date := day.Format("2006-01-02")
+		start, end := dayRange(day)
 		var inbound, outbound float64
 
 		// 查询当天入库数量
-		h.db.Table("biz_stock_log").
+		h.db.Table("biz_stock_log").Scopes(scope).
 			Select("COALESCE(SUM(change_qty), 0)").
-			Where("type = ? AND DATE(created_at) = ?", "IN", date).
+			Where("type = ? AND created_at >= ? AND created_at < ?", "IN", start, end).
 			Scan(&inbound)
 
 		// 查询当天出库数量
-		h.db.Table("biz_stock_log").
+		h.db.Table("biz_stock_log").Scopes(scope).
 			Select("COALESCE(SUM(ABS(change_qty)), 0)").
-			Where("type = ? AND DATE(created_at) = ?", "OUT", date).
+			Where("type = ? AND created_at >= ? AND created_at < ?", "OUT", start, end).
 			Scan(&outbound)
 
 		trends = append(trends, StockTrendItem{
@@ -119,7 +124,7 @@ func (h *DashboardHandler) GetCategoryStock(c *gin.Context) {
 	h.db.Table("base_product p").
 		Select("c.name, COALESCE(SUM(p.stock_qty), 0) as quantity").
 		Joins("LEFT JOIN base_category c ON p.category_id = c.id").
-		Where("p.status = ?", 1).
+		Where("p.status = ? AND p.deleted_at IS NULL", 1).
 		Group("c.id, c.name").
 		Scan(&items)
 
@@ -144,7 +149,7 @@ func (h *DashboardHandler) GetLowStockProducts(c *gin.Context) {
 
 	h.db.Table("base_product").
 		Select("id, name, sku_code, stock_qty, alert_threshold").
-		Where("stock_qty < alert_threshold AND status = ?", 1).
+		Where("stock_qty < alert_threshold AND status = ? AND deleted_at IS NULL", 1).
 		Order("stock_qty ASC").
 		Limit(10).
 		Scan(&products)
@@ -164,17 +169,35 @@ type RecentActivity struct {
 	CreatedAt string `json:"createdAt"`
 }
 
-// GetRecentActivities 获取最近操作动态
+// GetRecentActivities 获取最近操作动态，只包含当前用户有权查看的库存流水
 func (h *DashboardHandler) GetRecentActivities(c *gin.Context) {
-	var activities []RecentActivity
+	var rows []struct {
+		ID        uint
+		Type      string
+		OrderNo   string
+		Operator  string
+		CreatedAt time.Time
+	}
 
-	// 从库存流水中获取最近的操作记录
+	// 从库存流水中获取最近的操作记录，时间在程序中格式化，不依赖数据库的日期函数
 	h.db.Table("biz_stock_log sl").
-		Select("sl.id, sl.type, sl.related_no as order_no, u.real_name as operator, DATE_FORMAT(sl.created_at, '%Y-%m-%d %H:%i') as created_at").
+		Select("sl.id, sl.type, sl.related_no as order_no, u.real_name as operator, sl.created_at").
 		Joins("LEFT JOIN sys_user u ON sl.operator_id = u.id").
+		Scopes(currentDataScope(c, h.db).stockLogFilter("sl")).
 		Order("sl.created_at DESC").
 		Limit(10).
-		Scan(&activities)
+		Scan(&rows)
+
+	activities := make([]RecentActivity, 0, len(rows))
+	for _, row := range rows {
+		activities = append(activities, RecentActivity{
+			ID:        row.ID,
+			Type:      row.Type,
+			OrderNo:   row.OrderNo,
+			Operator:  row.Operator,
+			CreatedAt: row.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
@@ -182,3 +205,8 @@ func (h *DashboardHandler) GetRecentActivities(c *gin.Context) {
 	})
 }
 
+// dayRange 返回某天的起止时间 [当天0点, 次日0点)，用于按天统计，避免依赖数据库的 DATE() 函数
+func dayRange(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"easywms/internal/model"
+	"easywms/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 数据权限范围
+const (
+	dataScopeAll  = repository.ScopeAll  // 全部数据
+	dataScopeDept = repository.ScopeDept // 本部门及下级部门数据
+	dataScopeSelf = repository.ScopeSelf // 仅本人数据
+)
+
+// validDataScope 判断数据权限范围是否有效
+func validDataScope(scope string) bool {
+	return scope == dataScopeAll || scope == dataScopeDept || scope == dataScopeSelf
+}
+
+// dataScope 当前用户的数据权限
+type dataScope struct {
+	scope   string
+	userID  int64
+	deptIDs []int64
+}
+
+// currentDataScope 根据当前用户角色解析数据权限，用户或角色缺失时只能看本人数据
+func currentDataScope(c *gin.Context, db *gorm.DB) dataScope {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return dataScope{scope: dataScopeSelf}
+	}
+
+	var user model.User
+	if err := db.Select("id", "dept_id", "role_code").First(&user, userID).Error; err != nil {
+		return dataScope{scope: dataScopeSelf, userID: userID.(int64)}
+	}
+
+	scope := defaultDataScope(user.RoleCode)
+	var role Role
+	if err := db.Where("code = ?", user.RoleCode).First(&role).Error; err == nil && validDataScope(role.DataScope) {
+		scope = role.DataScope
+	}
+
+	ds := dataScope{scope: scope, userID: user.ID}
+	if scope == dataScopeDept {
+		ds.deptIDs = departmentSubtree(db, user.DeptID)
+	}
+	return ds
+}
+
+// defaultDataScope 角色未配置时的默认数据权限
+func defaultDataScope(roleCode string) string {
+	if roleCode == "STAFF" {
+		return dataScopeDept
+	}
+	return dataScopeAll
+}
+
+// filter 返回数据权限查询条件。userColumn 为单据归属人字段；
+// deptColumn 为单据归属部门字段，为空时按归属人所在部门过滤
+func (s dataScope) filter(userColumn, deptColumn string) func(*gorm.DB) *gorm.DB {
+	return repository.ScopeFilter(s.forStore(), userColumn, deptColumn)
+}
+
+// forStore 转换为仓储层的数据权限
+func (s dataScope) forStore() repository.Scope {
+	return repository.Scope{Level: s.scope, UserID: s.userID, DeptIDs: s.deptIDs}
+}
+
+// stockLogFilter 返回库存流水的数据权限查询条件：出库流水随出库单归属申请人和部门，
+// 其他流水按操作人归属。alias 为流水表在查询中的别名，可为空
+func (s dataScope) stockLogFilter(alias string) func(*gorm.DB) *gorm.DB {
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if s.scope == dataScopeAll {
+			return db
+		}
+		newDB := db.Session(&gorm.Session{NewDB: true})
+		outbounds := s.filter("applicant_id", "dept_id")(newDB.Table("biz_outbound").Select("outbound_no"))
+		return db.Where(s.filter(prefix+"operator_id", "")(newDB).
+			Or(prefix+"related_no IN (?)", outbounds))
+	}
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+
+	"easywms/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DepartmentHandler 部门处理器
+type DepartmentHandler struct {
+	db *gorm.DB
+}
+
+// NewDepartmentHandler 创建部门处理器
+func NewDepartmentHandler(db *gorm.DB) *DepartmentHandler {
+	return &DepartmentHandler{db: db}
+}
+
+// GetDepartmentList 获取部门列表
+func (h *DepartmentHandler) GetDepartmentList(c *gin.Context) {
+	keyword := c.Query("keyword")
+
+	query := h.db.Model(&model.Department{})
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+
+	var departments []model.Department
+	query.Order("parent_id ASC, id ASC").Find(&departments)
+
+	items := make([]gin.H, 0, len(departments))
+	for _, d := range departments {
+		items = append(items, departmentView(d))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": items})
+}
+
+// GetDepartmentTree 获取部门树
+func (h *DepartmentHandler) GetDepartmentTree(c *gin.Context) {
+	var departments []model.Department
+	h.db.Order("parent_id ASC, id ASC").Find(&departments)
+
+	// 构建树形结构
+	type TreeNode struct {
+		ID       int64       `json:"id"`
+		Name     string      `json:"name"`
+		ParentID int64       `json:"parentId"`
+		Children []*TreeNode `json:"children,omitempty"`
+	}
+
+	nodeMap := make(map[int64]*TreeNode)
+	var roots []*TreeNode
+
+	for _, dept := range departments {
+		node := &TreeNode{
+			ID:       dept.ID,
+			Name:     dept.Name,
+			ParentID: dept.ParentID,
+			Children: []*TreeNode{},
+		}
+		nodeMap[dept.ID] = node
+	}
+
+	for _, dept := range departments {
+		node := nodeMap[dept.ID]
+		if dept.ParentID == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodeMap[dept.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": roots})
+}
+
+// GetDepartment 获取部门详情
+func (h *DepartmentHandler) GetDepartment(c *gin.Context) {
+	id := c.Param("id")
+	var department model.Department
+	if err := h.db.First(&department, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "部门不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": departmentView(department)})
+}
+
+// CreateDepartment 创建部门
+func (h *DepartmentHandler) CreateDepartment(c *gin.Context) {
+	var req struct {
+		Name     string `json:"name"`
+		ParentID int64  `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "部门名称不能为空"})
+		return
+	}
+	if req.ParentID != 0 && !h.exists(req.ParentID) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "上级部门不存在"})
+		return
+	}
+
+	department := model.Department{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+
+	if err := h.db.Create(&department).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "department", department.ID, nil, department)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": departmentView(department)})
+}
+
+// UpdateDepartment 更新部门
+func (h *DepartmentHandler) UpdateDepartment(c *gin.Context) {
+	id := c.Param("id")
+	var department model.Department
+	if err := h.db.First(&department, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "部门不存在"})
+		return
+	}
+
+	var req struct {
+		Name     string `json:"name"`
+		ParentID int64  `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "部门名称不能为空"})
+		return
+	}
+
+	// 移动部门时，新上级不能是自身或自身的下级部门
+	if req.ParentID != department.ParentID && req.ParentID != 0 {
+		if !h.exists(req.ParentID) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "上级部门不存在"})
+			return
+		}
+		for _, descendant := range departmentSubtree(h.db, department.ID) {
+			if descendant == req.ParentID {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能将部门移动到自身或其下级部门下"})
+				return
+			}
+		}
+	}
+
+	before := department
+	h.db.Model(&department).Updates(map[string]interface{}{
+		"name":      req.Name,
+		"parent_id": req.ParentID,
+	})
+	h.db.First(&department, department.ID)
+	recordAudit(c, h.db, auditUpdate, "department", department.ID, before, department)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
+
+// DeleteDepartment 删除部门
+func (h *DepartmentHandler) DeleteDepartment(c *gin.Context) {
+	id := c.Param("id")
+	var department model.Department
+	if err := h.db.First(&department, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "部门不存在"})
+		return
+	}
+
+	var count int64
+	h.db.Model(&model.Department{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "存在下级部门，无法删除"})
+		return
+	}
+
+	h.db.Model(&model.User{}).Where("dept_id = ?", id).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "部门下存在用户，无法删除"})
+		return
+	}
+
+	h.db.Model(&Outbound{}).Where("dept_id = ?", id).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "存在关联的领用单，无法删除"})
+		return
+	}
+
+	if err := h.db.Delete(&department).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败"})
+		return
+	}
+	recordAudit(c, h.db, auditDelete, "department", department.ID, department, nil)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// exists 判断部门是否存在
+func (h *DepartmentHandler) exists(id int64) bool {
+	var count int64
+	h.db.Model(&model.Department{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// departmentSubtree 查询部门及其全部下级部门ID
+func departmentSubtree(db *gorm.DB, rootID int64) []int64 {
+	var departments []model.Department
+	db.Select("id", "parent_id").Find(&departments)
+
+	children := make(map[int64][]int64)
+	for _, d := range departments {
+		children[d.ParentID] = append(children[d.ParentID], d.ID)
+	}
+
+	ids := []int64{rootID}
+	visited := map[int64]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// departmentView 部门信息输出
+func departmentView(d model.Department) gin.H {
+	return gin.H{
+		"id":         d.ID,
+		"name":       d.Name,
+		"parentId":   d.ParentID,
+		"createTime": d.CreatedAt,
+		"updateTime": d.UpdatedAt,
+	}
+}
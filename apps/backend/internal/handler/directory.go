@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"easywms/internal/auth"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// identityProviderLDAP 外部身份来源：LDAP
+const identityProviderLDAP = "LDAP"
+
+// errDirectoryNotLinked 同名本地账号未关联LDAP身份
+var errDirectoryNotLinked = errors.New("directory identity not linked")
+
+// syncDirectoryUser 返回认证身份对应的系统用户。LDAP 账号按身份关联查找用户，首次登录时按配置自动创建，
+// 之后每次登录同步姓名、邮箱、角色和部门；同名本地账号须由管理员关联后才能使用LDAP登录。本地账号直接查询
+func (h *AuthHandler) syncDirectoryUser(identity *auth.Identity) (model.User, error) {
+	var user model.User
+	if identity.Source != auth.SourceLDAP {
+		err := h.db.Where("username = ?", identity.Username).First(&user).Error
+		return user, err
+	}
+
+	ldapCfg := h.cfg.Auth.LDAP
+	now := time.Now()
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		deptID, err := h.resolveDepartment(tx, identity.Department, ldapCfg.DefaultDeptID)
+		if err != nil {
+			return err
+		}
+
+		var link UserIdentity
+		err = tx.Where("provider = ? AND subject = ?", identityProviderLDAP, identity.Username).First(&link).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 不接管未关联的同名本地账号，避免目录中的同名用户登录他人账号
+			var count int64
+			if err := tx.Model(&model.User{}).Where("username = ?", identity.Username).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errDirectoryNotLinked
+			}
+			if !ldapCfg.AutoProvision {
+				return gorm.ErrRecordNotFound
+			}
+			password, err := unusablePassword()
+			if err != nil {
+				return err
+			}
+			roleCode := identity.RoleCode
+			if roleCode == "" {
+				roleCode = "STAFF"
+			}
+			realName := identity.Name
+			if realName == "" {
+				realName = identity.Username
+			}
+			user = model.User{
+				Username:          identity.Username,
+				Password:          password,
+				RealName:          realName,
+				Email:             identity.Email,
+				DeptID:            deptID,
+				RoleCode:          roleCode,
+				Status:            1,
+				PasswordChangedAt: &now,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			return tx.Create(&UserIdentity{
+				Provider:    identityProviderLDAP,
+				Subject:     identity.Username,
+				UserID:      user.ID,
+				Email:       identity.Email,
+				LastLoginAt: &now,
+			}).Error
+		case err != nil:
+			return err
+		}
+
+		if err := tx.First(&user, link.UserID).Error; err != nil {
+			return err
+		}
+
+		// 目录为准，只同步目录中有值的属性；密码由目录管理，不受本地密码有效期约束
+		updates := map[string]interface{}{"password_changed_at": now}
+		if identity.Name != "" {
+			updates["real_name"] = identity.Name
+		}
+		if identity.Email != "" {
+			updates["email"] = identity.Email
+		}
+		if identity.RoleCode != "" {
+			updates["role_code"] = identity.RoleCode
+		}
+		if identity.Department != "" {
+			updates["dept_id"] = deptID
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// 记录最近登录时间
+		return tx.Model(&link).Updates(map[string]interface{}{
+			"email":         identity.Email,
+			"last_login_at": now,
+		}).Error
+	})
+	return user, err
+}
+
+// LinkDirectoryUser 管理员把本地账号关联到同名或指定的LDAP账号，关联后可使用LDAP登录并由目录同步属性
+func (h *UserHandler) LinkDirectoryUser(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	var req struct {
+		Subject string `json:"subject"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+			return
+		}
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" {
+		subject = user.Username
+	}
+
+	var link UserIdentity
+	err := h.db.Where("provider = ? AND subject = ?", identityProviderLDAP, subject).First(&link).Error
+	switch {
+	case err == nil && link.UserID != user.ID:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "该LDAP账号已关联其他用户"})
+		return
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已关联"})
+		return
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "关联失败"})
+		return
+	}
+
+	link = UserIdentity{Provider: identityProviderLDAP, Subject: subject, UserID: user.ID, Email: user.Email}
+	if err := h.db.Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "关联失败"})
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "user_identity", link.ID, nil, link)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已关联"})
+}
+
+// resolveDepartment 按名称查找部门，不存在时在顶层创建；名称为空时返回默认部门
+func (h *AuthHandler) resolveDepartment(tx *gorm.DB, name string, defaultID int64) (int64, error) {
+	if name == "" {
+		return defaultID, nil
+	}
+
+	var dept model.Department
+	err := tx.Where("name = ?", name).Order("id ASC").First(&dept).Error
+	if err == nil {
+		return dept.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	dept = model.Department{Name: name}
+	if err := tx.Create(&dept).Error; err != nil {
+		return 0, err
+	}
+	return dept.ID, nil
+}
+
+// userIDByName 按账号查询用户ID，用于登录日志
+func (h *AuthHandler) userIDByName(username string) *int64 {
+	var user model.User
+	if err := h.db.Select("id").Where("username = ?", username).First(&user).Error; err != nil {
+		return nil
+	}
+	return &user.ID
+}
+
+// unusablePassword 为外部目录或单点登录创建的账号生成随机密码，不告知用户，只能通过外部身份登录
+func unusablePassword() (string, error) {
+	raw, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return utils.HashPassword(raw)
+}
@@ -1,75 +1,73 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+	"easywms/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // Inbound 入库单模型
-type Inbound struct {
-	ID              int64      `json:"id" gorm:"column:id;primaryKey"`
-	InboundNo       string     `json:"orderNo" gorm:"column:inbound_no"`
-	SourceID        *int64     `json:"sourceId" gorm:"column:source_id"`
-	IsTemporary     int        `json:"isTemporary" gorm:"column:is_temporary"`
-	Status          int        `json:"statusCode" gorm:"column:status"`
-	InboundDate     *time.Time `json:"inboundDate" gorm:"column:inbound_date"`
-	WarehouseUserID *int64     `json:"operatorId" gorm:"column:warehouse_user_id"`
-	Remark          string     `json:"remark" gorm:"column:remark"`
-	CreatedAt       time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt       time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
-	// 关联字段
-	Status_       string  `json:"status" gorm:"-"`
-	Type          string  `json:"type" gorm:"-"`
-	SourceOrderNo string  `json:"sourceOrderNo" gorm:"-"`
-	OperatorName  string  `json:"operatorName" gorm:"-"`
-	TotalQuantity float64 `json:"totalQuantity" gorm:"-"`
-	WarehouseName string  `json:"warehouseName" gorm:"-"`
-}
-
-func (Inbound) TableName() string {
-	return "biz_inbound"
-}
+type Inbound = model.Inbound
 
 // InboundItem 入库明细模型
-type InboundItem struct {
-	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
-	InboundID int64     `json:"inboundId" gorm:"column:inbound_id"`
-	ProductID int64     `json:"productId" gorm:"column:product_id"`
-	ActualQty float64   `json:"quantity" gorm:"column:actual_qty"`
-	Location  string    `json:"locationName" gorm:"column:location"`
-	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	// 关联字段
-	ProductName string `json:"productName" gorm:"-"`
-	ProductCode string `json:"productCode" gorm:"-"`
-}
-
-func (InboundItem) TableName() string {
-	return "biz_inbound_item"
-}
+type InboundItem = model.InboundItem
 
 // InboundHandler 入库处理器
 type InboundHandler struct {
-	db *gorm.DB
+	db      *gorm.DB
+	inbound *service.InboundService
 }
 
 // NewInboundHandler 创建入库处理器
-func NewInboundHandler(db *gorm.DB) *InboundHandler {
-	return &InboundHandler{db: db}
+func NewInboundHandler(db *gorm.DB, cfg *config.Config) *InboundHandler {
+	store := repository.NewStore(db, sequence.New(cfg.Numbering))
+	return &InboundHandler{db: db, inbound: service.NewInboundService(store)}
+}
+
+// inboundRequest 创建和修改入库单的请求
+type inboundRequest struct {
+	SourceID    *int64 `json:"sourceId"`
+	IsTemporary int    `json:"isTemporary"`
+	Status      string `json:"status"`
+	Remark      string `json:"remark"`
+	Items       []struct {
+		ProductID int64   `json:"productId"`
+		Quantity  float64 `json:"quantity"`
+		Unit      string  `json:"unit"`
+		Location  string  `json:"location"`
+	} `json:"items"`
+}
+
+func (r inboundRequest) input() service.InboundInput {
+	in := service.InboundInput{
+		SourceID:    r.SourceID,
+		IsTemporary: r.IsTemporary,
+		Remark:      r.Remark,
+		Complete:    r.Status == "completed",
+	}
+	for _, item := range r.Items {
+		in.Items = append(in.Items, service.InboundItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Unit:      item.Unit,
+			Location:  item.Location,
+		})
+	}
+	return in
 }
 
 // GetInboundList 获取入库单列表
 func (h *InboundHandler) GetInboundList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
-	orderNo := c.Query("orderNo")
-	status := c.Query("status")
-	startDate := c.Query("startDate")
-	endDate := c.Query("endDate")
 
 	if page < 1 {
 		page = 1
@@ -78,110 +76,26 @@ func (h *InboundHandler) GetInboundList(c *gin.Context) {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
-	query := h.db.Model(&Inbound{})
-
-	if orderNo != "" {
-		query = query.Where("inbound_no LIKE ?", "%"+orderNo+"%")
+	filter := repository.InboundFilter{
+		OrderNo:   c.Query("orderNo"),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+		Deleted:   c.Query("deleted") == "1",
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
 	}
-	if status != "" {
-		statusCode := 0
+	if status := c.Query("status"); status != "" {
+		statusCode := service.InboundDraft
 		if status == "completed" {
-			statusCode = 1
+			statusCode = service.InboundCompleted
 		}
-		query = query.Where("status = ?", statusCode)
-	}
-	if startDate != "" {
-		query = query.Where("created_at >= ?", startDate)
+		filter.Status = &statusCode
 	}
-	if endDate != "" {
-		query = query.Where("created_at <= ?", endDate+" 23:59:59")
-	}
-
-	var total int64
-	query.Count(&total)
-
-	var inbounds []Inbound
-	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&inbounds)
 
-	// 加载关联信息
-	userIDs := make([]int64, 0)
-	sourceIDs := make([]int64, 0)
-	inboundIDs := make([]int64, 0)
-
-	for _, i := range inbounds {
-		if i.WarehouseUserID != nil {
-			userIDs = append(userIDs, *i.WarehouseUserID)
-		}
-		if i.SourceID != nil {
-			sourceIDs = append(sourceIDs, *i.SourceID)
-		}
-		inboundIDs = append(inboundIDs, i.ID)
-	}
-
-	// 用户名映射
-	userMap := make(map[int64]string)
-	if len(userIDs) > 0 {
-		var users []struct {
-			ID       int64  `gorm:"column:id"`
-			RealName string `gorm:"column:real_name"`
-		}
-		h.db.Table("sys_user").Where("id IN ?", userIDs).Find(&users)
-		for _, u := range users {
-			userMap[u.ID] = u.RealName
-		}
-	}
-
-	// 采购单号映射
-	sourceMap := make(map[int64]string)
-	if len(sourceIDs) > 0 {
-		var procurements []struct {
-			ID      int64  `gorm:"column:id"`
-			OrderNo string `gorm:"column:order_no"`
-		}
-		h.db.Table("biz_procurement").Where("id IN ?", sourceIDs).Find(&procurements)
-		for _, p := range procurements {
-			sourceMap[p.ID] = p.OrderNo
-		}
-	}
-
-	// 计算总数量
-	qtyMap := make(map[int64]float64)
-	if len(inboundIDs) > 0 {
-		var qtys []struct {
-			InboundID int64   `gorm:"column:inbound_id"`
-			Total     float64 `gorm:"column:total"`
-		}
-		h.db.Table("biz_inbound_item").
-			Select("inbound_id, SUM(actual_qty) as total").
-			Where("inbound_id IN ?", inboundIDs).
-			Group("inbound_id").
-			Find(&qtys)
-		for _, q := range qtys {
-			qtyMap[q.InboundID] = q.Total
-		}
-	}
-
-	// 填充关联信息
-	for i := range inbounds {
-		if inbounds[i].WarehouseUserID != nil {
-			inbounds[i].OperatorName = userMap[*inbounds[i].WarehouseUserID]
-		}
-		if inbounds[i].SourceID != nil {
-			inbounds[i].SourceOrderNo = sourceMap[*inbounds[i].SourceID]
-			inbounds[i].Type = "purchase"
-		} else if inbounds[i].IsTemporary == 1 {
-			inbounds[i].Type = "other"
-		} else {
-			inbounds[i].Type = "other"
-		}
-		inbounds[i].TotalQuantity = qtyMap[inbounds[i].ID]
-		if inbounds[i].Status == 1 {
-			inbounds[i].Status_ = "completed"
-		} else {
-			inbounds[i].Status_ = "draft"
-		}
-		inbounds[i].WarehouseName = "默认仓库"
+	inbounds, total, err := h.inbound.List(filter)
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -195,45 +109,13 @@ func (h *InboundHandler) GetInboundList(c *gin.Context) {
 
 // GetInbound 获取入库单详情
 func (h *InboundHandler) GetInbound(c *gin.Context) {
-	id := c.Param("id")
-	var inbound Inbound
-	if err := h.db.First(&inbound, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "入库单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	inbound, items, err := h.inbound.Get(id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
 		return
 	}
 
-	// 获取明细
-	var items []InboundItem
-	h.db.Where("inbound_id = ?", id).Find(&items)
-
-	// 获取产品信息
-	productIDs := make([]int64, 0)
-	for _, item := range items {
-		productIDs = append(productIDs, item.ProductID)
-	}
-
-	productMap := make(map[int64]Product)
-	if len(productIDs) > 0 {
-		var products []Product
-		h.db.Where("id IN ?", productIDs).Find(&products)
-		for _, p := range products {
-			productMap[p.ID] = p
-		}
-	}
-
-	for i := range items {
-		if p, ok := productMap[items[i].ProductID]; ok {
-			items[i].ProductName = p.Name
-			items[i].ProductCode = p.SkuCode
-		}
-	}
-
-	// 状态转换
-	status := "draft"
-	if inbound.Status == 1 {
-		status = "completed"
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
@@ -241,7 +123,7 @@ func (h *InboundHandler) GetInbound(c *gin.Context) {
 			"orderNo":     inbound.InboundNo,
 			"sourceId":    inbound.SourceID,
 			"isTemporary": inbound.IsTemporary,
-			"status":      status,
+			"status":      service.InboundStatusName(inbound.Status),
 			"inboundDate": inbound.InboundDate,
 			"remark":      inbound.Remark,
 			"createTime":  inbound.CreatedAt,
@@ -252,183 +134,71 @@ func (h *InboundHandler) GetInbound(c *gin.Context) {
 
 // CreateInbound 创建入库单
 func (h *InboundHandler) CreateInbound(c *gin.Context) {
-	var req struct {
-		SourceID    *int64 `json:"sourceId"`
-		IsTemporary int    `json:"isTemporary"`
-		Remark      string `json:"remark"`
-		Items       []struct {
-			ProductID int64   `json:"productId"`
-			Quantity  float64 `json:"quantity"`
-			Location  string  `json:"location"`
-		} `json:"items"`
-	}
-
+	var req inboundRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
 		return
 	}
 
-	userID, _ := c.Get("userID")
-	userIDInt := userID.(int64)
-	inboundNo := fmt.Sprintf("IN%s%03d", time.Now().Format("20060102150405"), time.Now().Nanosecond()%1000)
-
-	// 创建时设置入库日期为当前时间
-	now := time.Now()
-	inbound := Inbound{
-		InboundNo:       inboundNo,
-		SourceID:        req.SourceID,
-		IsTemporary:     req.IsTemporary,
-		Status:          0,
-		InboundDate:     &now,
-		WarehouseUserID: &userIDInt,
-		Remark:          req.Remark,
-	}
-
-	tx := h.db.Begin()
-
-	if err := tx.Create(&inbound).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+	inbound, err := h.inbound.Create(c.GetInt64("userID"), req.input())
+	if err != nil {
+		serviceError(c, err, "创建失败")
 		return
 	}
 
-	for _, item := range req.Items {
-		inboundItem := InboundItem{
-			InboundID: inbound.ID,
-			ProductID: item.ProductID,
-			ActualQty: item.Quantity,
-			Location:  item.Location,
-		}
-		if err := tx.Create(&inboundItem).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建明细失败"})
-			return
-		}
-	}
-
-	tx.Commit()
+	recordAudit(c, h.db, auditCreate, "inbound", inbound.ID, nil, h.snapshot(inbound.ID))
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": inbound})
 }
 
-// UpdateInbound 更新入库单
+// UpdateInbound 更新入库单，状态改为 completed 时确认入库
 func (h *InboundHandler) UpdateInbound(c *gin.Context) {
-	id := c.Param("id")
-	var inbound Inbound
-	if err := h.db.First(&inbound, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "入库单不存在"})
-		return
-	}
-
-	// 已完成的入库单不允许修改
-	if inbound.Status == 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已完成的入库单不能修改"})
-		return
-	}
-
-	var req struct {
-		SourceID    *int64 `json:"sourceId"`
-		IsTemporary int    `json:"isTemporary"`
-		Status      string `json:"status"`
-		Remark      string `json:"remark"`
-		Items       []struct {
-			ProductID int64   `json:"productId"`
-			Quantity  float64 `json:"quantity"`
-			Location  string  `json:"location"`
-		} `json:"items"`
-	}
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req inboundRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
 		return
 	}
-
-	statusCode := 0
-	if req.Status == "completed" {
-		statusCode = 1
-	}
-
-	tx := h.db.Begin()
-
-	// 如果状态变为已完成，需要更新库存
-	if statusCode == 1 && inbound.Status == 0 {
-		// 使用新的明细数据计算库存
-		for _, item := range req.Items {
-			// 更新产品库存
-			tx.Model(&Product{}).Where("id = ?", item.ProductID).
-				Update("stock_qty", gorm.Expr("stock_qty + ?", item.Quantity))
-
-			// 记录库存流水
-			stockLog := StockLog{
-				ProductID:  item.ProductID,
-				Type:       "IN",
-				ChangeQty:  item.Quantity,
-				RelatedNo:  inbound.InboundNo,
-				OperatorID: inbound.WarehouseUserID,
-			}
-
-			// 获取当前库存
-			var product Product
-			tx.First(&product, item.ProductID)
-			stockLog.SnapshotQty = product.StockQty
-
-			tx.Create(&stockLog)
-		}
-
-		// 更新入库时间
-		now := time.Now()
-		tx.Model(&inbound).Updates(map[string]interface{}{
-			"source_id":    req.SourceID,
-			"is_temporary": req.IsTemporary,
-			"status":       statusCode,
-			"inbound_date": now,
-			"remark":       req.Remark,
-		})
-	} else {
-		// 更新主表字段（除日期外）
-		tx.Model(&inbound).Updates(map[string]interface{}{
-			"source_id":    req.SourceID,
-			"is_temporary": req.IsTemporary,
-			"status":       statusCode,
-			"remark":       req.Remark,
-		})
+	in := req.input()
+	if in.Complete && !hasPermission(c, h.db, "INBOUND_APPROVE") {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无入库审核权限"})
+		return
 	}
 
-	// 更新明细：先删除旧的，再插入新的
-	if len(req.Items) > 0 {
-		tx.Where("inbound_id = ?", id).Delete(&InboundItem{})
-		for _, item := range req.Items {
-			inboundItem := InboundItem{
-				InboundID: inbound.ID,
-				ProductID: item.ProductID,
-				ActualQty: item.Quantity,
-				Location:  item.Location,
-			}
-			tx.Create(&inboundItem)
-		}
+	before := h.snapshot(id)
+	if err := h.inbound.Update(id, in); err != nil {
+		serviceError(c, err, "更新失败")
+		return
 	}
-
-	tx.Commit()
+	recordAudit(c, h.db, auditUpdate, "inbound", id, before, h.snapshot(id))
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
 }
 
-// DeleteInbound 删除入库单
+// DeleteInbound 删除入库单（软删除，明细保留以便恢复）
 func (h *InboundHandler) DeleteInbound(c *gin.Context) {
-	id := c.Param("id")
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
 
-	var inbound Inbound
-	if err := h.db.First(&inbound, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "入库单不存在"})
+	before := h.snapshot(id)
+	if err := h.inbound.Delete(id); err != nil {
+		serviceError(c, err, "删除失败")
 		return
 	}
+	recordAudit(c, h.db, auditDelete, "inbound", id, before, nil)
 
-	if inbound.Status == 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已完成的入库单不能删除"})
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// RestoreInbound 恢复已删除的入库单
+func (h *InboundHandler) RestoreInbound(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.inbound.Restore(id); err != nil {
+		serviceError(c, err, "恢复失败")
 		return
 	}
+	recordAudit(c, h.db, auditRestore, "inbound", id, nil, h.snapshot(id))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
 
-	tx := h.db.Begin()
-	tx.Where("inbound_id = ?", id).Delete(&InboundItem{})
-	tx.Delete(&Inbound{}, id)
-	tx.Commit()
-
-	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+// snapshot 入库单审计快照
+func (h *InboundHandler) snapshot(id int64) map[string]interface{} {
+	return auditDocument(h.db, &Inbound{}, id, &[]InboundItem{}, "inbound_id")
 }
@@ -1,72 +1,44 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+	"easywms/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // InventoryCheck 盘点单模型
-type InventoryCheck struct {
-	ID        int64      `json:"id" gorm:"column:id;primaryKey"`
-	CheckNo   string     `json:"checkNo" gorm:"column:check_no"`
-	CheckerID *int64     `json:"checkerId" gorm:"column:checker_id"`
-	Status    string     `json:"status" gorm:"column:status"`
-	CheckDate *time.Time `json:"checkDate" gorm:"column:check_date"`
-	Remark    string     `json:"remark" gorm:"column:remark"`
-	CreatedAt time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
-	// 关联字段
-	OperatorID    int64  `json:"operatorId" gorm:"-"`
-	OperatorName  string `json:"operatorName" gorm:"-"`
-	WarehouseID   string `json:"warehouseId" gorm:"-"`
-	WarehouseName string `json:"warehouseName" gorm:"-"`
-}
-
-func (InventoryCheck) TableName() string {
-	return "biz_inventory_check"
-}
+type InventoryCheck = model.InventoryCheck
 
 // InventoryCheckItem 盘点明细模型
-type InventoryCheckItem struct {
-	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
-	CheckID   int64     `json:"checkId" gorm:"column:check_id"`
-	ProductID int64     `json:"productId" gorm:"column:product_id"`
-	BookQty   float64   `json:"systemQuantity" gorm:"column:book_qty"`
-	ActualQty float64   `json:"actualQuantity" gorm:"column:actual_qty"`
-	DiffQty   float64   `json:"differenceQuantity" gorm:"column:diff_qty"`
-	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	// 关联字段
-	ProductName string `json:"productName" gorm:"-"`
-	ProductCode string `json:"productCode" gorm:"-"`
-}
+type InventoryCheckItem = model.InventoryCheckItem
 
-func (InventoryCheckItem) TableName() string {
-	return "biz_inventory_check_item"
-}
+// InventoryCheckCount 盘点计数记录模型
+type InventoryCheckCount = model.InventoryCheckCount
 
 // InventoryCheckHandler 盘点处理器
 type InventoryCheckHandler struct {
-	db *gorm.DB
+	db    *gorm.DB
+	check *service.InventoryCheckService
 }
 
 // NewInventoryCheckHandler 创建盘点处理器
-func NewInventoryCheckHandler(db *gorm.DB) *InventoryCheckHandler {
-	return &InventoryCheckHandler{db: db}
+func NewInventoryCheckHandler(db *gorm.DB, cfg *config.Config) *InventoryCheckHandler {
+	store := repository.NewStore(db, sequence.New(cfg.Numbering))
+	return &InventoryCheckHandler{db: db, check: service.NewInventoryCheckService(store)}
 }
 
 // GetInventoryCheckList 获取盘点单列表
 func (h *InventoryCheckHandler) GetInventoryCheckList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
-	checkNo := c.Query("checkNo")
-	status := c.Query("status")
-	startDate := c.Query("startDate")
-	endDate := c.Query("endDate")
 
 	if page < 1 {
 		page = 1
@@ -75,77 +47,18 @@ func (h *InventoryCheckHandler) GetInventoryCheckList(c *gin.Context) {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
-	query := h.db.Model(&InventoryCheck{})
-
-	if checkNo != "" {
-		query = query.Where("check_no LIKE ?", "%"+checkNo+"%")
-	}
-	if status != "" {
-		// 状态映射
-		statusMap := map[string]string{
-			"draft":     "CHECKING",
-			"checking":  "CHECKING",
-			"completed": "FINISHED",
-			"cancelled": "CANCELLED",
-		}
-		if dbStatus, ok := statusMap[status]; ok {
-			query = query.Where("status = ?", dbStatus)
-		}
-	}
-	if startDate != "" {
-		query = query.Where("created_at >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("created_at <= ?", endDate+" 23:59:59")
-	}
-
-	var total int64
-	query.Count(&total)
-
-	var checks []InventoryCheck
-	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&checks)
-
-	// 加载用户信息
-	userIDs := make([]int64, 0)
-	for _, check := range checks {
-		if check.CheckerID != nil {
-			userIDs = append(userIDs, *check.CheckerID)
-		}
-	}
-
-	userMap := make(map[int64]string)
-	if len(userIDs) > 0 {
-		var users []struct {
-			ID       int64  `gorm:"column:id"`
-			RealName string `gorm:"column:real_name"`
-		}
-		h.db.Table("sys_user").Where("id IN ?", userIDs).Find(&users)
-		for _, u := range users {
-			userMap[u.ID] = u.RealName
-		}
-	}
-
-	// 填充关联信息和状态转换
-	for i := range checks {
-		if checks[i].CheckerID != nil {
-			checks[i].OperatorID = *checks[i].CheckerID
-			checks[i].OperatorName = userMap[*checks[i].CheckerID]
-		}
-		checks[i].WarehouseID = "1"
-		checks[i].WarehouseName = "默认仓库"
-
-		// 状态转换
-		switch checks[i].Status {
-		case "CHECKING":
-			checks[i].Status = "checking"
-		case "FINISHED":
-			checks[i].Status = "completed"
-		case "CANCELLED":
-			checks[i].Status = "cancelled"
-		default:
-			checks[i].Status = "draft"
-		}
+	checks, total, err := h.check.List(repository.InventoryCheckFilter{
+		CheckNo:   c.Query("checkNo"),
+		Status:    service.InventoryCheckStatus(c.Query("status")),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+		Deleted:   c.Query("deleted") == "1",
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	})
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -159,60 +72,17 @@ func (h *InventoryCheckHandler) GetInventoryCheckList(c *gin.Context) {
 
 // GetInventoryCheck 获取盘点单详情
 func (h *InventoryCheckHandler) GetInventoryCheck(c *gin.Context) {
-	id := c.Param("id")
-	var check InventoryCheck
-	if err := h.db.First(&check, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "盘点单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	check, items, err := h.check.Get(id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
 		return
 	}
 
-	// 获取明细
-	var items []InventoryCheckItem
-	h.db.Where("check_id = ?", id).Find(&items)
-
-	// 获取产品信息
-	productIDs := make([]int64, 0)
-	for _, item := range items {
-		productIDs = append(productIDs, item.ProductID)
-	}
-
-	productMap := make(map[int64]Product)
-	if len(productIDs) > 0 {
-		var products []Product
-		h.db.Where("id IN ?", productIDs).Find(&products)
-		for _, p := range products {
-			productMap[p.ID] = p
-		}
-	}
-
-	for i := range items {
-		if p, ok := productMap[items[i].ProductID]; ok {
-			items[i].ProductName = p.Name
-			items[i].ProductCode = p.SkuCode
-		}
-	}
-
-	// 获取操作员信息
-	if check.CheckerID != nil {
-		var user struct {
-			RealName string `gorm:"column:real_name"`
-		}
-		h.db.Table("sys_user").Where("id = ?", *check.CheckerID).First(&user)
-		check.OperatorID = *check.CheckerID
-		check.OperatorName = user.RealName
-	}
-
-	// 状态转换
-	dbStatus := check.Status
-	switch dbStatus {
-	case "CHECKING":
-		check.Status = "checking"
-	case "FINISHED":
-		check.Status = "completed"
-	case "CANCELLED":
-		check.Status = "cancelled"
-	default:
-		check.Status = "draft"
+	// 盲盘：无监盘权限的用户看不到账面数量和盈亏
+	var itemsData interface{} = items
+	if !hasPermission(c, h.db, "INVENTORY_SUPERVISE") {
+		itemsData = blindCheckItems(items)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -226,22 +96,28 @@ func (h *InventoryCheckHandler) GetInventoryCheck(c *gin.Context) {
 			"operatorName":  check.OperatorName,
 			"status":        check.Status,
 			"checkDate":     check.CheckDate,
+			"freeze":        check.Freeze,
+			"snapshotTime":  check.SnapshotAt,
+			"round":         check.Round,
+			"tolerance":     check.Tolerance,
 			"remark":        check.Remark,
 			"createTime":    check.CreatedAt,
-			"items":         items,
+			"items":         itemsData,
 		},
 	})
 }
 
 // CreateInventoryCheck 创建盘点单
+// 账面数量由服务端在盘点开始时从当前库存快照，不再信任请求中的 systemQuantity
 func (h *InventoryCheckHandler) CreateInventoryCheck(c *gin.Context) {
 	var req struct {
-		Remark string `json:"remark"`
-		Items  []struct {
-			ProductID      int64   `json:"productId"`
-			SystemQuantity float64 `json:"systemQuantity"`
-			ActualQuantity float64 `json:"actualQuantity"`
-			Remark         string  `json:"remark"`
+		Remark    string   `json:"remark"`
+		Freeze    bool     `json:"freeze"`
+		Tolerance *float64 `json:"tolerance"`
+		Items     []struct {
+			ProductID      int64    `json:"productId"`
+			ActualQuantity *float64 `json:"actualQuantity"`
+			Remark         string   `json:"remark"`
 		} `json:"items"`
 	}
 
@@ -250,141 +126,187 @@ func (h *InventoryCheckHandler) CreateInventoryCheck(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID")
-	userIDInt := userID.(int64)
-
-	checkNo := fmt.Sprintf("CHK%s%03d", time.Now().Format("20060102150405"), time.Now().Nanosecond()%1000)
+	userID := c.GetInt64("userID")
+	items := make([]service.InventoryCheckItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, service.InventoryCheckItemInput{ProductID: item.ProductID, ActualQty: item.ActualQuantity})
+	}
 
-	check := InventoryCheck{
-		CheckNo:   checkNo,
-		CheckerID: &userIDInt,
-		Status:    "CHECKING",
+	check, err := h.check.Create(service.InventoryCheckInput{
+		CheckerID: &userID,
+		Freeze:    req.Freeze,
+		Tolerance: req.Tolerance,
 		Remark:    req.Remark,
+		Items:     items,
+	})
+	if err != nil {
+		serviceError(c, err, "创建失败")
+		return
 	}
+	recordAudit(c, h.db, auditCreate, "inventory_check", check.ID, nil, h.snapshot(check.ID))
 
-	tx := h.db.Begin()
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": check})
+}
 
-	if err := tx.Create(&check).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+// GenerateInventoryCheck 按分类、库位区间或ABC分类生成盘点单
+func (h *InventoryCheckHandler) GenerateInventoryCheck(c *gin.Context) {
+	var req struct {
+		CategoryID   int64    `json:"categoryId"`
+		LocationFrom string   `json:"locationFrom"`
+		LocationTo   string   `json:"locationTo"`
+		AbcClass     string   `json:"abcClass"`
+		Freeze       bool     `json:"freeze"`
+		Tolerance    *float64 `json:"tolerance"`
+		Remark       string   `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
 		return
 	}
 
-	for _, item := range req.Items {
-		differenceQty := item.ActualQuantity - item.SystemQuantity
-
-		checkItem := InventoryCheckItem{
-			CheckID:   check.ID,
-			ProductID: item.ProductID,
-			BookQty:   item.SystemQuantity,
-			ActualQty: item.ActualQuantity,
-			DiffQty:   differenceQty,
-		}
-		if err := tx.Create(&checkItem).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建明细失败"})
-			return
-		}
+	userID := c.GetInt64("userID")
+	check, itemCount, err := h.check.Generate(repository.ProductMatch{
+		CategoryID:   req.CategoryID,
+		AbcClass:     req.AbcClass,
+		LocationFrom: req.LocationFrom,
+		LocationTo:   req.LocationTo,
+	}, service.InventoryCheckInput{
+		CheckerID: &userID,
+		Freeze:    req.Freeze,
+		Tolerance: req.Tolerance,
+		Remark:    req.Remark,
+	})
+	if err != nil {
+		serviceError(c, err, "创建失败")
+		return
 	}
+	recordAudit(c, h.db, auditCreate, "inventory_check", check.ID, nil, h.snapshot(check.ID))
 
-	tx.Commit()
-	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": check})
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data": gin.H{
+			"id":        check.ID,
+			"checkNo":   check.CheckNo,
+			"itemCount": itemCount,
+		},
+	})
 }
 
 // UpdateInventoryCheck 更新盘点单
 func (h *InventoryCheckHandler) UpdateInventoryCheck(c *gin.Context) {
-	id := c.Param("id")
-	var check InventoryCheck
-	if err := h.db.First(&check, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "盘点单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if _, err := h.check.Find(id); err != nil {
+		serviceError(c, err, "查询失败")
 		return
 	}
 
 	var req struct {
 		Status string `json:"status"`
 		Remark string `json:"remark"`
+		Items  []struct {
+			ProductID      int64   `json:"productId"`
+			ActualQuantity float64 `json:"actualQuantity"`
+		} `json:"items"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
 		return
 	}
 
-	// 状态转换
-	dbStatus := check.Status
-	switch req.Status {
-	case "draft", "checking":
-		dbStatus = "CHECKING"
-	case "completed":
-		dbStatus = "FINISHED"
-	case "cancelled":
-		dbStatus = "CANCELLED"
+	counts := make([]service.InventoryCountInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		counts = append(counts, service.InventoryCountInput{ProductID: item.ProductID, Quantity: item.ActualQuantity})
 	}
 
-	tx := h.db.Begin()
-
-	// 如果状态变为已完成，需要更新库存
-	if dbStatus == "FINISHED" && check.Status == "CHECKING" {
-		var items []InventoryCheckItem
-		h.db.Where("check_id = ?", id).Find(&items)
-
-		userID, _ := c.Get("userID")
-		userIDInt := userID.(int64)
-
-		for _, item := range items {
-			if item.DiffQty != 0 {
-				// 更新产品库存
-				tx.Model(&Product{}).Where("id = ?", item.ProductID).
-					Update("stock_qty", item.ActualQty)
-
-				// 记录库存流水
-				stockLog := StockLog{
-					ProductID:   item.ProductID,
-					Type:        "ADJUST",
-					ChangeQty:   item.DiffQty,
-					SnapshotQty: item.ActualQty,
-					RelatedNo:   check.CheckNo,
-					OperatorID:  &userIDInt,
-				}
-				tx.Create(&stockLog)
-			}
-		}
+	before := h.snapshot(id)
+	err := h.check.Update(id, c.GetInt64("userID"), service.InventoryCheckUpdate{
+		Status: service.InventoryCheckStatus(req.Status),
+		Remark: req.Remark,
+		Counts: counts,
+	})
+	if err != nil {
+		serviceError(c, err, "更新失败")
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "inventory_check", id, before, h.snapshot(id))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
 
-		now := time.Now()
-		tx.Model(&check).Updates(map[string]interface{}{
-			"status":     dbStatus,
-			"check_date": now,
-			"remark":     req.Remark,
-		})
-	} else {
-		tx.Model(&check).Updates(map[string]interface{}{
-			"status": dbStatus,
-			"remark": req.Remark,
-		})
+// RecountInventoryCheck 结束当前轮次，差异超出容差的明细进入下一轮复盘
+func (h *InventoryCheckHandler) RecountInventoryCheck(c *gin.Context) {
+	if !hasPermission(c, h.db, "INVENTORY_SUPERVISE") {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无监盘权限"})
+		return
 	}
 
-	tx.Commit()
-	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	before := h.snapshot(id)
+	round, recountCount, err := h.check.Recount(id)
+	if err != nil {
+		serviceError(c, err, "发起复盘失败")
+		return
+	}
+	if recountCount == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "message": "所有明细均在容差范围内，无需复盘"})
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "inventory_check", id, before, h.snapshot(id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "已发起复盘",
+		"data": gin.H{
+			"round":        round,
+			"recountCount": recountCount,
+		},
+	})
 }
 
-// DeleteInventoryCheck 删除盘点单
+// DeleteInventoryCheck 删除盘点单（软删除，明细和计数记录保留以便恢复）
 func (h *InventoryCheckHandler) DeleteInventoryCheck(c *gin.Context) {
-	id := c.Param("id")
-
-	var check InventoryCheck
-	if err := h.db.First(&check, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "盘点单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	before := h.snapshot(id)
+	if err := h.check.Delete(id); err != nil {
+		serviceError(c, err, "删除失败")
 		return
 	}
+	recordAudit(c, h.db, auditDelete, "inventory_check", id, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
 
-	if check.Status == "FINISHED" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已完成的盘点单不能删除"})
+// RestoreInventoryCheck 恢复已删除的盘点单
+func (h *InventoryCheckHandler) RestoreInventoryCheck(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.check.Restore(id); err != nil {
+		serviceError(c, err, "恢复失败")
 		return
 	}
+	recordAudit(c, h.db, auditRestore, "inventory_check", id, nil, h.snapshot(id))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
 
-	tx := h.db.Begin()
-	tx.Where("check_id = ?", id).Delete(&InventoryCheckItem{})
-	tx.Delete(&InventoryCheck{}, id)
-	tx.Commit()
+// blindCheckItems 去掉账面数量和盈亏，用于盲盘
+func blindCheckItems(items []InventoryCheckItem) []gin.H {
+	result := make([]gin.H, len(items))
+	for i, item := range items {
+		result[i] = gin.H{
+			"id":             item.ID,
+			"checkId":        item.CheckID,
+			"productId":      item.ProductID,
+			"productName":    item.ProductName,
+			"productCode":    item.ProductCode,
+			"actualQuantity": item.ActualQty,
+			"counted":        item.Counted,
+			"round":          item.Round,
+			"needRecount":    item.NeedRecount,
+		}
+	}
+	return result
+}
 
-	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+// snapshot 盘点单审计快照
+func (h *InventoryCheckHandler) snapshot(id int64) map[string]interface{} {
+	return auditDocument(h.db, &InventoryCheck{}, id, &[]InventoryCheckItem{}, "check_id")
 }
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+	"easywms/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// KitComponent 套件组成模型
+type KitComponent = model.KitComponent
+
+// KitAssembly 套件组装单模型
+type KitAssembly = model.KitAssembly
+
+// KitHandler 套件处理器
+type KitHandler struct {
+	db  *gorm.DB
+	kit *service.KitService
+}
+
+// NewKitHandler 创建套件处理器
+func NewKitHandler(db *gorm.DB, cfg *config.Config) *KitHandler {
+	store := repository.NewStore(db, sequence.New(cfg.Numbering))
+	return &KitHandler{db: db, kit: service.NewKitService(store)}
+}
+
+// GetKit 获取套件组成
+func (h *KitHandler) GetKit(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	kit, components, err := h.kit.Get(id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"kitId":      kit.ID,
+			"kitCode":    kit.SKUCode,
+			"kitName":    kit.Name,
+			"components": components,
+		},
+	})
+}
+
+// SaveKit 保存套件组成（整体替换）
+func (h *KitHandler) SaveKit(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	_, before, err := h.kit.Get(id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
+	}
+
+	var req struct {
+		Components []struct {
+			ProductID int64   `json:"productId"`
+			Quantity  float64 `json:"quantity"`
+		} `json:"components"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	inputs := make([]service.KitComponentInput, 0, len(req.Components))
+	for _, comp := range req.Components {
+		inputs = append(inputs, service.KitComponentInput{ProductID: comp.ProductID, Quantity: comp.Quantity})
+	}
+	after, err := h.kit.Save(id, inputs)
+	if err != nil {
+		serviceError(c, err, "保存失败")
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "product_kit", id, gin.H{"components": before}, gin.H{"components": after})
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "保存成功"})
+}
+
+// GetAssemblyList 获取组装单列表
+func (h *KitHandler) GetAssemblyList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	assemblies, total, err := h.kit.ListAssemblies(repository.AssemblyFilter{
+		OrderNo: c.Query("orderNo"),
+		Status:  c.Query("status"),
+		Deleted: c.Query("deleted") == "1",
+		Offset:  (page - 1) * pageSize,
+		Limit:   pageSize,
+	})
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"items": assemblies,
+			"total": total,
+		},
+	})
+}
+
+// GetAssembly 获取组装单详情
+func (h *KitHandler) GetAssembly(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	assembly, components, err := h.kit.GetAssembly(id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"assembly":   assembly,
+			"components": components,
+		},
+	})
+}
+
+// CreateAssembly 创建组装单
+func (h *KitHandler) CreateAssembly(c *gin.Context) {
+	var req struct {
+		KitID    int64   `json:"kitId"`
+		Quantity float64 `json:"quantity"`
+		Remark   string  `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	assembly, err := h.kit.CreateAssembly(c.GetInt64("userID"), service.AssemblyInput{
+		KitID:    req.KitID,
+		Quantity: req.Quantity,
+		Remark:   req.Remark,
+	})
+	if err != nil {
+		serviceError(c, err, "创建失败")
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "kit_assembly", assembly.ID, nil, h.snapshot(assembly.ID))
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": assembly})
+}
+
+// UpdateAssembly 更新组装单，状态变为 completed 时消耗组件并产出套件库存
+func (h *KitHandler) UpdateAssembly(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req struct {
+		Status string `json:"status"`
+		Remark string `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	before := h.snapshot(id)
+	err := h.kit.UpdateAssembly(id, c.GetInt64("userID"), service.AssemblyUpdate{Status: req.Status, Remark: req.Remark})
+	if err != nil {
+		serviceError(c, err, "更新失败")
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "kit_assembly", id, before, h.snapshot(id))
+
+	message := "更新成功"
+	if req.Status == "completed" {
+		message = "组装完成"
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": message})
+}
+
+// DeleteAssembly 删除组装单（软删除）
+func (h *KitHandler) DeleteAssembly(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	before := h.snapshot(id)
+	if err := h.kit.DeleteAssembly(id); err != nil {
+		serviceError(c, err, "删除失败")
+		return
+	}
+	recordAudit(c, h.db, auditDelete, "kit_assembly", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// RestoreAssembly 恢复已删除的组装单
+func (h *KitHandler) RestoreAssembly(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.kit.RestoreAssembly(id); err != nil {
+		serviceError(c, err, "恢复失败")
+		return
+	}
+	recordAudit(c, h.db, auditRestore, "kit_assembly", id, nil, h.snapshot(id))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
+
+// snapshot 组装单审计快照，组装单没有明细，只记录表头字段
+func (h *KitHandler) snapshot(id int64) map[string]interface{} {
+	var assembly KitAssembly
+	if err := h.db.First(&assembly, id).Error; err != nil {
+		return nil
+	}
+	return auditSnapshot(assembly)
+}
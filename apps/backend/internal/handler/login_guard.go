@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 登录锁定对象类型
+const (
+	lockSubjectUsername = "USERNAME"
+	lockSubjectIP       = "IP"
+)
+
+// LoginLock 登录失败计数与锁定模型
+type LoginLock struct {
+	ID           int64      `json:"id" gorm:"column:id;primaryKey"`
+	SubjectType  string     `json:"subjectType" gorm:"column:subject_type"`
+	Subject      string     `json:"subject" gorm:"column:subject"`
+	Failures     int        `json:"failures" gorm:"column:failures"`
+	LockCount    int        `json:"lockCount" gorm:"column:lock_count"`
+	LockedUntil  *time.Time `json:"lockedUntil" gorm:"column:locked_until"`
+	LastFailedAt *time.Time `json:"lastFailedTime" gorm:"column:last_failed_at"`
+	UpdatedAt    time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (LoginLock) TableName() string {
+	return "sys_login_lock"
+}
+
+// LoginLog 登录日志模型
+type LoginLog struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID    *int64    `json:"userId" gorm:"column:user_id"`
+	Username  string    `json:"username" gorm:"column:username"`
+	IP        string    `json:"ip" gorm:"column:ip"`
+	UserAgent string    `json:"userAgent" gorm:"column:user_agent"`
+	Success   int       `json:"success" gorm:"column:success"`
+	Message   string    `json:"message" gorm:"column:message"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (LoginLog) TableName() string {
+	return "sys_login_log"
+}
+
+// loginGuard 登录防暴力破解：按账号和IP分别统计失败次数，超限后指数退避锁定
+type loginGuard struct {
+	db  *gorm.DB
+	cfg config.LoginConfig
+}
+
+// newLoginGuard 创建登录保护，未配置时使用默认值
+func newLoginGuard(db *gorm.DB, cfg config.LoginConfig) *loginGuard {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 5
+	}
+	if cfg.MaxIPFailures <= 0 {
+		cfg.MaxIPFailures = 20
+	}
+	if cfg.WindowMinutes <= 0 {
+		cfg.WindowMinutes = 15
+	}
+	if cfg.LockMinutes <= 0 {
+		cfg.LockMinutes = 5
+	}
+	if cfg.MaxLockMinutes <= 0 {
+		cfg.MaxLockMinutes = 120
+	}
+	return &loginGuard{db: db, cfg: cfg}
+}
+
+// lockedUntil 返回账号或IP的锁定截止时间，未锁定返回nil
+func (g *loginGuard) lockedUntil(username, ip string) *time.Time {
+	var locks []LoginLock
+	g.db.Where("(subject_type = ? AND subject = ?) OR (subject_type = ? AND subject = ?)",
+		lockSubjectUsername, username, lockSubjectIP, ip).
+		Where("locked_until > ?", time.Now()).
+		Find(&locks)
+
+	var until *time.Time
+	for i := range locks {
+		if until == nil || locks[i].LockedUntil.After(*until) {
+			until = locks[i].LockedUntil
+		}
+	}
+	return until
+}
+
+// recordFailure 记录一次登录失败
+func (g *loginGuard) recordFailure(username, ip string) {
+	g.fail(lockSubjectUsername, username)
+	g.fail(lockSubjectIP, ip)
+}
+
+// recordSuccess 登录成功后清零该账号的失败计数。来源IP的计数不清零，
+// 否则攻击者可以用自己的账号穿插登录成功，绕过对同一IP的锁定
+func (g *loginGuard) recordSuccess(username string) {
+	g.db.Model(&LoginLock{}).
+		Where("subject_type = ? AND subject = ?", lockSubjectUsername, username).
+		Updates(map[string]interface{}{
+			"failures":     0,
+			"lock_count":   0,
+			"locked_until": nil,
+		})
+}
+
+// fail 累加失败次数，达到阈值时锁定，锁定时长按锁定次数翻倍。
+// 计数在事务中锁定记录后读改写，并发失败请求不会丢失计数或重复锁定
+func (g *loginGuard) fail(subjectType, subject string) {
+	if subject == "" {
+		return
+	}
+	// 同一出口IP可能有多人登录，IP的阈值单独配置且高于账号
+	maxFailures := g.cfg.MaxFailures
+	if subjectType == lockSubjectIP {
+		maxFailures = g.cfg.MaxIPFailures
+	}
+	now := time.Now()
+
+	g.db.Transaction(func(tx *gorm.DB) error {
+		// 首次失败时创建计数记录，并发创建由唯一索引去重
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LoginLock{SubjectType: subjectType, Subject: subject}).Error; err != nil {
+			return err
+		}
+
+		var lock LoginLock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("subject_type = ? AND subject = ?", subjectType, subject).First(&lock).Error; err != nil {
+			return err
+		}
+
+		// 超出统计窗口的失败次数重新计算
+		failures := lock.Failures + 1
+		if lock.LastFailedAt != nil && now.Sub(*lock.LastFailedAt) > time.Duration(g.cfg.WindowMinutes)*time.Minute {
+			failures = 1
+		}
+		updates := map[string]interface{}{
+			"failures":       failures,
+			"last_failed_at": now,
+		}
+
+		if failures >= maxFailures {
+			minutes := float64(g.cfg.LockMinutes) * math.Pow(2, float64(lock.LockCount))
+			if minutes > float64(g.cfg.MaxLockMinutes) {
+				minutes = float64(g.cfg.MaxLockMinutes)
+			}
+			updates["failures"] = 0
+			updates["lock_count"] = lock.LockCount + 1
+			updates["locked_until"] = now.Add(time.Duration(minutes) * time.Minute)
+		}
+		return tx.Model(&LoginLock{}).Where("id = ?", lock.ID).Updates(updates).Error
+	})
+}
+
+// audit 写入登录日志
+func (g *loginGuard) audit(c *gin.Context, userID *int64, username string, success bool, message string) {
+	entry := LoginLog{
+		UserID:    userID,
+		Username:  username,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Message:   message,
+	}
+	if success {
+		entry.Success = 1
+	}
+	g.db.Create(&entry)
+}
+
+// lockedMessage 锁定提示信息
+func lockedMessage(until time.Time) string {
+	minutes := int(math.Ceil(time.Until(until).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("登录失败次数过多，请%d分钟后再试", minutes)
+}
+
+// GetLoginLocks 获取当前被锁定的账号和IP
+func (h *UserHandler) GetLoginLocks(c *gin.Context) {
+	var locks []LoginLock
+	h.db.Where("locked_until > ?", time.Now()).Order("locked_until DESC").Find(&locks)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": locks})
+}
+
+// UnlockLogin 解除指定的登录锁定
+func (h *UserHandler) UnlockLogin(c *gin.Context) {
+	id := c.Param("id")
+	var lock LoginLock
+	if err := h.db.First(&lock, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "锁定记录不存在"})
+		return
+	}
+
+	before := lock
+	h.db.Model(&lock).Updates(map[string]interface{}{
+		"failures":     0,
+		"lock_count":   0,
+		"locked_until": nil,
+	})
+	h.db.First(&lock, lock.ID)
+	recordAudit(c, h.db, auditUpdate, "login_lock", lock.ID, before, lock)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已解锁"})
+}
+
+// UnlockUser 解除用户账号的登录锁定
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	h.db.Model(&LoginLock{}).
+		Where("subject_type = ? AND subject = ?", lockSubjectUsername, user.Username).
+		Updates(map[string]interface{}{
+			"failures":     0,
+			"lock_count":   0,
+			"locked_until": nil,
+		})
+	recordAudit(c, h.db, auditUpdate, "login_lock", user.ID, nil, gin.H{"username": user.Username, "unlocked": true})
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已解锁"})
+}
+
+// GetLoginLogs 获取登录日志
+func (h *UserHandler) GetLoginLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	username := c.Query("username")
+	ip := c.Query("ip")
+	success := c.Query("success")
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	query := h.db.Model(&LoginLog{})
+
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if success != "" {
+		query = query.Where("success = ?", success)
+	}
+	if startDate != "" {
+		query = query.Where("created_at >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("created_at <= ?", endDate+" 23:59:59")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var logs []LoginLog
+	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&logs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"items": logs,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Menu 菜单模型
+type Menu struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey"`
+	ParentID   int64     `json:"parentId" gorm:"column:parent_id"`
+	Name       string    `json:"name" gorm:"column:name"`
+	Path       string    `json:"path" gorm:"column:path"`
+	Component  string    `json:"component" gorm:"column:component"`
+	Title      string    `json:"title" gorm:"column:title"`
+	Icon       string    `json:"icon" gorm:"column:icon"`
+	Sort       int       `json:"order" gorm:"column:sort_order"`
+	AffixTab   int       `json:"affixTab" gorm:"column:affix_tab"`
+	Permission string    `json:"permission" gorm:"column:permission_code"` // 为空表示所有登录用户可见
+	Status     int       `json:"status" gorm:"column:status"`
+	CreatedAt  time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Menu) TableName() string {
+	return "sys_menu"
+}
+
+// MenuItem 菜单项
+type MenuItem struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Component string     `json:"component,omitempty"`
+	Meta      MenuMeta   `json:"meta"`
+	Children  []MenuItem `json:"children,omitempty"`
+}
+
+// MenuMeta 菜单元数据
+type MenuMeta struct {
+	Title     string   `json:"title"`
+	Icon      string   `json:"icon,omitempty"`
+	Order     int      `json:"order,omitempty"`
+	Authority []string `json:"authority,omitempty"`
+	AffixTab  bool     `json:"affixTab,omitempty"`
+}
+
+// MenuHandler 菜单处理器
+type MenuHandler struct {
+	db *gorm.DB
+}
+
+// NewMenuHandler 创建菜单处理器
+func NewMenuHandler(db *gorm.DB) *MenuHandler {
+	return &MenuHandler{db: db}
+}
+
+// GetMenus 获取当前用户可见的菜单
+func (h *MenuHandler) GetMenus(c *gin.Context) {
+	roleCode, exists := currentRoleCode(c, h.db)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未授权",
+		})
+		return
+	}
+
+	granted := make(map[string]bool)
+	for _, p := range loadPermissions(h.db, roleCode) {
+		granted[p] = true
+	}
+
+	var menus []Menu
+	h.db.Where("status = ?", 1).Order("sort_order ASC, id ASC").Find(&menus)
+
+	children := make(map[int64][]Menu)
+	for _, m := range menus {
+		children[m.ParentID] = append(children[m.ParentID], m)
+	}
+
+	var build func(parentID int64) []MenuItem
+	build = func(parentID int64) []MenuItem {
+		items := make([]MenuItem, 0)
+		for _, m := range children[parentID] {
+			if m.Permission != "" && !granted[m.Permission] {
+				continue
+			}
+			item := MenuItem{
+				Name:      m.Name,
+				Path:      m.Path,
+				Component: m.Component,
+				Meta: MenuMeta{
+					Title:    m.Title,
+					Icon:     m.Icon,
+					Order:    m.Sort,
+					AffixTab: m.AffixTab == 1,
+				},
+				Children: build(m.ID),
+			}
+			// 目录下的页面全部不可见时隐藏目录
+			if m.Component == "" && len(children[m.ID]) > 0 && len(item.Children) == 0 {
+				continue
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": build(0),
+	})
+}
+
+// GetMenuTree 获取完整菜单树（含停用菜单），用于菜单管理
+func (h *MenuHandler) GetMenuTree(c *gin.Context) {
+	var menus []Menu
+	h.db.Order("sort_order ASC, id ASC").Find(&menus)
+
+	type TreeNode struct {
+		Menu
+		Children []*TreeNode `json:"children,omitempty"`
+	}
+
+	nodeMap := make(map[int64]*TreeNode)
+	var roots []*TreeNode
+
+	for _, m := range menus {
+		nodeMap[m.ID] = &TreeNode{Menu: m, Children: []*TreeNode{}}
+	}
+
+	for _, m := range menus {
+		node := nodeMap[m.ID]
+		if m.ParentID == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodeMap[m.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": roots})
+}
+
+// menuRequest 菜单新增/修改请求
+type menuRequest struct {
+	ParentID   int64  `json:"parentId"`
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Component  string `json:"component"`
+	Title      string `json:"title"`
+	Icon       string `json:"icon"`
+	Order      int    `json:"order"`
+	AffixTab   bool   `json:"affixTab"`
+	Permission string `json:"permission"`
+	Status     *int   `json:"status"`
+}
+
+// CreateMenu 创建菜单
+func (h *MenuHandler) CreateMenu(c *gin.Context) {
+	var req menuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if msg := h.validateMenu(0, req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	menu := Menu{
+		ParentID:   req.ParentID,
+		Name:       req.Name,
+		Path:       req.Path,
+		Component:  req.Component,
+		Title:      req.Title,
+		Icon:       req.Icon,
+		Sort:       req.Order,
+		Permission: req.Permission,
+		Status:     1,
+	}
+	if req.AffixTab {
+		menu.AffixTab = 1
+	}
+	if req.Status != nil {
+		menu.Status = *req.Status
+	}
+
+	if err := h.db.Create(&menu).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "menu", menu.ID, nil, menu)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": menu})
+}
+
+// UpdateMenu 更新菜单
+func (h *MenuHandler) UpdateMenu(c *gin.Context) {
+	id := c.Param("id")
+	var menu Menu
+	if err := h.db.First(&menu, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "菜单不存在"})
+		return
+	}
+
+	var req menuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if msg := h.validateMenu(menu.ID, req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	affixTab := 0
+	if req.AffixTab {
+		affixTab = 1
+	}
+	updates := map[string]interface{}{
+		"parent_id":       req.ParentID,
+		"name":            req.Name,
+		"path":            req.Path,
+		"component":       req.Component,
+		"title":           req.Title,
+		"icon":            req.Icon,
+		"sort_order":      req.Order,
+		"affix_tab":       affixTab,
+		"permission_code": req.Permission,
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	before := menu
+	h.db.Model(&menu).Updates(updates)
+	h.db.First(&menu, menu.ID)
+	recordAudit(c, h.db, auditUpdate, "menu", menu.ID, before, menu)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
+
+// DeleteMenu 删除菜单
+func (h *MenuHandler) DeleteMenu(c *gin.Context) {
+	id := c.Param("id")
+	var menu Menu
+	if err := h.db.First(&menu, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "菜单不存在"})
+		return
+	}
+
+	var count int64
+	h.db.Model(&Menu{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "存在子菜单，无法删除"})
+		return
+	}
+
+	if err := h.db.Delete(&menu).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败"})
+		return
+	}
+	recordAudit(c, h.db, auditDelete, "menu", menu.ID, menu, nil)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// SortMenus 批量调整菜单层级和排序
+func (h *MenuHandler) SortMenus(c *gin.Context) {
+	var req struct {
+		Items []struct {
+			ID       int64 `json:"id"`
+			ParentID int64 `json:"parentId"`
+			Order    int   `json:"order"`
+		} `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	var menus []Menu
+	h.db.Select("id", "parent_id").Find(&menus)
+	parents := make(map[int64]int64, len(menus))
+	for _, m := range menus {
+		parents[m.ID] = m.ParentID
+	}
+
+	// 先在内存中应用调整，再检查是否形成环
+	for _, item := range req.Items {
+		if _, ok := parents[item.ID]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "菜单不存在"})
+			return
+		}
+		if _, ok := parents[item.ParentID]; item.ParentID != 0 && !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "上级菜单不存在"})
+			return
+		}
+		parents[item.ID] = item.ParentID
+	}
+	if hasParentCycle(parents) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能将菜单移动到自身或其下级菜单下"})
+		return
+	}
+
+	ids := make([]int64, 0, len(req.Items))
+	for _, item := range req.Items {
+		ids = append(ids, item.ID)
+	}
+	var before []Menu
+	h.db.Where("id IN ?", ids).Find(&before)
+
+	tx := h.db.Begin()
+	for _, item := range req.Items {
+		if err := tx.Model(&Menu{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"parent_id":  item.ParentID,
+			"sort_order": item.Order,
+		}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存失败"})
+			return
+		}
+	}
+	tx.Commit()
+
+	for _, old := range before {
+		var menu Menu
+		h.db.First(&menu, old.ID)
+		if menu.ParentID != old.ParentID || menu.Sort != old.Sort {
+			recordAudit(c, h.db, auditUpdate, "menu", menu.ID, old, menu)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "保存成功"})
+}
+
+// validateMenu 校验菜单参数，返回错误信息
+func (h *MenuHandler) validateMenu(id int64, req menuRequest) string {
+	if req.Name == "" || req.Path == "" || req.Title == "" {
+		return "菜单名称、路径和标题不能为空"
+	}
+
+	var count int64
+	h.db.Model(&Menu{}).Where("name = ? AND id <> ?", req.Name, id).Count(&count)
+	if count > 0 {
+		return "菜单名称已存在: " + req.Name
+	}
+
+	if req.Permission != "" {
+		h.db.Model(&Permission{}).Where("code = ?", req.Permission).Count(&count)
+		if count == 0 {
+			return "权限码不存在: " + req.Permission
+		}
+	}
+
+	if req.ParentID != 0 {
+		var menus []Menu
+		h.db.Select("id", "parent_id").Find(&menus)
+		parents := make(map[int64]int64, len(menus))
+		for _, m := range menus {
+			parents[m.ID] = m.ParentID
+		}
+		if _, ok := parents[req.ParentID]; !ok {
+			return "上级菜单不存在"
+		}
+		if id != 0 {
+			parents[id] = req.ParentID
+			if hasParentCycle(parents) {
+				return "不能将菜单移动到自身或其下级菜单下"
+			}
+		}
+	}
+	return ""
+}
+
+// hasParentCycle 检查父子关系中是否存在环
+func hasParentCycle(parents map[int64]int64) bool {
+	for id := range parents {
+		visited := map[int64]bool{id: true}
+		for p := parents[id]; p != 0; p = parents[p] {
+			if visited[p] {
+				return true
+			}
+			visited[p] = true
+		}
+	}
+	return false
+}
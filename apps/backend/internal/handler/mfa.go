@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// mfaTokenTTL 密码验证通过后等待输入验证码的有效期
+const mfaTokenTTL = 5 * time.Minute
+
+// UserMFA 用户两步验证模型
+type UserMFA struct {
+	ID          int64      `json:"id" gorm:"column:id;primaryKey"`
+	UserID      int64      `json:"userId" gorm:"column:user_id"`
+	Secret      string     `json:"-" gorm:"column:secret"`
+	Enabled     int        `json:"enabled" gorm:"column:enabled"`
+	ConfirmedAt *time.Time `json:"confirmTime" gorm:"column:confirmed_at"`
+	CreatedAt   time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserMFA) TableName() string {
+	return "sys_user_mfa"
+}
+
+// RecoveryCode 两步验证恢复码模型（只保存摘要）
+type RecoveryCode struct {
+	ID        int64      `json:"id" gorm:"column:id;primaryKey"`
+	UserID    int64      `json:"userId" gorm:"column:user_id"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash"`
+	UsedAt    *time.Time `json:"useTime" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "sys_user_recovery_code"
+}
+
+// GetMFAStatus 获取当前用户两步验证状态
+func (h *AuthHandler) GetMFAStatus(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	roleCode, _ := currentRoleCode(c, h.db)
+
+	_, enabled := mfaEnabled(h.db, userID.(int64))
+
+	var remaining int64
+	h.db.Model(&RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"enabled":            enabled,
+			"required":           mfaRequired(h.cfg, roleCode),
+			"recoveryCodesCount": remaining,
+		},
+	})
+}
+
+// SetupMFA 生成两步验证密钥，需调用 ConfirmMFA 验证后才会启用
+func (h *AuthHandler) SetupMFA(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var user model.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	if _, enabled := mfaEnabled(h.db, user.ID); enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已启用两步验证"})
+		return
+	}
+
+	issuer := h.cfg.MFA.Issuer
+	if issuer == "" {
+		issuer = "EasyWMS"
+	}
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: user.Username,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成密钥失败"})
+		return
+	}
+
+	// 未确认的密钥可重复生成，覆盖旧值
+	var mfa UserMFA
+	if err := h.db.Where("user_id = ?", user.ID).First(&mfa).Error; err != nil {
+		mfa = UserMFA{UserID: user.ID, Secret: key.Secret()}
+		err = h.db.Create(&mfa).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存密钥失败"})
+			return
+		}
+	} else {
+		h.db.Model(&mfa).Updates(map[string]interface{}{"secret": key.Secret(), "enabled": 0})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"secret":     key.Secret(),
+			"otpauthUri": key.URL(),
+		},
+	})
+}
+
+// ConfirmMFA 校验验证码并启用两步验证，返回恢复码（仅展示一次）
+func (h *AuthHandler) ConfirmMFA(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	var mfa UserMFA
+	if err := h.db.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请先生成两步验证密钥"})
+		return
+	}
+	if mfa.Enabled == 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已启用两步验证"})
+		return
+	}
+	if !totp.Validate(strings.TrimSpace(req.Code), mfa.Secret) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证码错误"})
+		return
+	}
+
+	before := mfa
+	now := time.Now()
+	var codes []string
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&mfa).Updates(map[string]interface{}{
+			"enabled":      1,
+			"confirmed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = generateRecoveryCodes(tx, mfa.UserID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "启用失败"})
+		return
+	}
+	h.db.First(&mfa, mfa.ID)
+	recordAudit(c, h.db, auditUpdate, "user_mfa", mfa.UserID, before, mfa)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "两步验证已启用", "data": gin.H{"recoveryCodes": codes}})
+}
+
+// DisableMFA 关闭两步验证，需同时提供密码和验证码
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	var user model.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+	if mfaRequired(h.cfg, user.RoleCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "当前角色必须启用两步验证"})
+		return
+	}
+
+	mfa, enabled := mfaEnabled(h.db, user.ID)
+	if !enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "未启用两步验证"})
+		return
+	}
+	if !utils.CheckPassword(req.Password, user.Password) || !verifyMFACode(h.db, mfa, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "密码或验证码错误"})
+		return
+	}
+
+	tx := h.db.Begin()
+	tx.Where("user_id = ?", user.ID).Delete(&RecoveryCode{})
+	tx.Delete(&mfa)
+	tx.Commit()
+	recordAudit(c, h.db, auditDelete, "user_mfa", user.ID, mfa, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "两步验证已关闭"})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	mfa, enabled := mfaEnabled(h.db, userID.(int64))
+	if !enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "未启用两步验证"})
+		return
+	}
+	if !totp.Validate(strings.TrimSpace(req.Code), mfa.Secret) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证码错误"})
+		return
+	}
+
+	var codes []string
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = generateRecoveryCodes(tx, mfa.UserID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成恢复码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"recoveryCodes": codes}})
+}
+
+// LoginMFA 两步登录第二步：校验验证码或恢复码后签发令牌
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req struct {
+		MFAToken string `json:"mfaToken" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误"})
+		return
+	}
+
+	claims, err := utils.ParseToken(req.MFAToken, mfaSigningKey(h.cfg))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "登录已超时，请重新输入密码"})
+		return
+	}
+
+	ip := c.ClientIP()
+	if until := h.guard.lockedUntil(claims.Username, ip); until != nil {
+		h.guard.audit(c, &claims.UserID, claims.Username, false, "账号或IP已锁定")
+		c.JSON(http.StatusTooManyRequests, gin.H{"code": 429, "message": lockedMessage(*until)})
+		return
+	}
+
+	var user model.User
+	if err := h.db.First(&user, claims.UserID).Error; err != nil || user.Status != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "账号不存在或已被禁用"})
+		return
+	}
+
+	mfa, enabled := mfaEnabled(h.db, user.ID)
+	if !enabled || !verifyMFACode(h.db, mfa, req.Code) {
+		h.guard.recordFailure(user.Username, ip)
+		h.guard.audit(c, &user.ID, user.Username, false, "两步验证失败")
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "验证码错误"})
+		return
+	}
+
+	h.guard.recordSuccess(user.Username)
+	h.guard.audit(c, &user.ID, user.Username, true, "登录成功（两步验证）")
+
+	tokens, err := issueTokens(c, h.db, h.cfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+		return
+	}
+	tokens.PasswordExpired = passwordExpired(h.cfg.Password, user)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": tokens})
+}
+
+// MFAEnforcement 强制两步验证中间件：必须启用的角色未完成绑定前只能访问绑定相关接口
+func MFAEnforcement(db *gorm.DB, cfg *config.Config) gin.HandlerFunc {
+	allowed := map[string]bool{
+		"/api/user/info":        true,
+		"/api/auth/codes":       true,
+		"/api/menu/all":         true,
+		"/api/auth/2fa/status":  true,
+		"/api/auth/2fa/setup":   true,
+		"/api/auth/2fa/confirm": true,
+		"/api/auth/password":    true,
+	}
+
+	return func(c *gin.Context) {
+		_, viaAPIKey := c.Get("apiKeyID")
+		if len(cfg.MFA.RequiredRoles) == 0 || allowed[c.FullPath()] || viaAPIKey {
+			c.Next()
+			return
+		}
+
+		roleCode, _ := currentRoleCode(c, db)
+		userID, exists := c.Get("userID")
+		if !exists || !mfaRequired(cfg, roleCode) {
+			c.Next()
+			return
+		}
+
+		if _, enabled := mfaEnabled(db, userID.(int64)); !enabled {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "请先启用两步验证",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// mfaEnabled 查询用户是否已启用两步验证
+func mfaEnabled(db *gorm.DB, userID int64) (UserMFA, bool) {
+	var mfa UserMFA
+	if err := db.Where("user_id = ? AND enabled = ?", userID, 1).First(&mfa).Error; err != nil {
+		return mfa, false
+	}
+	return mfa, true
+}
+
+// mfaRequired 判断角色是否被配置为必须启用两步验证
+func mfaRequired(cfg *config.Config, roleCode string) bool {
+	return containsString(cfg.MFA.RequiredRoles, roleCode)
+}
+
+// mfaSigningKey 两步登录临时令牌的签名密钥，与访问令牌区分，避免被当作访问令牌使用
+func mfaSigningKey(cfg *config.Config) string {
+	return cfg.JWT.Secret + ":mfa"
+}
+
+// verifyMFACode 校验TOTP验证码或未使用的恢复码，恢复码使用后作废
+func verifyMFACode(db *gorm.DB, mfa UserMFA, code string) bool {
+	code = strings.TrimSpace(code)
+	if totp.Validate(code, mfa.Secret) {
+		return true
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	result := db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", mfa.UserID, utils.HashToken(normalized)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// generateRecoveryCodes 重新生成恢复码，返回明文（格式 xxxxx-xxxxx）
+func generateRecoveryCodes(tx *gorm.DB, userID int64) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.RandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&RecoveryCode{UserID: userID, CodeHash: utils.HashToken(raw)}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
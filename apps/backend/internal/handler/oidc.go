@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"easywms/internal/auth"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// identityProviderOIDC 外部身份来源：OIDC
+const identityProviderOIDC = "OIDC"
+
+// oidcStateTTL 发起单点登录到回调的最长等待时间
+const oidcStateTTL = 10 * time.Minute
+
+// oidcStateCookie 保存 state 摘要的Cookie，回调时校验请求来自发起登录的同一浏览器
+const oidcStateCookie = "oidc_state"
+
+// oidcCookiePath 单点登录Cookie只在登录和回调接口上发送
+const oidcCookiePath = "/api/auth/oidc"
+
+// errAccountNotProvisioned 未开通账号且未启用自动创建
+var errAccountNotProvisioned = errors.New("account not provisioned")
+
+// OIDCState 单点登录请求状态（state、nonce 和 PKCE code_verifier）
+type OIDCState struct {
+	ID           int64     `json:"id" gorm:"column:id;primaryKey"`
+	State        string    `json:"state" gorm:"column:state"`
+	Nonce        string    `json:"-" gorm:"column:nonce"`
+	CodeVerifier string    `json:"-" gorm:"column:code_verifier"`
+	ExpiresAt    time.Time `json:"expireTime" gorm:"column:expires_at"`
+	CreatedAt    time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (OIDCState) TableName() string {
+	return "sys_oidc_state"
+}
+
+// UserIdentity 外部身份与系统用户的关联
+type UserIdentity struct {
+	ID          int64      `json:"id" gorm:"column:id;primaryKey"`
+	Provider    string     `json:"provider" gorm:"column:provider"`
+	Subject     string     `json:"subject" gorm:"column:subject"`
+	UserID      int64      `json:"userId" gorm:"column:user_id"`
+	Email       string     `json:"email" gorm:"column:email"`
+	LastLoginAt *time.Time `json:"lastLoginTime" gorm:"column:last_login_at"`
+	CreatedAt   time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "sys_user_identity"
+}
+
+// OIDCLogin 发起单点登录，跳转到身份提供商授权页
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	if h.sso == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "未启用单点登录"})
+		return
+	}
+
+	state, err := utils.RandomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成登录请求失败"})
+		return
+	}
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成登录请求失败"})
+		return
+	}
+	verifier := auth.GenerateCodeVerifier()
+
+	authURL, err := h.sso.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"code": 502, "message": "身份提供商不可用"})
+		return
+	}
+
+	// 顺带清理过期的登录请求
+	h.db.Where("expires_at < ?", time.Now()).Delete(&OIDCState{})
+	if err := h.db.Create(&OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成登录请求失败"})
+		return
+	}
+
+	// 身份提供商跳转回来属于跨站导航，需要 Lax 才会携带Cookie
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, utils.HashToken(state), int(oidcStateTTL.Seconds()), oidcCookiePath, "", secureCookie(h.cfg), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供商回调：校验授权码和ID令牌，映射到系统用户后签发令牌
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if h.sso == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "未启用单点登录"})
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "单点登录失败: " + errCode + " " + c.Query("error_description"),
+		})
+		return
+	}
+
+	// state 必须与发起登录的浏览器中保存的摘要一致，防止把他人的授权码注入当前浏览器
+	cookie, err := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", secureCookie(h.cfg), true)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(utils.HashToken(c.Query("state")))) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "登录请求无效或已过期，请重新登录"})
+		return
+	}
+
+	// state 只能使用一次
+	var state OIDCState
+	if err := h.db.Where("state = ?", c.Query("state")).First(&state).Error; err != nil ||
+		time.Now().After(state.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "登录请求无效或已过期，请重新登录"})
+		return
+	}
+	if result := h.db.Delete(&state); result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "登录请求无效或已过期，请重新登录"})
+		return
+	}
+
+	identity, err := h.sso.Exchange(c.Request.Context(), c.Query("code"), state.CodeVerifier, state.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "单点登录验证失败"})
+		return
+	}
+
+	user, msg := h.resolveOIDCUser(identity)
+	if msg != "" {
+		h.guard.audit(c, nil, identity.Email, false, "单点登录失败: "+msg)
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": msg})
+		return
+	}
+	if user.Status != 1 {
+		h.guard.audit(c, &user.ID, user.Username, false, "账号已被禁用")
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "账号已被禁用"})
+		return
+	}
+
+	// 单点登录不代替本系统的两步验证：已启用时同密码登录一样返回临时令牌，由前端跳转到验证码页面
+	if _, mfaOn := mfaEnabled(h.db, user.ID); mfaOn {
+		mfaToken, _, err := utils.GenerateAccessToken(user.ID, user.Username, user.RoleCode, mfaSigningKey(h.cfg), mfaTokenTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+			return
+		}
+		if redirect := h.cfg.OIDC.PostLoginRedirect; redirect != "" {
+			c.Redirect(http.StatusFound, withQuery(redirect, "mfaToken", mfaToken))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": LoginResponse{MFARequired: true, MFAToken: mfaToken}})
+		return
+	}
+
+	h.guard.audit(c, &user.ID, user.Username, true, "单点登录成功")
+
+	tokens, err := issueTokens(c, h.db, h.cfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+		return
+	}
+
+	// 前端通过刷新令牌Cookie换取访问令牌，令牌不出现在跳转地址中
+	if redirect := h.cfg.OIDC.PostLoginRedirect; redirect != "" {
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": tokens})
+}
+
+// withQuery 在跳转地址后追加查询参数；前端地址通常是 hash 路由，参数跟在 # 之后不会发送到服务器
+func withQuery(redirect, key, value string) string {
+	sep := "?"
+	if strings.Contains(redirect, "?") {
+		sep = "&"
+	}
+	return redirect + sep + key + "=" + url.QueryEscape(value)
+}
+
+// resolveOIDCUser 按 subject、已验证邮箱依次匹配系统用户，找不到时按配置自动创建，返回错误信息
+func (h *AuthHandler) resolveOIDCUser(identity *auth.OIDCIdentity) (model.User, string) {
+	var user model.User
+	now := time.Now()
+
+	var link UserIdentity
+	if err := h.db.Where("provider = ? AND subject = ?", identityProviderOIDC, identity.Subject).First(&link).Error; err == nil {
+		if err := h.db.First(&user, link.UserID).Error; err != nil {
+			return user, "关联的账号不存在"
+		}
+		h.db.Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": now})
+		return user, ""
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		found := false
+		if h.cfg.OIDC.MatchByEmail && identity.EmailVerified && identity.Email != "" {
+			found = tx.Where("email = ?", identity.Email).First(&user).Error == nil
+		}
+
+		if !found {
+			if !h.cfg.OIDC.AutoProvision {
+				return errAccountNotProvisioned
+			}
+			created, err := h.provisionOIDCUser(tx, identity, now)
+			if err != nil {
+				return err
+			}
+			user = created
+		}
+
+		return tx.Create(&UserIdentity{
+			Provider:    identityProviderOIDC,
+			Subject:     identity.Subject,
+			UserID:      user.ID,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err == errAccountNotProvisioned {
+		return user, "账号未开通，请联系管理员"
+	}
+	if err != nil {
+		return user, "创建账号失败"
+	}
+	return user, ""
+}
+
+// provisionOIDCUser 首次单点登录时自动创建账号，本地密码随机且不告知用户
+func (h *AuthHandler) provisionOIDCUser(tx *gorm.DB, identity *auth.OIDCIdentity, now time.Time) (model.User, error) {
+	base := identity.Username
+	if base == "" && identity.Email != "" {
+		base = strings.Split(identity.Email, "@")[0]
+	}
+	if base == "" {
+		base = "sso_" + identity.Subject
+	}
+	if len(base) > 60 {
+		base = base[:60]
+	}
+
+	username := base
+	for i := 2; ; i++ {
+		var count int64
+		tx.Model(&model.User{}).Where("username = ?", username).Count(&count)
+		if count == 0 {
+			break
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	hash, err := unusablePassword()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	roleCode := h.cfg.OIDC.DefaultRole
+	if roleCode == "" {
+		roleCode = "STAFF"
+	}
+	realName := identity.Name
+	if realName == "" {
+		realName = username
+	}
+
+	user := model.User{
+		Username:          username,
+		Password:          hash,
+		RealName:          realName,
+		Email:             identity.Email,
+		DeptID:            h.cfg.OIDC.DefaultDeptID,
+		RoleCode:          roleCode,
+		Status:            1,
+		PasswordChangedAt: &now,
+	}
+	return user, tx.Create(&user).Error
+}
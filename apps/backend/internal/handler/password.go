@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory 历史密码模型
+type PasswordHistory struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID    int64     `json:"userId" gorm:"column:user_id"`
+	Password  string    `json:"-" gorm:"column:password"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "sys_password_history"
+}
+
+// ChangePassword 修改当前用户密码，需验证原密码
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req struct {
+		OldPassword string `json:"oldPassword" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	var user model.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	if !utils.CheckPassword(req.OldPassword, user.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "原密码错误"})
+		return
+	}
+	if req.NewPassword == req.OldPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "新密码不能与原密码相同"})
+		return
+	}
+	if msg := validatePassword(h.cfg.Password, req.NewPassword); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+	if passwordReused(h.db, h.cfg.Password, user, req.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": fmt.Sprintf("不能使用最近%d次使用过的密码", h.cfg.Password.HistoryCount),
+		})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		return savePassword(tx, h.cfg.Password, user, req.NewPassword, true)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "修改密码失败"})
+		return
+	}
+	var after model.User
+	h.db.First(&after, user.ID)
+	recordAudit(c, h.db, auditUpdate, "user", user.ID, user, after)
+
+	// 其他会话全部失效，当前会话重新签发令牌
+	revokeUserTokens(h.db, user.ID, h.cfg.JWT.AccessTTL())
+	tokens, err := issueTokens(c, h.db, h.cfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "密码修改成功", "data": tokens})
+}
+
+// PasswordExpiry 密码过期中间件：密码过期或须修改时只能访问修改密码相关接口
+func PasswordExpiry(db *gorm.DB, cfg *config.Config) gin.HandlerFunc {
+	allowed := map[string]bool{
+		"/api/user/info":        true,
+		"/api/auth/codes":       true,
+		"/api/menu/all":         true,
+		"/api/auth/password":    true,
+		"/api/auth/2fa/status":  true,
+		"/api/auth/2fa/setup":   true,
+		"/api/auth/2fa/confirm": true,
+	}
+
+	return func(c *gin.Context) {
+		// API密钥调用不受密码过期限制
+		_, viaAPIKey := c.Get("apiKeyID")
+		if allowed[c.FullPath()] || viaAPIKey {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		var user model.User
+		if err := db.Select("id", "password_changed_at").First(&user, userID).Error; err == nil && passwordExpired(cfg.Password, user) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "密码已过期，请先修改密码",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// validatePassword 按密码策略校验密码强度，返回错误信息
+func validatePassword(policy config.PasswordConfig, password string) string {
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = minPasswordLength
+	}
+	if len([]rune(password)) < minLength {
+		return fmt.Sprintf("密码长度不能少于%d位", minLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if policy.RequireUpper && !upper {
+		missing = append(missing, "大写字母")
+	}
+	if policy.RequireLower && !lower {
+		missing = append(missing, "小写字母")
+	}
+	if policy.RequireDigit && !digit {
+		missing = append(missing, "数字")
+	}
+	if policy.RequireSymbol && !symbol {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return "密码必须包含" + strings.Join(missing, "、")
+	}
+	return ""
+}
+
+// passwordReused 检查新密码是否与最近N次使用过的密码（含当前密码）相同
+func passwordReused(db *gorm.DB, policy config.PasswordConfig, user model.User, password string) bool {
+	if policy.HistoryCount <= 0 {
+		return false
+	}
+	if utils.CheckPassword(password, user.Password) {
+		return true
+	}
+
+	if policy.HistoryCount == 1 {
+		return false
+	}
+
+	var history []PasswordHistory
+	db.Where("user_id = ?", user.ID).Order("id DESC").Limit(policy.HistoryCount - 1).Find(&history)
+	for _, item := range history {
+		if utils.CheckPassword(password, item.Password) {
+			return true
+		}
+	}
+	return false
+}
+
+// savePassword 保存新密码，旧密码写入历史并按策略清理；byUser 为 false 表示管理员设置
+func savePassword(tx *gorm.DB, policy config.PasswordConfig, user model.User, password string, byUser bool) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	var changedAt interface{} = time.Now()
+	if !byUser && policy.ForceChangeOnReset {
+		changedAt = nil
+	}
+
+	if user.Password != "" && policy.HistoryCount > 1 {
+		if err := tx.Create(&PasswordHistory{UserID: user.ID, Password: user.Password}).Error; err != nil {
+			return err
+		}
+
+		// 历史表加上当前密码共保留最近N个
+		var keep []int64
+		tx.Model(&PasswordHistory{}).Where("user_id = ?", user.ID).
+			Order("id DESC").Limit(policy.HistoryCount-1).Pluck("id", &keep)
+		if err := tx.Where("user_id = ? AND id NOT IN ?", user.ID, keep).Delete(&PasswordHistory{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password":            hash,
+		"password_changed_at": changedAt,
+	}).Error
+}
+
+// passwordExpired 判断密码是否已过期或须修改
+func passwordExpired(policy config.PasswordConfig, user model.User) bool {
+	if user.PasswordChangedAt == nil {
+		return policy.ForceChangeOnReset
+	}
+	if policy.ExpireDays <= 0 {
+		return false
+	}
+	return time.Since(*user.PasswordChangedAt) > time.Duration(policy.ExpireDays)*24*time.Hour
+}
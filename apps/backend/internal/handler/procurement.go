@@ -1,72 +1,42 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+	"easywms/internal/service"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // Procurement 采购单模型
-type Procurement struct {
-	ID           int64      `json:"id" gorm:"column:id;primaryKey"`
-	OrderNo      string     `json:"orderNo" gorm:"column:order_no"`
-	ApplicantID  int64      `json:"applicantId" gorm:"column:applicant_id"`
-	SupplierID   *int64     `json:"supplierId" gorm:"column:supplier_id"`
-	Status       string     `json:"status" gorm:"column:status"`
-	Reason       string     `json:"reason" gorm:"column:reason"`
-	ExpectedDate *time.Time `json:"expectedDate" gorm:"column:expected_date"`
-	CreatedAt    time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time  `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
-	TotalAmount  float64    `json:"totalAmount" gorm:"-"`
-	// 关联字段
-	ApplicantName string `json:"applicantName" gorm:"-"`
-	SupplierName  string `json:"supplierName" gorm:"-"`
-}
-
-func (Procurement) TableName() string {
-	return "biz_procurement"
-}
+type Procurement = model.Procurement
 
 // ProcurementItem 采购明细模型
-type ProcurementItem struct {
-	ID            int64     `json:"id" gorm:"column:id;primaryKey"`
-	ProcurementID int64     `json:"procurementId" gorm:"column:procurement_id"`
-	ProductID     int64     `json:"productId" gorm:"column:product_id"`
-	PlanQty       float64   `json:"quantity" gorm:"column:plan_qty"`
-	UnitPrice     *float64  `json:"price" gorm:"column:unit_price"`
-	Amount        float64   `json:"amount" gorm:"-"`
-	CreatedAt     time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	// 关联字段
-	ProductName string `json:"productName" gorm:"-"`
-	ProductCode string `json:"productCode" gorm:"-"`
-}
-
-func (ProcurementItem) TableName() string {
-	return "biz_procurement_item"
-}
+type ProcurementItem = model.ProcurementItem
 
 // ProcurementHandler 采购处理器
 type ProcurementHandler struct {
-	db *gorm.DB
+	db          *gorm.DB
+	procurement *service.ProcurementService
 }
 
 // NewProcurementHandler 创建采购处理器
-func NewProcurementHandler(db *gorm.DB) *ProcurementHandler {
-	return &ProcurementHandler{db: db}
+func NewProcurementHandler(db *gorm.DB, cfg *config.Config) *ProcurementHandler {
+	store := repository.NewStore(db, sequence.New(cfg.Numbering))
+	return &ProcurementHandler{db: db, procurement: service.NewProcurementService(store)}
 }
 
 // GetProcurementList 获取采购单列表
 func (h *ProcurementHandler) GetProcurementList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
-	orderNo := c.Query("orderNo")
-	status := c.Query("status")
-	startDate := c.Query("startDate")
-	endDate := c.Query("endDate")
 
 	if page < 1 {
 		page = 1
@@ -75,88 +45,19 @@ func (h *ProcurementHandler) GetProcurementList(c *gin.Context) {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
-	query := h.db.Model(&Procurement{})
-
-	if orderNo != "" {
-		query = query.Where("order_no LIKE ?", "%"+orderNo+"%")
-	}
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
-	if startDate != "" {
-		query = query.Where("created_at >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("created_at <= ?", endDate+" 23:59:59")
-	}
-
-	var total int64
-	query.Count(&total)
-
-	var procurements []Procurement
-	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&procurements)
-
-	// 加载关联信息
-	userIDs := make([]int64, 0)
-	supplierIDs := make([]int64, 0)
-	procurementIDs := make([]int64, 0)
-
-	for _, p := range procurements {
-		userIDs = append(userIDs, p.ApplicantID)
-		if p.SupplierID != nil && *p.SupplierID > 0 {
-			supplierIDs = append(supplierIDs, *p.SupplierID)
-		}
-		procurementIDs = append(procurementIDs, p.ID)
-	}
-
-	// 用户名映射
-	userMap := make(map[int64]string)
-	if len(userIDs) > 0 {
-		var users []struct {
-			ID       int64  `gorm:"column:id"`
-			RealName string `gorm:"column:real_name"`
-		}
-		h.db.Table("sys_user").Where("id IN ?", userIDs).Find(&users)
-		for _, u := range users {
-			userMap[u.ID] = u.RealName
-		}
-	}
-
-	// 供应商名映射
-	supplierMap := make(map[int64]string)
-	if len(supplierIDs) > 0 {
-		var suppliers []Supplier
-		h.db.Where("id IN ?", supplierIDs).Find(&suppliers)
-		for _, s := range suppliers {
-			supplierMap[s.ID] = s.Name
-		}
-	}
-
-	// 计算总金额
-	amountMap := make(map[int64]float64)
-	if len(procurementIDs) > 0 {
-		var amounts []struct {
-			ProcurementID int64   `gorm:"column:procurement_id"`
-			Total         float64 `gorm:"column:total"`
-		}
-		h.db.Table("biz_procurement_item").
-			Select("procurement_id, SUM(plan_qty * COALESCE(unit_price, 0)) as total").
-			Where("procurement_id IN ?", procurementIDs).
-			Group("procurement_id").
-			Find(&amounts)
-		for _, a := range amounts {
-			amountMap[a.ProcurementID] = a.Total
-		}
-	}
-
-	// 填充关联信息
-	for i := range procurements {
-		procurements[i].ApplicantName = userMap[procurements[i].ApplicantID]
-		if procurements[i].SupplierID != nil {
-			procurements[i].SupplierName = supplierMap[*procurements[i].SupplierID]
-		}
-		procurements[i].TotalAmount = amountMap[procurements[i].ID]
+	procurements, total, err := h.procurement.List(repository.ProcurementFilter{
+		Scope:     currentDataScope(c, h.db).forStore(),
+		OrderNo:   c.Query("orderNo"),
+		Status:    c.Query("status"),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+		Deleted:   c.Query("deleted") == "1",
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	})
+	if err != nil {
+		serviceError(c, err, "查询失败")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -170,55 +71,13 @@ func (h *ProcurementHandler) GetProcurementList(c *gin.Context) {
 
 // GetProcurement 获取采购单详情
 func (h *ProcurementHandler) GetProcurement(c *gin.Context) {
-	id := c.Param("id")
-	var procurement Procurement
-	if err := h.db.First(&procurement, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "采购单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	procurement, items, err := h.procurement.Get(currentDataScope(c, h.db).forStore(), id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
 		return
 	}
 
-	// 获取明细
-	var items []ProcurementItem
-	h.db.Where("procurement_id = ?", id).Find(&items)
-
-	// 获取产品信息
-	productIDs := make([]int64, 0)
-	for _, item := range items {
-		productIDs = append(productIDs, item.ProductID)
-	}
-
-	productMap := make(map[int64]Product)
-	if len(productIDs) > 0 {
-		var products []Product
-		h.db.Where("id IN ?", productIDs).Find(&products)
-		for _, p := range products {
-			productMap[p.ID] = p
-		}
-	}
-
-	for i := range items {
-		if p, ok := productMap[items[i].ProductID]; ok {
-			items[i].ProductName = p.Name
-			items[i].ProductCode = p.SkuCode
-		}
-		if items[i].UnitPrice != nil {
-			items[i].Amount = items[i].PlanQty * *items[i].UnitPrice
-		}
-	}
-
-	// 获取供应商和申请人信息
-	var user struct {
-		RealName string `gorm:"column:real_name"`
-	}
-	h.db.Table("sys_user").Where("id = ?", procurement.ApplicantID).First(&user)
-	procurement.ApplicantName = user.RealName
-
-	if procurement.SupplierID != nil {
-		var supplier Supplier
-		h.db.First(&supplier, *procurement.SupplierID)
-		procurement.SupplierName = supplier.Name
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
@@ -247,6 +106,7 @@ func (h *ProcurementHandler) CreateProcurement(c *gin.Context) {
 			ProductID int64   `json:"productId"`
 			Quantity  float64 `json:"quantity"`
 			Price     float64 `json:"price"`
+			Unit      string  `json:"unit"`
 		} `json:"items"`
 	}
 
@@ -255,69 +115,36 @@ func (h *ProcurementHandler) CreateProcurement(c *gin.Context) {
 		return
 	}
 
-	// 获取当前用户ID
-	userID, _ := c.Get("userID")
-
-	// 生成单号
-	orderNo := fmt.Sprintf("PO%s%03d", time.Now().Format("20060102150405"), time.Now().Nanosecond()%1000)
-
-	// 解析日期
-	var expectedDate *time.Time
-	if req.ExpectedDate != "" {
-		if t, err := time.Parse("2006-01-02", req.ExpectedDate); err == nil {
-			expectedDate = &t
-		}
-	}
-
-	// 处理供应商ID
-	var supplierID *int64
-	if req.SupplierID > 0 {
-		supplierID = &req.SupplierID
-	}
-
-	procurement := Procurement{
-		OrderNo:      orderNo,
-		ApplicantID:  userID.(int64),
-		SupplierID:   supplierID,
-		Status:       "PENDING",
+	in := service.ProcurementInput{
+		SupplierID:   positiveID(req.SupplierID),
 		Reason:       req.Reason,
-		ExpectedDate: expectedDate,
-	}
-
-	tx := h.db.Begin()
-
-	if err := tx.Create(&procurement).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
-		return
+		ExpectedDate: parseDate(req.ExpectedDate),
 	}
-
-	// 创建明细
 	for _, item := range req.Items {
-		price := item.Price
-		procurementItem := ProcurementItem{
-			ProcurementID: procurement.ID,
-			ProductID:     item.ProductID,
-			PlanQty:       item.Quantity,
-			UnitPrice:     &price,
-		}
-		if err := tx.Create(&procurementItem).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建明细失败"})
-			return
-		}
+		in.Items = append(in.Items, service.ProcurementItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			Unit:      item.Unit,
+		})
 	}
 
-	tx.Commit()
+	procurement, err := h.procurement.Create(c.GetInt64("userID"), in)
+	if err != nil {
+		serviceError(c, err, "创建失败")
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "procurement", procurement.ID, nil, h.snapshot(procurement.ID))
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": procurement})
 }
 
 // UpdateProcurement 更新采购单
 func (h *ProcurementHandler) UpdateProcurement(c *gin.Context) {
-	id := c.Param("id")
-	var procurement Procurement
-	if err := h.db.First(&procurement, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "采购单不存在"})
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	scope := currentDataScope(c, h.db).forStore()
+	procurement, err := h.procurement.Find(scope, id)
+	if err != nil {
+		serviceError(c, err, "查询失败")
 		return
 	}
 
@@ -332,37 +159,78 @@ func (h *ProcurementHandler) UpdateProcurement(c *gin.Context) {
 		return
 	}
 
-	updates := map[string]interface{}{
-		"status": req.Status,
-		"reason": req.Reason,
-	}
-
-	// 处理供应商ID
-	if req.SupplierID > 0 {
-		updates["supplier_id"] = req.SupplierID
-	}
-
-	// 解析日期
-	if req.ExpectedDate != "" {
-		if t, err := time.Parse("2006-01-02", req.ExpectedDate); err == nil {
-			updates["expected_date"] = t
+	// 下单和完成需要下单权限，其余状态流转（审批、驳回、退回）需要采购审批权限
+	if req.Status != "" && req.Status != procurement.Status {
+		permission, message := "PROCUREMENT_APPROVE", "无采购审批权限"
+		if req.Status == "ORDERED" || req.Status == "DONE" {
+			permission, message = "PROCUREMENT_ORDER", "无采购下单权限"
+		}
+		if !hasPermission(c, h.db, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": message})
+			return
 		}
 	}
 
-	h.db.Model(&procurement).Updates(updates)
+	before := h.snapshot(id)
+	if err := h.procurement.Update(scope, id, service.ProcurementUpdate{
+		SupplierID:   positiveID(req.SupplierID),
+		Status:       req.Status,
+		Reason:       req.Reason,
+		ExpectedDate: parseDate(req.ExpectedDate),
+	}); err != nil {
+		serviceError(c, err, "更新失败")
+		return
+	}
+	recordAudit(c, h.db, auditUpdate, "procurement", id, before, h.snapshot(id))
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
 }
 
-// DeleteProcurement 删除采购单
+// DeleteProcurement 删除采购单（软删除，明细保留以便恢复）
 func (h *ProcurementHandler) DeleteProcurement(c *gin.Context) {
-	id := c.Param("id")
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
 
-	tx := h.db.Begin()
-	// 先删除明细
-	tx.Where("procurement_id = ?", id).Delete(&ProcurementItem{})
-	// 再删除主表
-	tx.Delete(&Procurement{}, id)
-	tx.Commit()
+	before := h.snapshot(id)
+	if err := h.procurement.Delete(currentDataScope(c, h.db).forStore(), id); err != nil {
+		serviceError(c, err, "删除失败")
+		return
+	}
+	recordAudit(c, h.db, auditDelete, "procurement", id, before, nil)
 
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
 }
+
+// RestoreProcurement 恢复已删除的采购单
+func (h *ProcurementHandler) RestoreProcurement(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.procurement.Restore(currentDataScope(c, h.db).forStore(), id); err != nil {
+		serviceError(c, err, "恢复失败")
+		return
+	}
+	recordAudit(c, h.db, auditRestore, "procurement", id, nil, h.snapshot(id))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
+
+// snapshot 采购单审计快照
+func (h *ProcurementHandler) snapshot(id int64) map[string]interface{} {
+	return auditDocument(h.db, &Procurement{}, id, &[]ProcurementItem{}, "procurement_id")
+}
+
+// positiveID 大于0的ID转为指针，0表示未指定
+func positiveID(id int64) *int64 {
+	if id <= 0 {
+		return nil
+	}
+	return &id
+}
+
+// parseDate 解析 yyyy-MM-dd 格式的日期，为空或格式错误时返回 nil
+func parseDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -11,17 +11,19 @@ import (
 
 // Product 产品模型
 type Product struct {
-	ID             int64     `json:"id" gorm:"column:id;primaryKey"`
-	CategoryID     int64     `json:"categoryId" gorm:"column:category_id"`
-	SkuCode        string    `json:"code" gorm:"column:sku_code"`
-	Name           string    `json:"name" gorm:"column:name"`
-	Specification  string    `json:"specification" gorm:"column:specification"`
-	Unit           string    `json:"unit" gorm:"column:unit"`
-	StockQty       float64   `json:"stockQty" gorm:"column:stock_qty"`
-	AlertThreshold float64   `json:"alertThreshold" gorm:"column:alert_threshold"`
-	Status         int       `json:"status" gorm:"column:status"`
-	CreatedAt      time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	ID             int64          `json:"id" gorm:"column:id;primaryKey"`
+	CategoryID     int64          `json:"categoryId" gorm:"column:category_id"`
+	SkuCode        string         `json:"code" gorm:"column:sku_code"`
+	Name           string         `json:"name" gorm:"column:name"`
+	Specification  string         `json:"specification" gorm:"column:specification"`
+	Unit           string         `json:"unit" gorm:"column:unit"`
+	StockQty       float64        `json:"stockQty" gorm:"column:stock_qty"`
+	AlertThreshold float64        `json:"alertThreshold" gorm:"column:alert_threshold"`
+	AbcClass       string         `json:"abcClass" gorm:"column:abc_class"`
+	Status         int            `json:"status" gorm:"column:status"`
+	CreatedAt      time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
 	// 关联字段
 	CategoryName string `json:"category" gorm:"-"`
 }
@@ -32,9 +34,10 @@ func (Product) TableName() string {
 
 // Category 分类模型
 type Category struct {
-	ID       int64  `json:"id" gorm:"column:id;primaryKey"`
-	Name     string `json:"name" gorm:"column:name"`
-	ParentID int64  `json:"parentId" gorm:"column:parent_id"`
+	ID        int64          `json:"id" gorm:"column:id;primaryKey"`
+	Name      string         `json:"name" gorm:"column:name"`
+	ParentID  int64          `json:"parentId" gorm:"column:parent_id"`
+	DeletedAt gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
 }
 
 func (Category) TableName() string {
@@ -69,7 +72,7 @@ func (h *ProductHandler) GetProductList(c *gin.Context) {
 	offset := (page - 1) * pageSize
 
 	// 构建查询
-	query := h.db.Model(&Product{})
+	query := h.db.Model(&Product{}).Scopes(trashScope(c))
 
 	// 关键字搜索
 	if keyword != "" {
@@ -185,6 +188,17 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	// 编码被已删除的产品占用时提示恢复，而不是报唯一索引冲突
+	var count int64
+	h.db.Unscoped().Model(&Product{}).Where("sku_code = ? AND deleted_at IS NOT NULL", req.SkuCode).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "产品编码已被已删除的产品使用，请恢复该产品",
+		})
+		return
+	}
+
 	product := Product{
 		CategoryID:     req.CategoryID,
 		SkuCode:        req.SkuCode,
@@ -202,6 +216,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		})
 		return
 	}
+	recordAudit(c, h.db, auditCreate, "product", product.ID, nil, product)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
@@ -251,6 +266,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		"status":          req.Status,
 	}
 
+	before := product
 	if err := h.db.Model(&product).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -258,6 +274,8 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		})
 		return
 	}
+	h.db.First(&product, product.ID)
+	recordAudit(c, h.db, auditUpdate, "product", product.ID, before, product)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
@@ -265,7 +283,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	})
 }
 
-// DeleteProduct 删除产品
+// DeleteProduct 删除产品（软删除，历史流水仍可关联）
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
@@ -278,6 +296,14 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
+	if msg := productDeleteBlocker(h.db, product); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+		})
+		return
+	}
+
 	if err := h.db.Delete(&product).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -285,9 +311,43 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		})
 		return
 	}
+	recordAudit(c, h.db, auditDelete, "product", product.ID, product, nil)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
 		"message": "删除成功",
 	})
 }
+
+// RestoreProduct 恢复已删除的产品
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	var product Product
+	if !findDeleted(c, h.db, &product, c.Param("id"), "已删除的产品不存在") {
+		return
+	}
+
+	var count int64
+	h.db.Model(&Category{}).Where("id = ?", product.CategoryID).Count(&count)
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "所属分类已删除，请先恢复分类",
+		})
+		return
+	}
+
+	if err := restoreRecord(h.db, &product); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "恢复失败: " + err.Error(),
+		})
+		return
+	}
+	h.db.First(&product, product.ID)
+	recordAudit(c, h.db, auditRestore, "product", product.ID, nil, product)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "恢复成功",
+	})
+}
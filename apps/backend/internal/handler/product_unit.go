@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"easywms/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductUnit 产品辅助计量单位模型
+type ProductUnit = model.ProductUnit
+
+// GetProductUnits 获取产品计量单位
+func (h *ProductHandler) GetProductUnits(c *gin.Context) {
+	id := c.Param("id")
+
+	var product Product
+	if err := h.db.First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "产品不存在"})
+		return
+	}
+
+	var units []ProductUnit
+	h.db.Where("product_id = ?", product.ID).Order("factor ASC").Find(&units)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"baseUnit": product.Unit,
+			"units":    units,
+		},
+	})
+}
+
+// SaveProductUnits 保存产品辅助计量单位（整体替换）
+func (h *ProductHandler) SaveProductUnits(c *gin.Context) {
+	id := c.Param("id")
+
+	var product Product
+	if err := h.db.First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "产品不存在"})
+		return
+	}
+
+	var req struct {
+		Units []struct {
+			Unit   string  `json:"unit"`
+			Factor float64 `json:"factor"`
+		} `json:"units"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, u := range req.Units {
+		if u.Unit == "" || u.Factor <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "单位名称不能为空且换算系数必须大于0"})
+			return
+		}
+		if u.Unit == product.Unit {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "辅助单位不能与基本单位相同"})
+			return
+		}
+		if seen[u.Unit] {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "单位重复: " + u.Unit})
+			return
+		}
+		seen[u.Unit] = true
+	}
+
+	var before []ProductUnit
+	h.db.Where("product_id = ?", product.ID).Find(&before)
+
+	tx := h.db.Begin()
+	tx.Where("product_id = ?", product.ID).Delete(&ProductUnit{})
+	var after []ProductUnit
+	for _, u := range req.Units {
+		productUnit := ProductUnit{
+			ProductID: product.ID,
+			Unit:      u.Unit,
+			Factor:    u.Factor,
+		}
+		if err := tx.Create(&productUnit).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存失败"})
+			return
+		}
+		after = append(after, productUnit)
+	}
+	tx.Commit()
+	recordAudit(c, h.db, auditUpdate, "product_unit", product.ID, gin.H{"units": before}, gin.H{"units": after})
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "保存成功"})
+}
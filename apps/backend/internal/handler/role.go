@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"easywms/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Role 角色模型
+type Role struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey"`
+	Code        string    `json:"code" gorm:"column:code"`
+	Name        string    `json:"name" gorm:"column:name"`
+	Description string    `json:"description" gorm:"column:description"`
+	Builtin     int       `json:"builtin" gorm:"column:builtin"`
+	Status      int       `json:"status" gorm:"column:status"`
+	DataScope   string    `json:"dataScope" gorm:"column:data_scope"` // ALL/DEPT/SELF
+	CreatedAt   time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	// 关联字段
+	Permissions []string `json:"permissions,omitempty" gorm:"-"`
+	UserCount   int64    `json:"userCount" gorm:"-"`
+}
+
+func (Role) TableName() string {
+	return "sys_role"
+}
+
+// Permission 权限码模型
+type Permission struct {
+	ID          int64  `json:"id" gorm:"column:id;primaryKey"`
+	Code        string `json:"code" gorm:"column:code"`
+	Name        string `json:"name" gorm:"column:name"`
+	Description string `json:"description" gorm:"column:description"`
+	Module      string `json:"module" gorm:"column:module"`
+}
+
+func (Permission) TableName() string {
+	return "sys_permission"
+}
+
+// RolePermission 角色权限关联模型
+type RolePermission struct {
+	ID             int64     `json:"id" gorm:"column:id;primaryKey"`
+	RoleCode       string    `json:"roleCode" gorm:"column:role_code"`
+	PermissionCode string    `json:"permissionCode" gorm:"column:permission_code"`
+	CreatedAt      time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RolePermission) TableName() string {
+	return "sys_role_permission"
+}
+
+// roleCodePattern 角色代码格式
+var roleCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,19}$`)
+
+// RoleHandler 角色处理器
+type RoleHandler struct {
+	db *gorm.DB
+}
+
+// NewRoleHandler 创建角色处理器
+func NewRoleHandler(db *gorm.DB) *RoleHandler {
+	return &RoleHandler{db: db}
+}
+
+// GetRoleList 获取角色列表
+func (h *RoleHandler) GetRoleList(c *gin.Context) {
+	var roles []Role
+	h.db.Order("builtin DESC, id ASC").Find(&roles)
+
+	var counts []struct {
+		RoleCode string `gorm:"column:role_code"`
+		Total    int64  `gorm:"column:total"`
+	}
+	h.db.Model(&model.User{}).
+		Select("role_code, COUNT(*) as total").
+		Where("status = ?", 1).
+		Group("role_code").
+		Find(&counts)
+	countMap := make(map[string]int64)
+	for _, cnt := range counts {
+		countMap[cnt.RoleCode] = cnt.Total
+	}
+
+	for i := range roles {
+		roles[i].UserCount = countMap[roles[i].Code]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": roles})
+}
+
+// GetRole 获取角色详情（含权限码）
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id := c.Param("id")
+	var role Role
+	if err := h.db.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "角色不存在"})
+		return
+	}
+
+	role.Permissions = make([]string, 0)
+	h.db.Model(&RolePermission{}).Where("role_code = ?", role.Code).Pluck("permission_code", &role.Permissions)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": role})
+}
+
+// CreateRole 创建自定义角色
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req struct {
+		Code        string   `json:"code"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		DataScope   string   `json:"dataScope"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if !roleCodePattern.MatchString(req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "角色代码须为大写字母开头的大写字母、数字或下划线，最长20位"})
+		return
+	}
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "角色名称不能为空"})
+		return
+	}
+
+	if req.DataScope == "" {
+		req.DataScope = dataScopeSelf
+	}
+	if !validDataScope(req.DataScope) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "数据权限范围无效"})
+		return
+	}
+
+	var count int64
+	h.db.Model(&Role{}).Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "角色代码已存在"})
+		return
+	}
+
+	if msg := h.validatePermissions(req.Permissions); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	role := Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      1,
+		DataScope:   req.DataScope,
+	}
+
+	tx := h.db.Begin()
+	if err := tx.Create(&role).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+		return
+	}
+	if err := replaceRolePermissions(tx, role.Code, req.Permissions); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存权限失败"})
+		return
+	}
+	tx.Commit()
+	recordAudit(c, h.db, auditCreate, "role", role.ID, nil, h.snapshot(role.ID))
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": role})
+}
+
+// UpdateRole 更新角色（角色代码不可修改）
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+	var role Role
+	if err := h.db.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "角色不存在"})
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		DataScope   string `json:"dataScope"`
+		Status      *int   `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "角色名称不能为空"})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":        req.Name,
+		"description": req.Description,
+	}
+	if req.DataScope != "" {
+		if !validDataScope(req.DataScope) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "数据权限范围无效"})
+			return
+		}
+		updates["data_scope"] = req.DataScope
+	}
+	if req.Status != nil {
+		if *req.Status != 1 && role.Code == "ADMIN" {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "系统管理员角色不能停用"})
+			return
+		}
+		updates["status"] = *req.Status
+	}
+
+	before := h.snapshot(role.ID)
+	h.db.Model(&role).Updates(updates)
+	recordAudit(c, h.db, auditUpdate, "role", role.ID, before, h.snapshot(role.ID))
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
+
+// DeleteRole 删除自定义角色
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id := c.Param("id")
+	var role Role
+	if err := h.db.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "角色不存在"})
+		return
+	}
+
+	if role.Builtin == 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "内置角色不能删除"})
+		return
+	}
+
+	var count int64
+	h.db.Model(&model.User{}).Where("role_code = ?", role.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "角色下存在用户，无法删除"})
+		return
+	}
+
+	before := h.snapshot(role.ID)
+	tx := h.db.Begin()
+	tx.Where("role_code = ?", role.Code).Delete(&RolePermission{})
+	tx.Delete(&role)
+	tx.Commit()
+	recordAudit(c, h.db, auditDelete, "role", role.ID, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// SaveRolePermissions 保存角色权限（整体替换），对已登录用户即时生效
+func (h *RoleHandler) SaveRolePermissions(c *gin.Context) {
+	id := c.Param("id")
+	var role Role
+	if err := h.db.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "角色不存在"})
+		return
+	}
+
+	var req struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if msg := h.validatePermissions(req.Permissions); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	// 防止管理员角色失去角色管理权限后无法恢复
+	if role.Code == "ADMIN" && !containsString(req.Permissions, "ROLE_MANAGE") {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "系统管理员角色必须保留角色管理权限"})
+		return
+	}
+
+	before := h.snapshot(role.ID)
+	tx := h.db.Begin()
+	if err := replaceRolePermissions(tx, role.Code, req.Permissions); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存失败"})
+		return
+	}
+	tx.Commit()
+	recordAudit(c, h.db, auditUpdate, "role", role.ID, before, h.snapshot(role.ID))
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "保存成功"})
+}
+
+// snapshot 角色审计快照，包含权限码
+func (h *RoleHandler) snapshot(id int64) *Role {
+	var role Role
+	if err := h.db.First(&role, id).Error; err != nil {
+		return nil
+	}
+	role.Permissions = make([]string, 0)
+	h.db.Model(&RolePermission{}).Where("role_code = ?", role.Code).Pluck("permission_code", &role.Permissions)
+	return &role
+}
+
+// GetPermissionList 获取全部权限码
+func (h *RoleHandler) GetPermissionList(c *gin.Context) {
+	var permissions []Permission
+	h.db.Order("module ASC, id ASC").Find(&permissions)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": permissions})
+}
+
+// validatePermissions 校验权限码是否存在，返回错误信息
+func (h *RoleHandler) validatePermissions(codes []string) string {
+	if len(codes) == 0 {
+		return ""
+	}
+	var existing []string
+	h.db.Model(&Permission{}).Where("code IN ?", codes).Pluck("code", &existing)
+	for _, code := range codes {
+		if !containsString(existing, code) {
+			return "权限码不存在: " + code
+		}
+	}
+	return ""
+}
+
+// replaceRolePermissions 替换角色的权限码
+func replaceRolePermissions(tx *gorm.DB, roleCode string, codes []string) error {
+	if err := tx.Where("role_code = ?", roleCode).Delete(&RolePermission{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		rp := RolePermission{RoleCode: roleCode, PermissionCode: code}
+		if err := tx.Create(&rp).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"easywms/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceError 按业务错误类别写出响应，非业务错误返回500和 fallback 提示
+func serviceError(c *gin.Context, err error, fallback string) {
+	switch service.KindOf(err) {
+	case service.KindNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case service.KindInvalid:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": fallback})
+	}
+}
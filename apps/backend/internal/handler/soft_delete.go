@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// trashScope 列表查询的删除状态过滤：默认排除已删除记录，deleted=1 时只查回收站
+func trashScope(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if c.Query("deleted") == "1" {
+			return db.Unscoped().Where("deleted_at IS NOT NULL")
+		}
+		return db
+	}
+}
+
+// findDeleted 查询已删除的记录，scopes 为数据权限等附加条件，找不到时已写出404响应并返回 false
+func findDeleted(c *gin.Context, db *gorm.DB, dest interface{}, id, notFound string, scopes ...func(*gorm.DB) *gorm.DB) bool {
+	if err := db.Unscoped().Scopes(scopes...).Where("deleted_at IS NOT NULL").First(dest, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": notFound})
+		return false
+	}
+	return true
+}
+
+// restoreRecord 清除删除标记
+func restoreRecord(db *gorm.DB, dest interface{}) error {
+	return db.Unscoped().Model(dest).Update("deleted_at", nil).Error
+}
+
+// productDeleteBlocker 检查产品能否删除，返回不能删除的原因
+func productDeleteBlocker(db *gorm.DB, product Product) string {
+	if product.StockQty != 0 {
+		return "产品仍有库存，无法删除"
+	}
+
+	var count int64
+	db.Model(&ProcurementItem{}).
+		Joins("JOIN biz_procurement p ON p.id = biz_procurement_item.procurement_id").
+		Where("biz_procurement_item.product_id = ? AND p.deleted_at IS NULL AND p.status IN ?",
+			product.ID, []string{"PENDING", "APPROVED", "ORDERED"}).
+		Count(&count)
+	if count > 0 {
+		return "存在未完成的采购单，无法删除"
+	}
+
+	db.Model(&InboundItem{}).
+		Joins("JOIN biz_inbound i ON i.id = biz_inbound_item.inbound_id").
+		Where("biz_inbound_item.product_id = ? AND i.deleted_at IS NULL AND i.status = ?", product.ID, 0).
+		Count(&count)
+	if count > 0 {
+		return "存在未完成的入库单，无法删除"
+	}
+
+	db.Model(&OutboundItem{}).
+		Joins("JOIN biz_outbound o ON o.id = biz_outbound_item.outbound_id").
+		Where("biz_outbound_item.product_id = ? AND o.deleted_at IS NULL AND o.status IN ?",
+			product.ID, []string{"PENDING", "APPROVED"}).
+		Count(&count)
+	if count > 0 {
+		return "存在未完成的出库单，无法删除"
+	}
+
+	db.Model(&InventoryCheckItem{}).
+		Joins("JOIN biz_inventory_check c ON c.id = biz_inventory_check_item.check_id").
+		Where("biz_inventory_check_item.product_id = ? AND c.deleted_at IS NULL AND c.status = ?", product.ID, "CHECKING").
+		Count(&count)
+	if count > 0 {
+		return "产品正在盘点中，无法删除"
+	}
+
+	db.Model(&KitAssembly{}).Where("kit_id = ? AND status = ?", product.ID, "PENDING").Count(&count)
+	if count > 0 {
+		return "存在未完成的组装单，无法删除"
+	}
+
+	db.Model(&KitComponent{}).Where("kit_id = ? OR product_id = ?", product.ID, product.ID).Count(&count)
+	if count > 0 {
+		return "产品已定义套件组成或是其他套件的组件，无法删除"
+	}
+
+	return ""
+}
@@ -3,27 +3,15 @@ package handler
 import (
 	"net/http"
 	"strconv"
-	"time"
+
+	"easywms/internal/model"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // StockLog 库存流水模型
-type StockLog struct {
-	ID          int64     `json:"id" gorm:"column:id;primaryKey"`
-	ProductID   int64     `json:"productId" gorm:"column:product_id"`
-	Type        string    `json:"type" gorm:"column:type"`
-	ChangeQty   float64   `json:"changeQty" gorm:"column:change_qty"`
-	SnapshotQty float64   `json:"snapshotQty" gorm:"column:snapshot_qty"`
-	RelatedNo   string    `json:"relatedNo" gorm:"column:related_no"`
-	OperatorID  *int64    `json:"operatorId" gorm:"column:operator_id"`
-	CreatedAt   time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-}
-
-func (StockLog) TableName() string {
-	return "biz_stock_log"
-}
+type StockLog = model.StockLog
 
 // StockHandler 库存处理器
 type StockHandler struct {
@@ -174,7 +162,7 @@ func (h *StockHandler) GetStockLogs(c *gin.Context) {
 	}
 
 	offset := (page - 1) * pageSize
-	query := h.db.Model(&StockLog{})
+	query := h.db.Model(&StockLog{}).Scopes(currentDataScope(c, h.db).stockLogFilter(""))
 
 	if productID != "" {
 		query = query.Where("product_id = ?", productID)
@@ -203,7 +191,7 @@ func (h *StockHandler) GetStockLogs(c *gin.Context) {
 	productMap := make(map[int64]Product)
 	if len(productIDs) > 0 {
 		var products []Product
-		h.db.Where("id IN ?", productIDs).Find(&products)
+		h.db.Unscoped().Where("id IN ?", productIDs).Find(&products)
 		for _, p := range products {
 			productMap[p.ID] = p
 		}
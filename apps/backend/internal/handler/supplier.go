@@ -11,14 +11,15 @@ import (
 
 // Supplier 供应商模型
 type Supplier struct {
-	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
-	Name      string    `json:"name" gorm:"column:name"`
-	Contact   string    `json:"contact" gorm:"column:contact"`
-	Phone     string    `json:"phone" gorm:"column:phone"`
-	Address   string    `json:"address" gorm:"column:address"`
-	Status    int       `json:"status" gorm:"column:status"`
-	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	ID        int64          `json:"id" gorm:"column:id;primaryKey"`
+	Name      string         `json:"name" gorm:"column:name"`
+	Contact   string         `json:"contact" gorm:"column:contact"`
+	Phone     string         `json:"phone" gorm:"column:phone"`
+	Address   string         `json:"address" gorm:"column:address"`
+	Status    int            `json:"status" gorm:"column:status"`
+	CreatedAt time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
 }
 
 func (Supplier) TableName() string {
@@ -50,7 +51,7 @@ func (h *SupplierHandler) GetSupplierList(c *gin.Context) {
 	}
 
 	offset := (page - 1) * pageSize
-	query := h.db.Model(&Supplier{})
+	query := h.db.Model(&Supplier{}).Scopes(trashScope(c))
 
 	if keyword != "" {
 		query = query.Where("name LIKE ? OR contact LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
@@ -115,6 +116,7 @@ func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
 		return
 	}
+	recordAudit(c, h.db, auditCreate, "supplier", supplier.ID, nil, supplier)
 
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": supplier})
 }
@@ -140,6 +142,7 @@ func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
 		return
 	}
 
+	before := supplier
 	h.db.Model(&supplier).Updates(map[string]interface{}{
 		"name":    req.Name,
 		"contact": req.Contact,
@@ -147,17 +150,49 @@ func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
 		"address": req.Address,
 		"status":  req.Status,
 	})
+	h.db.First(&supplier, supplier.ID)
+	recordAudit(c, h.db, auditUpdate, "supplier", supplier.ID, before, supplier)
 
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
 }
 
-// DeleteSupplier 删除供应商
+// DeleteSupplier 删除供应商（软删除，历史采购单仍可关联）
 func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.db.Delete(&Supplier{}, id).Error; err != nil {
+	var supplier Supplier
+	if err := h.db.First(&supplier, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "供应商不存在"})
+		return
+	}
+	var count int64
+	h.db.Model(&Procurement{}).
+		Where("supplier_id = ? AND status IN ?", supplier.ID, []string{"PENDING", "APPROVED", "ORDERED"}).
+		Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "存在未完成的采购单，无法删除"})
+		return
+	}
+
+	if err := h.db.Delete(&supplier).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败"})
 		return
 	}
+	recordAudit(c, h.db, auditDelete, "supplier", supplier.ID, supplier, nil)
 	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
 }
 
+// RestoreSupplier 恢复已删除的供应商
+func (h *SupplierHandler) RestoreSupplier(c *gin.Context) {
+	var supplier Supplier
+	if !findDeleted(c, h.db, &supplier, c.Param("id"), "已删除的供应商不存在") {
+		return
+	}
+	if err := restoreRecord(h.db, &supplier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "恢复失败"})
+		return
+	}
+	h.db.First(&supplier, supplier.ID)
+	recordAudit(c, h.db, auditRestore, "supplier", supplier.ID, nil, supplier)
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "恢复成功"})
+}
+
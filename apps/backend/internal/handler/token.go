@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// refreshTokenCookie 刷新令牌Cookie名称
+const refreshTokenCookie = "refresh_token"
+
+// RefreshToken 刷新令牌模型（只保存摘要）
+type RefreshToken struct {
+	ID        int64      `json:"id" gorm:"column:id;primaryKey"`
+	UserID    int64      `json:"userId" gorm:"column:user_id"`
+	TokenHash string     `json:"-" gorm:"column:token_hash"`
+	AccessJTI string     `json:"-" gorm:"column:access_jti"` // 同批签发的访问令牌jti，吊销时一并拉黑
+	ExpiresAt time.Time  `json:"expireTime" gorm:"column:expires_at"`
+	RevokedAt *time.Time `json:"revokeTime" gorm:"column:revoked_at"`
+	IP        string     `json:"ip" gorm:"column:ip"`
+	UserAgent string     `json:"userAgent" gorm:"column:user_agent"`
+	CreatedAt time.Time  `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "sys_refresh_token"
+}
+
+// TokenDenylist 访问令牌黑名单模型
+type TokenDenylist struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	JTI       string    `json:"jti" gorm:"column:jti"`
+	UserID    int64     `json:"userId" gorm:"column:user_id"`
+	ExpiresAt time.Time `json:"expireTime" gorm:"column:expires_at"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (TokenDenylist) TableName() string {
+	return "sys_token_denylist"
+}
+
+// RefreshAccessToken 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+func (h *AuthHandler) RefreshAccessToken(c *gin.Context) {
+	raw := requestRefreshToken(c)
+	if raw == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未提供刷新令牌"})
+		return
+	}
+
+	var stored RefreshToken
+	if err := h.db.Where("token_hash = ?", utils.HashToken(raw)).First(&stored).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "刷新令牌无效"})
+		return
+	}
+
+	// 已轮换的令牌被再次使用，视为泄露，吊销该用户全部会话
+	if stored.RevokedAt != nil {
+		revokeUserTokens(h.db, stored.UserID, h.cfg.JWT.AccessTTL())
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "刷新令牌已失效，请重新登录"})
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "刷新令牌已过期，请重新登录"})
+		return
+	}
+
+	var user model.User
+	if err := h.db.First(&user, stored.UserID).Error; err != nil || user.Status != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "账号不存在或已被禁用"})
+		return
+	}
+
+	// 条件更新保证并发刷新时只有一个请求能轮换成功
+	now := time.Now()
+	result := h.db.Model(&RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", stored.ID).
+		Update("revoked_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "刷新令牌已失效，请重新登录"})
+		return
+	}
+
+	tokens, err := issueTokens(c, h.db, h.cfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":         0,
+		"data":         tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+	})
+}
+
+// issueTokens 签发访问令牌和刷新令牌，刷新令牌同时写入HttpOnly Cookie
+func issueTokens(c *gin.Context, db *gorm.DB, cfg *config.Config, user model.User) (LoginResponse, error) {
+	accessToken, jti, err := utils.GenerateAccessToken(user.ID, user.Username, user.RoleCode, cfg.JWT.Secret, cfg.JWT.AccessTTL())
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	stored := RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		AccessJTI: jti,
+		ExpiresAt: time.Now().Add(cfg.JWT.RefreshTTL()),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := db.Create(&stored).Error; err != nil {
+		return LoginResponse{}, err
+	}
+
+	c.SetCookie(refreshTokenCookie, refreshToken, int(cfg.JWT.RefreshTTL().Seconds()), "/api/auth", "", secureCookie(cfg), true)
+
+	return LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// requestRefreshToken 从请求体或Cookie中读取刷新令牌
+func requestRefreshToken(c *gin.Context) string {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.RefreshToken != "" {
+		return req.RefreshToken
+	}
+	if cookie, err := c.Cookie(refreshTokenCookie); err == nil {
+		return cookie
+	}
+	return ""
+}
+
+// revokeSession 吊销当前会话：访问令牌加入黑名单，刷新令牌作废
+func revokeSession(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	authHeader := c.GetHeader("Authorization")
+	if parts := strings.SplitN(authHeader, " ", 2); len(parts) == 2 && parts[0] == "Bearer" {
+		if claims, err := utils.ParseToken(parts[1], cfg.JWT.Secret); err == nil && claims.ID != "" {
+			denyAccessToken(db, claims.ID, claims.UserID, claims.ExpiresAt.Time)
+		}
+	}
+
+	if raw := requestRefreshToken(c); raw != "" {
+		db.Model(&RefreshToken{}).
+			Where("token_hash = ? AND revoked_at IS NULL", utils.HashToken(raw)).
+			Update("revoked_at", time.Now())
+	}
+
+	c.SetCookie(refreshTokenCookie, "", -1, "/api/auth", "", secureCookie(cfg), true)
+}
+
+// secureCookie Cookie是否设置 Secure 标记，未配置时除 debug 模式外都只允许 HTTPS 发送
+func secureCookie(cfg *config.Config) bool {
+	if cfg.Server.CookieSecure != nil {
+		return *cfg.Server.CookieSecure
+	}
+	return gin.Mode() != gin.DebugMode
+}
+
+// denyAccessToken 将访问令牌加入黑名单，直到其自然过期
+func denyAccessToken(db *gorm.DB, jti string, userID int64, expiresAt time.Time) {
+	// 顺带清理已过期的黑名单记录
+	db.Where("expires_at < ?", time.Now()).Delete(&TokenDenylist{})
+
+	var count int64
+	db.Model(&TokenDenylist{}).Where("jti = ?", jti).Count(&count)
+	if count > 0 {
+		return
+	}
+	db.Create(&TokenDenylist{JTI: jti, UserID: userID, ExpiresAt: expiresAt})
+}
+
+// revokeUserTokens 吊销用户全部会话，用于禁用账号、重置密码或检测到令牌重放
+func revokeUserTokens(db *gorm.DB, userID int64, accessTTL time.Duration) {
+	now := time.Now()
+
+	// 仍在有效期内的访问令牌加入黑名单
+	var sessions []RefreshToken
+	db.Where("user_id = ? AND created_at > ?", userID, now.Add(-accessTTL)).Find(&sessions)
+	for _, s := range sessions {
+		if s.AccessJTI != "" {
+			denyAccessToken(db, s.AccessJTI, userID, s.CreatedAt.Add(accessTTL))
+		}
+	}
+
+	db.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now)
+}
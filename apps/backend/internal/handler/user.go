@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"easywms/internal/config"
+	"easywms/internal/model"
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// minPasswordLength 未配置密码策略时的最小长度
+const minPasswordLength = 6
+
+// UserHandler 用户管理处理器
+type UserHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewUserHandler 创建用户管理处理器
+func NewUserHandler(db *gorm.DB, cfg *config.Config) *UserHandler {
+	return &UserHandler{db: db, cfg: cfg}
+}
+
+// GetUserList 获取用户列表
+func (h *UserHandler) GetUserList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	keyword := c.Query("keyword")
+	deptID := c.Query("deptId")
+	roleCode := c.Query("roleCode")
+	statusStr := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	query := h.db.Model(&model.User{})
+
+	if keyword != "" {
+		query = query.Where("username LIKE ? OR real_name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if deptID != "" {
+		query = query.Where("dept_id = ?", deptID)
+	}
+	if roleCode != "" {
+		query = query.Where("role_code = ?", roleCode)
+	}
+	if statusStr != "" {
+		if status, err := strconv.Atoi(statusStr); err == nil {
+			query = query.Where("status = ?", status)
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var users []model.User
+	query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&users)
+
+	// 部门名映射
+	deptIDs := make([]int64, 0, len(users))
+	for _, u := range users {
+		deptIDs = append(deptIDs, u.DeptID)
+	}
+	deptMap := make(map[int64]string)
+	if len(deptIDs) > 0 {
+		var depts []model.Department
+		h.db.Where("id IN ?", deptIDs).Find(&depts)
+		for _, d := range depts {
+			deptMap[d.ID] = d.Name
+		}
+	}
+
+	roleMap := h.roleNames()
+
+	items := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		items = append(items, userView(u, deptMap[u.DeptID], roleMap[u.RoleCode]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"items": items,
+			"total": total,
+		},
+	})
+}
+
+// GetUser 获取用户详情
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	var dept model.Department
+	h.db.First(&dept, user.DeptID)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": userView(user, dept.Name, h.roleNames()[user.RoleCode])})
+}
+
+// CreateUser 创建用户
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		RealName string `json:"realName"`
+		Email    string `json:"email"`
+		DeptID   int64  `json:"deptId"`
+		RoleCode string `json:"roleCode"`
+		Status   *int8  `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if req.Username == "" || req.RealName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "账号和姓名不能为空"})
+		return
+	}
+	if msg := validatePassword(h.cfg.Password, req.Password); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+	if msg := h.validateAssignment(req.RoleCode, req.DeptID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	var count int64
+	h.db.Model(&model.User{}).Where("username = ?", req.Username).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "账号已存在"})
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码加密失败"})
+		return
+	}
+
+	user := model.User{
+		Username: req.Username,
+		Password: hash,
+		RealName: req.RealName,
+		Email:    req.Email,
+		DeptID:   req.DeptID,
+		RoleCode: req.RoleCode,
+		Status:   1,
+	}
+	if req.Status != nil {
+		user.Status = *req.Status
+	}
+	// 管理员设置的初始密码按策略决定是否须在首次登录时修改
+	if !h.cfg.Password.ForceChangeOnReset {
+		now := time.Now()
+		user.PasswordChangedAt = &now
+	}
+
+	if err := h.db.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建失败"})
+		return
+	}
+	recordAudit(c, h.db, auditCreate, "user", user.ID, nil, user)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "创建成功", "data": userView(user, "", "")})
+}
+
+// UpdateUser 更新用户
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	var req struct {
+		RealName string `json:"realName"`
+		Email    string `json:"email"`
+		DeptID   int64  `json:"deptId"`
+		RoleCode string `json:"roleCode"`
+		Status   *int8  `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if req.RealName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "姓名不能为空"})
+		return
+	}
+	if msg := h.validateAssignment(req.RoleCode, req.DeptID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"real_name": req.RealName,
+		"email":     req.Email,
+		"dept_id":   req.DeptID,
+		"role_code": req.RoleCode,
+	}
+	if req.Status != nil {
+		if *req.Status != 1 && isCurrentUser(c, user.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能禁用当前登录账号"})
+			return
+		}
+		updates["status"] = *req.Status
+	}
+
+	before := user
+	h.db.Model(&user).Updates(updates)
+	h.db.First(&user, user.ID)
+	recordAudit(c, h.db, auditUpdate, "user", user.ID, before, user)
+
+	// 禁用账号后立即吊销其全部会话
+	if req.Status != nil && *req.Status != 1 {
+		revokeUserTokens(h.db, user.ID, h.cfg.JWT.AccessTTL())
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
+
+// DeleteUser 删除用户（禁用账号，保留历史单据关联）
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	if isCurrentUser(c, user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能删除当前登录账号"})
+		return
+	}
+
+	before := user
+	if err := h.db.Model(&user).Update("status", 0).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败"})
+		return
+	}
+	recordAudit(c, h.db, auditDelete, "user", user.ID, before, nil)
+	revokeUserTokens(h.db, user.ID, h.cfg.JWT.AccessTTL())
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// ResetPassword 重置用户密码
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	id := c.Param("id")
+	var user model.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+	if msg := validatePassword(h.cfg.Password, req.Password); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		return savePassword(tx, h.cfg.Password, user, req.Password, false)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+	before := user
+	h.db.First(&user, user.ID)
+	recordAudit(c, h.db, auditUpdate, "user", user.ID, before, user)
+	revokeUserTokens(h.db, user.ID, h.cfg.JWT.AccessTTL())
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "密码已重置"})
+}
+
+// validateAssignment 校验角色和部门是否有效，返回错误信息
+func (h *UserHandler) validateAssignment(roleCode string, deptID int64) string {
+	var count int64
+	h.db.Model(&Role{}).Where("code = ? AND status = ?", roleCode, 1).Count(&count)
+	if count == 0 {
+		return "角色不存在或已停用: " + roleCode
+	}
+	h.db.Model(&model.Department{}).Where("id = ?", deptID).Count(&count)
+	if count == 0 {
+		return "部门不存在"
+	}
+	return ""
+}
+
+// roleNames 角色代码与名称映射
+func (h *UserHandler) roleNames() map[string]string {
+	var roles []Role
+	h.db.Find(&roles)
+	names := make(map[string]string, len(roles))
+	for _, r := range roles {
+		names[r.Code] = r.Name
+	}
+	return names
+}
+
+// isCurrentUser 判断是否为当前登录用户
+func isCurrentUser(c *gin.Context, userID int64) bool {
+	current, exists := c.Get("userID")
+	return exists && current.(int64) == userID
+}
+
+// userView 用户信息输出，不含密码
+func userView(user model.User, deptName, roleName string) gin.H {
+	return gin.H{
+		"id":         user.ID,
+		"username":   user.Username,
+		"realName":   user.RealName,
+		"email":      user.Email,
+		"deptId":     user.DeptID,
+		"deptName":   deptName,
+		"roleCode":   user.RoleCode,
+		"roleName":   roleName,
+		"status":     user.Status,
+		"createTime": user.CreatedAt,
+		"updateTime": user.UpdatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"easywms/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader API密钥请求头
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyRecord API密钥记录
+type apiKeyRecord struct {
+	ID        int64      `gorm:"column:id"`
+	UserID    int64      `gorm:"column:user_id"`
+	Status    int        `gorm:"column:status"`
+	ExpiresAt *time.Time `gorm:"column:expires_at"`
+}
+
+// apiKeyAuth 使用API密钥认证，以密钥绑定的用户身份执行，并记录调用日志
+func apiKeyAuth(c *gin.Context, db *gorm.DB, key string) {
+	var record apiKeyRecord
+	if err := db.Table("sys_api_key").Where("key_hash = ?", utils.HashToken(key)).First(&record).Error; err != nil ||
+		record.Status != 1 || (record.ExpiresAt != nil && time.Now().After(*record.ExpiresAt)) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "API密钥无效或已过期",
+		})
+		c.Abort()
+		return
+	}
+
+	var user struct {
+		ID       int64  `gorm:"column:id"`
+		Username string `gorm:"column:username"`
+		RoleCode string `gorm:"column:role_code"`
+		Status   int    `gorm:"column:status"`
+	}
+	if err := db.Table("sys_user").Where("id = ?", record.UserID).First(&user).Error; err != nil || user.Status != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "API密钥绑定的账号不存在或已被禁用",
+		})
+		c.Abort()
+		return
+	}
+
+	// 密钥的权限范围以绑定用户角色当前拥有的权限为上限
+	permissions := make([]string, 0)
+	db.Table("sys_api_key_permission p").
+		Joins("JOIN sys_role_permission rp ON rp.permission_code = p.permission_code AND rp.role_code = ?", user.RoleCode).
+		Joins("JOIN sys_role r ON r.code = rp.role_code AND r.status = 1").
+		Where("p.api_key_id = ?", record.ID).
+		Pluck("p.permission_code", &permissions)
+
+	c.Set("userID", user.ID)
+	c.Set("username", user.Username)
+	c.Set("roleCode", user.RoleCode)
+	c.Set("apiKeyID", record.ID)
+	c.Set("apiKeyPermissions", permissions)
+
+	db.Table("sys_api_key").Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"last_used_ip": c.ClientIP(),
+	})
+
+	// 按接口校验密钥权限范围，未登记的接口不允许使用API密钥调用
+	if apiKeyRouteAllowed(c.Request.Method, c.FullPath(), permissions) {
+		c.Next()
+	} else {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "API密钥无权调用该接口",
+		})
+		c.Abort()
+	}
+
+	db.Table("sys_api_key_log").Create(map[string]interface{}{
+		"api_key_id":  record.ID,
+		"method":      c.Request.Method,
+		"path":        c.Request.URL.Path,
+		"status_code": c.Writer.Status(),
+		"ip":          c.ClientIP(),
+		"created_at":  time.Now(),
+	})
+}
@@ -0,0 +1,126 @@
+package middleware
+
+// apiKeyRoutePermissions API密钥可调用的接口及所需权限，键为“方法 路由”。
+// 列出多个权限时须全部具备；未列出的接口（个人账号、API密钥管理等）不允许使用API密钥调用
+var apiKeyRoutePermissions = map[string][]string{
+	// 仪表盘
+	"GET /api/dashboard/overview":       {"DASHBOARD_VIEW"},
+	"GET /api/dashboard/stock-trend":    {"DASHBOARD_VIEW"},
+	"GET /api/dashboard/category-stock": {"DASHBOARD_VIEW"},
+	"GET /api/dashboard/low-stock":      {"DASHBOARD_VIEW"},
+	"GET /api/dashboard/activities":     {"DASHBOARD_VIEW"},
+
+	// 产品
+	"GET /api/products":              {"PRODUCT_VIEW"},
+	"GET /api/products/:id":          {"PRODUCT_VIEW"},
+	"POST /api/products":             {"PRODUCT_CREATE"},
+	"PUT /api/products/:id":          {"PRODUCT_EDIT"},
+	"DELETE /api/products/:id":       {"PRODUCT_DELETE"},
+	"POST /api/products/:id/restore": {"PRODUCT_DELETE"},
+	"GET /api/products/:id/units":    {"PRODUCT_VIEW"},
+	"PUT /api/products/:id/units":    {"PRODUCT_EDIT"},
+	"GET /api/products/:id/kit":      {"PRODUCT_VIEW"},
+	"PUT /api/products/:id/kit":      {"PRODUCT_EDIT"},
+
+	// 供应商
+	"GET /api/suppliers":              {"BASIC_VIEW"},
+	"GET /api/suppliers/:id":          {"BASIC_VIEW"},
+	"POST /api/suppliers":             {"SUPPLIER_MANAGE"},
+	"PUT /api/suppliers/:id":          {"SUPPLIER_MANAGE"},
+	"DELETE /api/suppliers/:id":       {"SUPPLIER_MANAGE"},
+	"POST /api/suppliers/:id/restore": {"SUPPLIER_MANAGE"},
+
+	// 分类
+	"GET /api/categories":              {"BASIC_VIEW"},
+	"GET /api/categories/tree":         {"BASIC_VIEW"},
+	"GET /api/categories/:id":          {"BASIC_VIEW"},
+	"POST /api/categories":             {"BASIC_MANAGE"},
+	"PUT /api/categories/:id":          {"BASIC_MANAGE"},
+	"DELETE /api/categories/:id":       {"BASIC_MANAGE"},
+	"POST /api/categories/:id/restore": {"BASIC_MANAGE"},
+
+	// 采购，修改接口可审批和下单
+	"GET /api/procurements":              {"PROCUREMENT_VIEW"},
+	"GET /api/procurements/:id":          {"PROCUREMENT_VIEW"},
+	"POST /api/procurements":             {"PROCUREMENT_CREATE"},
+	"PUT /api/procurements/:id":          {"PROCUREMENT_APPROVE", "PROCUREMENT_ORDER"},
+	"DELETE /api/procurements/:id":       {"PROCUREMENT_CREATE"},
+	"POST /api/procurements/:id/restore": {"PROCUREMENT_CREATE"},
+
+	// 入库，修改接口可确认入库
+	"GET /api/inbounds":              {"INBOUND_VIEW"},
+	"GET /api/inbounds/:id":          {"INBOUND_VIEW"},
+	"POST /api/inbounds":             {"INBOUND_CREATE"},
+	"PUT /api/inbounds/:id":          {"INBOUND_CREATE", "INBOUND_APPROVE"},
+	"DELETE /api/inbounds/:id":       {"INBOUND_CREATE"},
+	"POST /api/inbounds/:id/restore": {"INBOUND_CREATE"},
+
+	// 出库，修改接口可审批和发货
+	"GET /api/outbounds":              {"OUTBOUND_VIEW"},
+	"GET /api/outbounds/:id":          {"OUTBOUND_VIEW"},
+	"POST /api/outbounds":             {"OUTBOUND_CREATE"},
+	"PUT /api/outbounds/:id":          {"OUTBOUND_APPROVE", "OUTBOUND_EXECUTE"},
+	"DELETE /api/outbounds/:id":       {"OUTBOUND_CREATE"},
+	"POST /api/outbounds/:id/restore": {"OUTBOUND_CREATE"},
+
+	// 套件组装
+	"GET /api/kit-assemblies":              {"INVENTORY_VIEW"},
+	"GET /api/kit-assemblies/:id":          {"INVENTORY_VIEW"},
+	"POST /api/kit-assemblies":             {"INVENTORY_ADJUST"},
+	"PUT /api/kit-assemblies/:id":          {"INVENTORY_ADJUST"},
+	"DELETE /api/kit-assemblies/:id":       {"INVENTORY_ADJUST"},
+	"POST /api/kit-assemblies/:id/restore": {"INVENTORY_ADJUST"},
+
+	// 库存
+	"GET /api/inventory/stock":     {"INVENTORY_VIEW"},
+	"GET /api/inventory/stock/:id": {"INVENTORY_VIEW"},
+	"GET /api/inventory/logs":      {"INVENTORY_VIEW"},
+
+	// 盘点，完成盘点会调整库存
+	"GET /api/inventory/checks":              {"INVENTORY_CHECK"},
+	"GET /api/inventory/checks/:id":          {"INVENTORY_CHECK"},
+	"POST /api/inventory/checks":             {"INVENTORY_CHECK"},
+	"POST /api/inventory/checks/generate":    {"INVENTORY_CHECK"},
+	"POST /api/inventory/checks/:id/recount": {"INVENTORY_SUPERVISE"},
+	"PUT /api/inventory/checks/:id":          {"INVENTORY_CHECK", "INVENTORY_ADJUST"},
+	"DELETE /api/inventory/checks/:id":       {"INVENTORY_CHECK"},
+	"POST /api/inventory/checks/:id/restore": {"INVENTORY_CHECK"},
+
+	// 循环盘点
+	"POST /api/inventory/abc/classify":     {"INVENTORY_CHECK"},
+	"GET /api/inventory/cycle-count":       {"INVENTORY_VIEW"},
+	"POST /api/inventory/cycle-count/plan": {"INVENTORY_CHECK"},
+
+	// 系统管理
+	"GET /api/users":              {"USER_MANAGE"},
+	"GET /api/users/:id":          {"USER_MANAGE"},
+	"GET /api/departments":        {"BASIC_VIEW"},
+	"GET /api/departments/tree":   {"BASIC_VIEW"},
+	"GET /api/departments/:id":    {"BASIC_VIEW"},
+	"POST /api/departments":       {"DEPARTMENT_MANAGE"},
+	"PUT /api/departments/:id":    {"DEPARTMENT_MANAGE"},
+	"DELETE /api/departments/:id": {"DEPARTMENT_MANAGE"},
+	"GET /api/audit-logs":         {"AUDIT_VIEW"},
+	"GET /api/audit-logs/:id":     {"AUDIT_VIEW"},
+}
+
+// apiKeyRouteAllowed 判断API密钥的权限范围能否调用指定接口，fullPath 为匹配到的路由
+func apiKeyRouteAllowed(method, fullPath string, scopes []string) bool {
+	required, ok := apiKeyRoutePermissions[method+" "+fullPath]
+	if !ok {
+		return false
+	}
+	for _, code := range required {
+		found := false
+		for _, scope := range scopes {
+			if scope == code {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
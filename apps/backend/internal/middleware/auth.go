@@ -7,11 +7,18 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// JWTAuth JWT认证中间件
-func JWTAuth(cfg *config.Config) gin.HandlerFunc {
+// JWTAuth JWT认证中间件，同时支持通过 X-API-Key 请求头使用API密钥认证
+func JWTAuth(cfg *config.Config, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API密钥认证（供ERP、BI等外部系统调用）
+		if key := c.GetHeader(APIKeyHeader); key != "" && db != nil {
+			apiKeyAuth(c, db, key)
+			return
+		}
+
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -45,6 +52,20 @@ func JWTAuth(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已被吊销（登出、禁用账号等）
+		if claims.ID != "" && db != nil {
+			var count int64
+			db.Table("sys_token_denylist").Where("jti = ?", claims.ID).Count(&count)
+			if count > 0 {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    401,
+					"message": "认证令牌已失效",
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		// 将用户信息存入上下文
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
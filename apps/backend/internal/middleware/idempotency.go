@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"easywms/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyHeader 幂等键请求头
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader 标记响应是重放的首次结果
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// 幂等记录状态
+const (
+	idempotencyProcessing = "PROCESSING"
+	idempotencyCompleted  = "COMPLETED"
+)
+
+// idempotencyRecord 幂等请求记录，同一用户的同一幂等键只有一行
+type idempotencyRecord struct {
+	ID           int64     `gorm:"column:id;primaryKey"`
+	UserID       int64     `gorm:"column:user_id"`
+	IdemKey      string    `gorm:"column:idem_key"`
+	Method       string    `gorm:"column:method"`
+	Path         string    `gorm:"column:path"`
+	RequestHash  string    `gorm:"column:request_hash"`
+	Status       string    `gorm:"column:status"`
+	StatusCode   int       `gorm:"column:status_code"`
+	ResponseBody string    `gorm:"column:response_body"`
+	ExpiresAt    time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (idempotencyRecord) TableName() string {
+	return "sys_idempotency_key"
+}
+
+// responseRecorder 在写出响应的同时保留响应体
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等键中间件，用于创建单据和变动库存的接口，须在认证之后执行。
+// 带 Idempotency-Key 的请求首次执行后保存响应，有效期内相同请求直接重放；
+// 首次请求仍在处理时重复提交返回409，同一幂等键用于不同请求返回422。
+// 服务端错误（5xx）不保存，客户端可用同一幂等键重试
+func Idempotency(cfg *config.Config, db *gorm.DB) gin.HandlerFunc {
+	ttl := time.Duration(cfg.Idempotency.TTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 128 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "幂等键长度不能超过128"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "读取请求失败"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := c.GetInt64("userID")
+		now := time.Now()
+		record := idempotencyRecord{
+			UserID:      userID,
+			IdemKey:     key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: requestHash(c.Request.Method, c.Request.URL.Path, body),
+			Status:      idempotencyProcessing,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		// 清理该用户已过期的幂等记录，过期的同名键可重新使用
+		db.Where("user_id = ? AND expires_at < ?", userID, now).Delete(&idempotencyRecord{})
+
+		// 唯一键保证并发的重复请求只有一个能占用幂等键
+		if err := db.Create(&record).Error; err != nil {
+			var existing idempotencyRecord
+			if err := db.Where("user_id = ? AND idem_key = ?", userID, key).First(&existing).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "幂等键处理失败"})
+				c.Abort()
+				return
+			}
+			replayIdempotent(c, existing, record.RequestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// 处理失败或发生panic时释放幂等键，允许客户端重试
+			if !completed {
+				db.Delete(&idempotencyRecord{}, record.ID)
+			}
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		if err := db.Model(&record).Updates(map[string]interface{}{
+			"status":        idempotencyCompleted,
+			"status_code":   c.Writer.Status(),
+			"response_body": recorder.body.String(),
+		}).Error; err != nil {
+			log.Printf("idempotency: save response for key %q: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replayIdempotent 处理已存在的幂等键：重放已完成的响应，拒绝处理中或内容不同的请求
+func replayIdempotent(c *gin.Context, existing idempotencyRecord, hash string) {
+	if existing.RequestHash != hash {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"code": 422, "message": "幂等键已用于其他请求"})
+		c.Abort()
+		return
+	}
+	if existing.Status != idempotencyCompleted {
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "message": "相同请求正在处理中，请稍后重试"})
+		c.Abort()
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(existing.StatusCode, "application/json; charset=utf-8", []byte(existing.ResponseBody))
+	c.Abort()
+}
+
+// requestHash 计算请求指纹，同一幂等键只能对应同一个请求
+func requestHash(method, path string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(method + " " + path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -22,12 +22,13 @@ func Logger() gin.HandlerFunc {
 		statusCode := c.Writer.Status()
 
 		// 记录日志
-		log.Printf("[%s] %s %s %d %v",
+		log.Printf("[%s] %s %s %d %v %s",
 			c.Request.Method,
 			c.Request.RequestURI,
 			c.ClientIP(),
 			statusCode,
 			latency,
+			c.GetString("requestID"),
 		)
 	}
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"easywms/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求ID头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 请求ID中间件：沿用上游传入的请求ID，没有时生成，用于串联日志和审计记录
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id, _ = utils.RandomToken(8)
+		}
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
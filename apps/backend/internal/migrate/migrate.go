@@ -0,0 +1,306 @@
+package migrate
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFS 内嵌的迁移脚本，按数据库方言分目录，文件名格式为 {版本}_{名称}.up.sql / .down.sql
+//
+//go:embed migrations
+var migrationFS embed.FS
+
+// baselineTable 基线版本中的表，用于识别迁移机制引入之前由旧版 init_all.sql 初始化的数据库
+const baselineTable = "sys_user"
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移版本的执行状态
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 数据库迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 按数据库方言加载内嵌的迁移脚本
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest 最新的迁移版本
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status 列出所有迁移版本及执行时间，未执行的 AppliedAt 为空
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if record, ok := applied[mig.Version]; ok {
+			at := record.AppliedAt
+			s.AppliedAt = &at
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// Pending 未执行的迁移，按版本升序
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Check 校验数据库结构是否为最新版本，有未执行的迁移时返回错误
+func (m *Migrator) Check() error {
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		versions := make([]string, len(pending))
+		for i, mig := range pending {
+			versions[i] = fmt.Sprintf("%04d_%s", mig.Version, mig.Name)
+		}
+		return fmt.Errorf("database schema is behind, pending migrations: %s", strings.Join(versions, ", "))
+	}
+	return nil
+}
+
+// Up 依次执行所有未执行的迁移，返回本次执行的迁移。
+// 没有执行记录但基线表已存在时，视为由旧版 init_all.sql 初始化的数据库，只记录基线版本，之后的版本照常执行
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range pending {
+		if mig.Version == m.migrations[0].Version && m.db.Migrator().HasTable(baselineTable) {
+			if err := m.record(m.db, mig); err != nil {
+				return done, err
+			}
+			done = append(done, mig)
+			continue
+		}
+		if err := m.run(mig, mig.Up, true); err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down 回滚最近执行的一个迁移，没有可回滚的版本时返回 nil
+func (m *Migrator) Down() (*Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var last SchemaMigration
+	if err := m.db.Order("version DESC").Limit(1).Find(&last).Error; err != nil {
+		return nil, err
+	}
+	if last.Version == 0 {
+		return nil, nil
+	}
+
+	for _, mig := range m.migrations {
+		if mig.Version == last.Version {
+			if err := m.run(mig, mig.Down, false); err != nil {
+				return nil, err
+			}
+			return &mig, nil
+		}
+	}
+	return nil, fmt.Errorf("migration %d is applied but not found in this binary", last.Version)
+}
+
+// run 在同一连接上执行迁移脚本并更新迁移记录。
+// MySQL 的 DDL 会隐式提交，失败时已执行的语句无法回滚，需要按报错手工处理
+func (m *Migrator) run(mig Migration, script string, up bool) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		if up {
+			return m.record(tx, mig)
+		}
+		return tx.Delete(&SchemaMigration{}, mig.Version).Error
+	})
+}
+
+// record 记录迁移已执行
+func (m *Migrator) record(db *gorm.DB, mig Migration) error {
+	return db.Create(&SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+}
+
+// ensureTable 创建迁移记录表
+func (m *Migrator) ensureTable() error {
+	if m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	return m.db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+// applied 读取已执行的迁移，迁移表不存在时视为没有执行过
+func (m *Migrator) applied() (map[int64]SchemaMigration, error) {
+	result := make(map[int64]SchemaMigration)
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return result, nil
+	}
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// load 读取指定方言的迁移脚本，每个版本必须同时有 up 和 down 脚本
+func load(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database %q", dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", name)
+		}
+
+		data, err := migrationFS.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = mig
+		} else if mig.Name != parts[1] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, parts[1])
+		}
+		if direction == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down scripts", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements 按行尾分号拆分脚本中的语句，忽略整行注释和空行
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,181 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"easywms/internal/config"
+	"easywms/internal/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.InitDB(&config.Config{
+		Database: config.DatabaseConfig{Driver: database.DriverSQLite, File: ":memory:"},
+	})
+	if err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func newMigrator(t *testing.T, db *gorm.DB) *Migrator {
+	t.Helper()
+	m, err := New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	return m
+}
+
+// schema 数据库中每张表的列和索引，用于比较两个库的结构
+func schema(t *testing.T, db *gorm.DB) map[string]string {
+	t.Helper()
+	var tables []string
+	db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables)
+
+	result := make(map[string]string)
+	for _, table := range tables {
+		var columns []struct {
+			Name    string
+			Type    string
+			NotNull int
+			Dflt    *string `gorm:"column:dflt_value"`
+		}
+		if err := db.Raw("SELECT name, type, `notnull` AS not_null, dflt_value FROM pragma_table_info(?)", table).Scan(&columns).Error; err != nil {
+			t.Fatalf("columns of %s: %v", table, err)
+		}
+		var parts []string
+		for _, c := range columns {
+			dflt := "-"
+			if c.Dflt != nil {
+				dflt = *c.Dflt
+			}
+			parts = append(parts, fmt.Sprintf("%s %s notnull=%d default=%s", c.Name, c.Type, c.NotNull, dflt))
+		}
+		var indexes []string
+		db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", table).Scan(&indexes)
+		parts = append(parts, indexes...)
+		sort.Strings(parts)
+		result[table] = strings.Join(parts, "; ")
+	}
+	return result
+}
+
+func TestUpUpgradesDatabaseFromOldSchema(t *testing.T) {
+	// 旧版 init_all.sql 建出的库：只有基线版本的表和数据，没有迁移记录
+	old := openSQLite(t)
+	m := newMigrator(t, old)
+	for _, stmt := range splitStatements(m.migrations[0].Up) {
+		if err := old.Exec(stmt).Error; err != nil {
+			t.Fatalf("old schema: %v", err)
+		}
+	}
+	legacy := []string{
+		"INSERT INTO sys_user (username, password, real_name, dept_id, role_code, status) VALUES ('keeper', 'x', '仓管员', 1, 'W_MGR', 1)",
+		"INSERT INTO base_product (id, category_id, sku_code, name, unit, stock_qty) VALUES (1, 1, 'SKU-1', '螺栓', '个', 5)",
+		"INSERT INTO biz_outbound (outbound_no, applicant_id, dept_id) VALUES ('OUT-0001', 1, 1)",
+		"INSERT INTO biz_outbound_item (outbound_id, product_id, apply_qty) VALUES (1, 1, 2)",
+		"INSERT INTO biz_inventory_check (check_no, check_date) VALUES ('CHK-0001', '2026-01-01')",
+	}
+	for _, stmt := range legacy {
+		if err := old.Exec(stmt).Error; err != nil {
+			t.Fatalf("legacy data: %v", err)
+		}
+	}
+
+	done, err := m.Up()
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(done) != len(m.migrations) {
+		t.Errorf("applied %d migrations, want %d", len(done), len(m.migrations))
+	}
+	if err := m.Check(); err != nil {
+		t.Errorf("check after upgrade: %v", err)
+	}
+
+	fresh := openSQLite(t)
+	if _, err := newMigrator(t, fresh).Up(); err != nil {
+		t.Fatalf("fresh up: %v", err)
+	}
+	got, want := schema(t, old), schema(t, fresh)
+	for table, columns := range want {
+		if got[table] != columns {
+			t.Errorf("table %s after upgrade:\n got  %s\n want %s", table, got[table], columns)
+		}
+	}
+	for table := range got {
+		if _, ok := want[table]; !ok {
+			t.Errorf("unexpected table %s after upgrade", table)
+		}
+	}
+
+	// 原有数据保留，新增列取默认值；已有账号不会被要求立即修改密码
+	var user struct {
+		Email             string
+		PasswordChangedAt *string
+	}
+	old.Raw("SELECT email, password_changed_at FROM sys_user WHERE username = 'keeper'").Scan(&user)
+	if user.PasswordChangedAt == nil || user.Email != "" {
+		t.Errorf("legacy user = %+v", user)
+	}
+	var counts struct {
+		Products int64
+		Items    int64
+		Checks   int64
+		Perms    int64
+	}
+	old.Raw(`SELECT
+		(SELECT COUNT(*) FROM base_product WHERE deleted_at IS NULL AND stock_qty = 5) AS products,
+		(SELECT COUNT(*) FROM biz_outbound_item WHERE kit_id IS NULL AND apply_qty = 2) AS items,
+		(SELECT COUNT(*) FROM biz_inventory_check WHERE round = 1 AND freeze = 0) AS checks,
+		(SELECT COUNT(*) FROM sys_role_permission WHERE role_code = 'W_MGR' AND permission_code = 'INVENTORY_SUPERVISE') AS perms`).
+		Scan(&counts)
+	if counts.Products != 1 || counts.Items != 1 || counts.Checks != 1 || counts.Perms != 1 {
+		t.Errorf("legacy data after upgrade = %+v", counts)
+	}
+}
+
+func TestDownRevertsEveryVersion(t *testing.T) {
+	db := openSQLite(t)
+	m := newMigrator(t, db)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig, err := m.Down()
+		if err != nil {
+			t.Fatalf("down: %v", err)
+		}
+		if mig == nil || mig.Version != m.migrations[i].Version {
+			t.Fatalf("down reverted %v, want version %d", mig, m.migrations[i].Version)
+		}
+	}
+	if mig, err := m.Down(); mig != nil || err != nil {
+		t.Errorf("down on empty database = %v, %v", mig, err)
+	}
+
+	var tables []string
+	db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables)
+	if len(tables) != 1 || tables[0] != "schema_migrations" {
+		t.Errorf("tables left after down: %v", tables)
+	}
+
+	// 全部回滚后可以重新升级
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up again: %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+-- 基线版本：迁移机制引入前 db/init_all.sql 的表结构和权限数据，
+-- 由旧版脚本初始化的数据库直接记录为已执行。之后的结构变化见 0002 起的版本
+
+-- =============================================
+-- 表结构
+-- =============================================
+
+-- 1. 部门表
+CREATE TABLE `base_department` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '部门ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '部门名称',
+  `parent_id` BIGINT NOT NULL DEFAULT 0 COMMENT '父部门ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='部门表';
+
+-- 2. 系统用户表
+CREATE TABLE `sys_user` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '用户ID',
+  `username` VARCHAR(64) NOT NULL COMMENT '登录账号',
+  `password` VARCHAR(128) NOT NULL COMMENT '登录密码(BCrypt)',
+  `real_name` VARCHAR(64) NOT NULL COMMENT '真实姓名',
+  `dept_id` BIGINT NOT NULL COMMENT '部门ID',
+  `role_code` VARCHAR(20) NOT NULL COMMENT '角色: ADMIN/W_MGR/BUYER/STAFF',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-禁用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_username` (`username`),
+  KEY `idx_dept_id` (`dept_id`),
+  KEY `idx_role_code` (`role_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系统用户表';
+
+-- 3. 权限码表
+CREATE TABLE `sys_permission` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `code` VARCHAR(50) NOT NULL COMMENT '权限码',
+  `name` VARCHAR(100) NOT NULL COMMENT '权限名称',
+  `description` VARCHAR(255) DEFAULT NULL COMMENT '描述',
+  `module` VARCHAR(50) DEFAULT NULL COMMENT '所属模块',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限码表';
+
+-- 4. 角色权限关联表
+CREATE TABLE `sys_role_permission` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `role_code` VARCHAR(20) NOT NULL COMMENT '角色代码',
+  `permission_code` VARCHAR(50) NOT NULL COMMENT '权限码',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_role_perm` (`role_code`, `permission_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色权限关联表';
+
+-- 5. 供应商表
+CREATE TABLE `base_supplier` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '供应商ID',
+  `name` VARCHAR(128) NOT NULL COMMENT '供应商名称',
+  `contact` VARCHAR(32) DEFAULT NULL COMMENT '联系人',
+  `phone` VARCHAR(20) DEFAULT NULL COMMENT '联系电话',
+  `address` VARCHAR(255) DEFAULT NULL COMMENT '地址',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='供应商表';
+
+-- 6. 物资分类表
+CREATE TABLE `base_category` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '分类ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '分类名称',
+  `parent_id` BIGINT NOT NULL DEFAULT 0 COMMENT '父分类ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='物资分类表';
+
+-- 7. 物资档案表
+CREATE TABLE `base_product` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '物资ID',
+  `category_id` BIGINT NOT NULL COMMENT '所属分类ID',
+  `sku_code` VARCHAR(64) NOT NULL COMMENT 'SKU编码',
+  `name` VARCHAR(128) NOT NULL COMMENT '物资名称',
+  `specification` VARCHAR(128) DEFAULT NULL COMMENT '规格型号',
+  `unit` VARCHAR(20) NOT NULL COMMENT '计量单位',
+  `stock_qty` DECIMAL(14,4) NOT NULL DEFAULT 0.0000 COMMENT '实时库存',
+  `alert_threshold` DECIMAL(14,4) NOT NULL DEFAULT 0.0000 COMMENT '预警阈值',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_sku_code` (`sku_code`),
+  KEY `idx_category_id` (`category_id`),
+  KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='物资档案表';
+
+-- 8. 采购订单主表
+CREATE TABLE `biz_procurement` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '订单ID',
+  `order_no` VARCHAR(32) NOT NULL COMMENT '采购单号',
+  `applicant_id` BIGINT NOT NULL COMMENT '申请人ID',
+  `supplier_id` BIGINT DEFAULT NULL COMMENT '供应商ID',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/APPROVED/ORDERED/DONE',
+  `reason` TEXT COMMENT '申请原因',
+  `expected_date` DATE DEFAULT NULL COMMENT '预计到货日期',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_order_no` (`order_no`),
+  KEY `idx_applicant_id` (`applicant_id`),
+  KEY `idx_supplier_id` (`supplier_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='采购订单主表';
+
+-- 9. 采购明细表
+CREATE TABLE `biz_procurement_item` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
+  `procurement_id` BIGINT NOT NULL COMMENT '采购单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `plan_qty` DECIMAL(14,4) NOT NULL COMMENT '计划数量',
+  `unit_price` DECIMAL(14,2) DEFAULT NULL COMMENT '单价',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_procurement_id` (`procurement_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='采购明细表';
+
+-- 10. 入库单主表
+CREATE TABLE `biz_inbound` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '入库ID',
+  `inbound_no` VARCHAR(32) NOT NULL COMMENT '入库单号',
+  `source_id` BIGINT DEFAULT NULL COMMENT '来源采购单ID',
+  `is_temporary` TINYINT NOT NULL DEFAULT 0 COMMENT '1-暂估 0-正常',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '1-已完成 0-草稿',
+  `inbound_date` DATETIME DEFAULT NULL COMMENT '入库时间',
+  `warehouse_user_id` BIGINT DEFAULT NULL COMMENT '仓管员ID',
+  `remark` TEXT COMMENT '备注',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_inbound_no` (`inbound_no`),
+  KEY `idx_source_id` (`source_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='入库单主表';
+
+-- 11. 入库明细表
+CREATE TABLE `biz_inbound_item` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
+  `inbound_id` BIGINT NOT NULL COMMENT '入库单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实收数量',
+  `location` VARCHAR(64) DEFAULT NULL COMMENT '库位',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_inbound_id` (`inbound_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='入库明细表';
+
+-- 12. 出库主表
+CREATE TABLE `biz_outbound` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '出库ID',
+  `outbound_no` VARCHAR(32) NOT NULL COMMENT '出库单号',
+  `applicant_id` BIGINT NOT NULL COMMENT '申请人ID',
+  `dept_id` BIGINT NOT NULL COMMENT '领用部门ID',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/APPROVED/DONE/REJECT',
+  `purpose` TEXT COMMENT '用途',
+  `reviewer_id` BIGINT DEFAULT NULL COMMENT '审核人ID',
+  `review_time` DATETIME DEFAULT NULL COMMENT '审核时间',
+  `outbound_date` DATETIME DEFAULT NULL COMMENT '出库时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_outbound_no` (`outbound_no`),
+  KEY `idx_applicant_id` (`applicant_id`),
+  KEY `idx_dept_id` (`dept_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='出库主表';
+
+-- 13. 领用明细表
+CREATE TABLE `biz_outbound_item` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
+  `outbound_id` BIGINT NOT NULL COMMENT '出库单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `apply_qty` DECIMAL(14,4) NOT NULL COMMENT '申请数量',
+  `actual_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '实发数量',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_outbound_id` (`outbound_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='领用明细表';
+
+-- 14. 库存流水表
+CREATE TABLE `biz_stock_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '日志ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `type` VARCHAR(10) NOT NULL COMMENT 'IN/OUT/ADJUST',
+  `change_qty` DECIMAL(14,4) NOT NULL COMMENT '变动数量',
+  `snapshot_qty` DECIMAL(14,4) NOT NULL COMMENT '变动后库存',
+  `related_no` VARCHAR(32) DEFAULT NULL COMMENT '关联单号',
+  `operator_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_type` (`type`),
+  KEY `idx_related_no` (`related_no`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='库存流水表';
+
+-- 15. 盘点主表
+CREATE TABLE `biz_inventory_check` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '盘点ID',
+  `check_no` VARCHAR(32) NOT NULL COMMENT '盘点单号',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'CHECKING' COMMENT 'CHECKING/FINISHED',
+  `check_date` DATE NOT NULL COMMENT '盘点日期',
+  `checker_id` BIGINT DEFAULT NULL COMMENT '盘点人ID',
+  `remark` TEXT COMMENT '备注',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_check_no` (`check_no`),
+  KEY `idx_status` (`status`),
+  KEY `idx_check_date` (`check_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点主表';
+
+-- 16. 盘点差异表
+CREATE TABLE `biz_inventory_check_item` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
+  `check_id` BIGINT NOT NULL COMMENT '盘点单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `book_qty` DECIMAL(14,4) NOT NULL COMMENT '账面数量',
+  `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实盘数量',
+  `diff_qty` DECIMAL(14,4) NOT NULL COMMENT '盈亏数量',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_check_id` (`check_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点差异表';
+
+-- =============================================
+-- 权限数据
+-- =============================================
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('BASIC_VIEW', '基础数据查看', '查看物资档案、供应商等', 'basic'),
+('BASIC_MANAGE', '基础数据管理', '新增、修改、删除基础数据', 'basic'),
+('PRODUCT_VIEW', '产品查看', '查看产品列表', 'product'),
+('PRODUCT_CREATE', '产品新增', '新增产品', 'product'),
+('PRODUCT_EDIT', '产品编辑', '编辑产品信息', 'product'),
+('PRODUCT_DELETE', '产品删除', '删除产品', 'product'),
+('SUPPLIER_MANAGE', '供应商管理', '管理供应商档案', 'supplier'),
+('DEPARTMENT_MANAGE', '部门管理', '管理部门架构', 'department'),
+('USER_MANAGE', '用户管理', '管理系统用户', 'user'),
+('INIT_STOCK', '期初库存录入', '录入期初库存', 'stock'),
+('PROCUREMENT_VIEW', '采购单查看', '查看采购申请列表', 'procurement'),
+('PROCUREMENT_CREATE', '采购申请', '发起采购申请', 'procurement'),
+('PROCUREMENT_APPROVE', '采购审批', '审批采购申请', 'procurement'),
+('PROCUREMENT_ORDER', '生成订单', '将批准的申请转为订单', 'procurement'),
+('INBOUND_VIEW', '入库单查看', '查看入库单列表', 'inbound'),
+('INBOUND_CREATE', '入库操作', '执行入库操作', 'inbound'),
+('INBOUND_APPROVE', '入库审核', '审核入库单', 'inbound'),
+('OUTBOUND_VIEW', '出库单查看', '查看出库单列表', 'outbound'),
+('OUTBOUND_CREATE', '领用申请', '发起物资领用申请', 'outbound'),
+('OUTBOUND_APPROVE', '出库审核', '审核出库申请', 'outbound'),
+('OUTBOUND_EXECUTE', '出库执行', '执行出库操作', 'outbound'),
+('INVENTORY_VIEW', '库存查看', '查看库存信息', 'inventory'),
+('INVENTORY_CHECK', '库存盘点', '执行库存盘点', 'inventory'),
+('INVENTORY_ADJUST', '库存调整', '调整库存数量', 'inventory'),
+('REPORT_VIEW', '报表查看', '查看统计报表', 'report'),
+('DASHBOARD_VIEW', '仪表盘查看', '查看仪表盘数据', 'dashboard');
+
+-- ADMIN (系统管理员) - 全部权限
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'BASIC_VIEW'), ('ADMIN', 'BASIC_MANAGE'), ('ADMIN', 'PRODUCT_VIEW'), ('ADMIN', 'PRODUCT_CREATE'),
+('ADMIN', 'PRODUCT_EDIT'), ('ADMIN', 'PRODUCT_DELETE'), ('ADMIN', 'SUPPLIER_MANAGE'), ('ADMIN', 'DEPARTMENT_MANAGE'),
+('ADMIN', 'USER_MANAGE'), ('ADMIN', 'INIT_STOCK'), ('ADMIN', 'PROCUREMENT_VIEW'), ('ADMIN', 'PROCUREMENT_CREATE'),
+('ADMIN', 'PROCUREMENT_APPROVE'), ('ADMIN', 'PROCUREMENT_ORDER'), ('ADMIN', 'INBOUND_VIEW'), ('ADMIN', 'INBOUND_CREATE'),
+('ADMIN', 'INBOUND_APPROVE'), ('ADMIN', 'OUTBOUND_VIEW'), ('ADMIN', 'OUTBOUND_CREATE'), ('ADMIN', 'OUTBOUND_APPROVE'),
+('ADMIN', 'OUTBOUND_EXECUTE'), ('ADMIN', 'INVENTORY_VIEW'), ('ADMIN', 'INVENTORY_CHECK'), ('ADMIN', 'INVENTORY_ADJUST'),
+('ADMIN', 'REPORT_VIEW'), ('ADMIN', 'DASHBOARD_VIEW');
+
+-- BUYER (采购专员)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('BUYER', 'BASIC_VIEW'), ('BUYER', 'PRODUCT_VIEW'), ('BUYER', 'SUPPLIER_MANAGE'),
+('BUYER', 'PROCUREMENT_VIEW'), ('BUYER', 'PROCUREMENT_CREATE'), ('BUYER', 'PROCUREMENT_ORDER'),
+('BUYER', 'INVENTORY_VIEW'), ('BUYER', 'DASHBOARD_VIEW');
+
+-- W_MGR (仓库管理员)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('W_MGR', 'BASIC_VIEW'), ('W_MGR', 'PRODUCT_VIEW'), ('W_MGR', 'PRODUCT_CREATE'), ('W_MGR', 'PRODUCT_EDIT'),
+('W_MGR', 'INIT_STOCK'), ('W_MGR', 'INBOUND_VIEW'), ('W_MGR', 'INBOUND_CREATE'), ('W_MGR', 'INBOUND_APPROVE'),
+('W_MGR', 'OUTBOUND_VIEW'), ('W_MGR', 'OUTBOUND_APPROVE'), ('W_MGR', 'OUTBOUND_EXECUTE'),
+('W_MGR', 'INVENTORY_VIEW'), ('W_MGR', 'INVENTORY_CHECK'), ('W_MGR', 'INVENTORY_ADJUST'), ('W_MGR', 'DASHBOARD_VIEW');
+
+-- STAFF (部门员工)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('STAFF', 'BASIC_VIEW'), ('STAFF', 'PRODUCT_VIEW'), ('STAFF', 'OUTBOUND_VIEW'),
+('STAFF', 'OUTBOUND_CREATE'), ('STAFF', 'INVENTORY_VIEW'), ('STAFF', 'DASHBOARD_VIEW');
+
+-- =============================================
+-- 初始管理员账号 (admin / 123456)
+-- =============================================
+INSERT INTO `base_department` (`id`, `name`, `parent_id`) VALUES
+(1, '总经办', 0);
+
+INSERT INTO `sys_user` (`id`, `username`, `password`, `real_name`, `dept_id`, `role_code`, `status`) VALUES
+(1, 'admin', '$2a$10$N.zmdr9k7uOCQb376NoUnuTJ8iAt6Z5EHsM8lE9lBOsl7iKTVKIUi', '系统管理员', 1, 'ADMIN', 1);
@@ -0,0 +1,18 @@
+DELETE FROM `sys_role_permission` WHERE `permission_code` IN ('INVENTORY_SUPERVISE');
+DELETE FROM `sys_permission` WHERE `code` IN ('INVENTORY_SUPERVISE');
+
+DROP TABLE IF EXISTS `biz_inventory_check_count`;
+
+ALTER TABLE `base_product` DROP COLUMN `abc_class`;
+
+ALTER TABLE `biz_inventory_check_item`
+  DROP COLUMN `need_recount`,
+  DROP COLUMN `round`,
+  DROP COLUMN `counted`;
+
+ALTER TABLE `biz_inventory_check`
+  DROP COLUMN `cycle_class`,
+  DROP COLUMN `tolerance`,
+  DROP COLUMN `round`,
+  DROP COLUMN `snapshot_at`,
+  DROP COLUMN `freeze`;
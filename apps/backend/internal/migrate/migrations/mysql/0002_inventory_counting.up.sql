@@ -0,0 +1,35 @@
+-- 盘点账面快照与冻结、盲盘计数和复盘轮次、ABC分类循环盘点
+
+ALTER TABLE `biz_inventory_check`
+  ADD COLUMN `freeze` TINYINT NOT NULL DEFAULT 0 COMMENT '1-盘点期间冻结出入库 0-不冻结' AFTER `checker_id`,
+  ADD COLUMN `snapshot_at` DATETIME DEFAULT NULL COMMENT '账面快照时间' AFTER `freeze`,
+  ADD COLUMN `round` INT NOT NULL DEFAULT 1 COMMENT '当前盘点轮次' AFTER `snapshot_at`,
+  ADD COLUMN `tolerance` DECIMAL(14,4) DEFAULT NULL COMMENT '差异容差，超出需复盘；为空不要求复盘' AFTER `round`,
+  ADD COLUMN `cycle_class` CHAR(1) DEFAULT NULL COMMENT '循环盘点生成时的ABC分类' AFTER `tolerance`;
+
+ALTER TABLE `biz_inventory_check_item`
+  ADD COLUMN `counted` TINYINT NOT NULL DEFAULT 1 COMMENT '1-本轮已盘 0-待盘' AFTER `diff_qty`,
+  ADD COLUMN `round` INT NOT NULL DEFAULT 1 COMMENT '最近一次盘点的轮次' AFTER `counted`,
+  ADD COLUMN `need_recount` TINYINT NOT NULL DEFAULT 0 COMMENT '1-差异超出容差需复盘' AFTER `round`;
+
+ALTER TABLE `base_product`
+  ADD COLUMN `abc_class` CHAR(1) DEFAULT NULL COMMENT 'ABC分类: A/B/C' AFTER `alert_threshold`;
+
+-- 盘点计数记录表
+CREATE TABLE `biz_inventory_check_count` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `check_id` BIGINT NOT NULL COMMENT '盘点单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `round` INT NOT NULL COMMENT '盘点轮次',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '计数数量',
+  `counter_id` BIGINT DEFAULT NULL COMMENT '盘点人ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_check_product` (`check_id`, `product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点计数记录表';
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('INVENTORY_SUPERVISE', '盘点监盘', '查看盘点账面数量、发起复盘', 'inventory');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'INVENTORY_SUPERVISE'), ('W_MGR', 'INVENTORY_SUPERVISE');
@@ -0,0 +1,23 @@
+ALTER TABLE `biz_stock_log`
+  MODIFY COLUMN `type` VARCHAR(10) NOT NULL COMMENT 'IN/OUT/ADJUST';
+
+ALTER TABLE `biz_outbound_item`
+  DROP COLUMN `kit_id`,
+  DROP COLUMN `unit_qty`,
+  DROP COLUMN `unit`,
+  MODIFY COLUMN `apply_qty` DECIMAL(14,4) NOT NULL COMMENT '申请数量';
+
+ALTER TABLE `biz_inbound_item`
+  DROP COLUMN `unit_qty`,
+  DROP COLUMN `unit`,
+  MODIFY COLUMN `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实收数量';
+
+ALTER TABLE `biz_procurement_item`
+  DROP COLUMN `unit_qty`,
+  DROP COLUMN `unit`,
+  MODIFY COLUMN `unit_price` DECIMAL(14,2) DEFAULT NULL COMMENT '单价',
+  MODIFY COLUMN `plan_qty` DECIMAL(14,4) NOT NULL COMMENT '计划数量';
+
+DROP TABLE IF EXISTS `base_product_unit`;
+DROP TABLE IF EXISTS `biz_kit_assembly`;
+DROP TABLE IF EXISTS `base_product_kit`;
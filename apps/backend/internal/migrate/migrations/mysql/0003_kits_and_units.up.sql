@@ -0,0 +1,64 @@
+-- 套件组成与组装单、产品辅助计量单位，单据明细记录录入单位和数量
+
+-- 套件组成表
+CREATE TABLE `base_product_kit` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `kit_id` BIGINT NOT NULL COMMENT '套件物资ID',
+  `product_id` BIGINT NOT NULL COMMENT '组件物资ID',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '每套数量',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_kit_product` (`kit_id`, `product_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='套件组成表';
+
+-- 套件组装单表
+CREATE TABLE `biz_kit_assembly` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '组装单ID',
+  `assembly_no` VARCHAR(64) NOT NULL COMMENT '组装单号',
+  `kit_id` BIGINT NOT NULL COMMENT '套件物资ID',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '组装数量',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/DONE/CANCELLED',
+  `operator_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
+  `assembled_at` DATETIME DEFAULT NULL COMMENT '组装完成时间',
+  `remark` TEXT COMMENT '备注',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_assembly_no` (`assembly_no`),
+  KEY `idx_kit_id` (`kit_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='套件组装单表';
+
+-- 产品辅助计量单位表
+CREATE TABLE `base_product_unit` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `unit` VARCHAR(20) NOT NULL COMMENT '辅助单位',
+  `factor` DECIMAL(14,4) NOT NULL COMMENT '换算系数（1辅助单位=多少基本单位）',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_product_unit` (`product_id`, `unit`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='产品辅助计量单位表';
+
+ALTER TABLE `biz_procurement_item`
+  MODIFY COLUMN `plan_qty` DECIMAL(14,4) NOT NULL COMMENT '计划数量（基本单位）',
+  MODIFY COLUMN `unit_price` DECIMAL(14,4) DEFAULT NULL COMMENT '单价（基本单位）',
+  ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位' AFTER `unit_price`,
+  ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量' AFTER `unit`;
+
+ALTER TABLE `biz_inbound_item`
+  MODIFY COLUMN `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实收数量（基本单位）',
+  ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位' AFTER `actual_qty`,
+  ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量' AFTER `unit`;
+
+ALTER TABLE `biz_outbound_item`
+  MODIFY COLUMN `apply_qty` DECIMAL(14,4) NOT NULL COMMENT '申请数量（基本单位）',
+  ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位' AFTER `actual_qty`,
+  ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量' AFTER `unit`,
+  ADD COLUMN `kit_id` BIGINT DEFAULT NULL COMMENT '来源套件ID（按套件领用展开）' AFTER `unit_qty`;
+
+ALTER TABLE `biz_stock_log`
+  MODIFY COLUMN `type` VARCHAR(10) NOT NULL COMMENT 'IN/OUT/ADJUST/KIT_IN/KIT_OUT';
@@ -0,0 +1,66 @@
+-- 角色和菜单存入数据库，内置角色沿用原有角色代码
+
+-- 角色表
+CREATE TABLE `sys_role` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '角色ID',
+  `code` VARCHAR(20) NOT NULL COMMENT '角色代码',
+  `name` VARCHAR(64) NOT NULL COMMENT '角色名称',
+  `description` VARCHAR(255) DEFAULT NULL COMMENT '描述',
+  `builtin` TINYINT NOT NULL DEFAULT 0 COMMENT '1-内置角色(不可删除) 0-自定义角色',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `data_scope` VARCHAR(10) NOT NULL DEFAULT 'SELF' COMMENT '数据权限: ALL-全部 DEPT-本部门及下级 SELF-仅本人',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色表';
+
+-- 菜单表
+CREATE TABLE `sys_menu` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '菜单ID',
+  `parent_id` BIGINT NOT NULL DEFAULT 0 COMMENT '上级菜单ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '路由名称',
+  `path` VARCHAR(128) NOT NULL COMMENT '路由路径',
+  `component` VARCHAR(255) DEFAULT NULL COMMENT '页面组件，目录为空',
+  `title` VARCHAR(64) NOT NULL COMMENT '菜单标题',
+  `icon` VARCHAR(64) DEFAULT NULL COMMENT '图标',
+  `sort_order` INT NOT NULL DEFAULT 0 COMMENT '排序',
+  `affix_tab` TINYINT NOT NULL DEFAULT 0 COMMENT '1-固定标签页',
+  `permission_code` VARCHAR(50) DEFAULT NULL COMMENT '所需权限码，为空表示所有登录用户可见',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`),
+  KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='菜单表';
+
+INSERT INTO `sys_role` (`code`, `name`, `description`, `builtin`, `data_scope`) VALUES
+('ADMIN', '系统管理员', '系统配置、基础数据管理、采购审批', 1, 'ALL'),
+('BUYER', '采购专员', '供应商管理、采购申请、订单生成', 1, 'ALL'),
+('W_MGR', '仓库管理员', '入库验收、出库审核、库存盘点', 1, 'ALL'),
+('STAFF', '部门员工', '库存查询、物资领用申请', 1, 'DEPT');
+
+-- 菜单（按权限码过滤可见性）
+INSERT INTO `sys_menu` (`id`, `parent_id`, `name`, `path`, `component`, `title`, `icon`, `sort_order`, `affix_tab`, `permission_code`) VALUES
+(1, 0, 'Dashboard', '/dashboard', NULL, '概览', 'lucide:layout-dashboard', -1, 0, NULL),
+(2, 1, 'Analytics', '/analytics', '#/views/dashboard/analytics/index.vue', '分析页', 'lucide:area-chart', 0, 1, NULL),
+(3, 1, 'Workspace', '/workspace', '#/views/dashboard/workspace/index.vue', '工作台', 'carbon:workspace', 0, 0, NULL),
+(4, 0, 'WmsBasicData', '/wms/basic', NULL, '基础数据', 'mdi:package-variant-closed', 10, 0, NULL),
+(5, 4, 'WmsProduct', '/wms/basic/product', '#/views/wms/product/list.vue', '产品管理', 'mdi:package-variant', 0, 0, 'PRODUCT_VIEW'),
+(6, 0, 'WmsProcurement', '/wms/procurement', NULL, '采购管理', 'mdi:cart-outline', 20, 0, NULL),
+(7, 6, 'WmsProcurementList', '/wms/procurement/list', '#/views/wms/procurement/list.vue', '采购单列表', 'mdi:clipboard-list-outline', 0, 0, 'PROCUREMENT_VIEW'),
+(8, 0, 'WmsInbound', '/wms/inbound', NULL, '入库管理', 'mdi:package-down', 30, 0, NULL),
+(9, 8, 'WmsInboundList', '/wms/inbound/list', '#/views/wms/inbound/list.vue', '入库单列表', 'mdi:clipboard-arrow-down-outline', 0, 0, 'INBOUND_VIEW'),
+(10, 0, 'WmsOutbound', '/wms/outbound', NULL, '出库管理', 'mdi:package-up', 40, 0, NULL),
+(11, 10, 'WmsOutboundList', '/wms/outbound/list', '#/views/wms/outbound/list.vue', '出库单列表', 'mdi:clipboard-arrow-up-outline', 0, 0, 'OUTBOUND_VIEW'),
+(12, 0, 'WmsInventory', '/wms/inventory', NULL, '库存管理', 'mdi:warehouse', 50, 0, NULL),
+(13, 12, 'WmsInventoryStock', '/wms/inventory/stock', '#/views/wms/inventory/stock/list.vue', '库存查询', 'mdi:cube-outline', 0, 0, 'INVENTORY_VIEW'),
+(14, 12, 'WmsInventoryCheck', '/wms/inventory/check', '#/views/wms/inventory/check/list.vue', '库存盘点', 'mdi:clipboard-check-outline', 1, 0, 'INVENTORY_CHECK');
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('ROLE_MANAGE', '角色管理', '管理角色及角色权限', 'user'),
+('MENU_MANAGE', '菜单管理', '维护菜单及排序', 'user');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'ROLE_MANAGE'), ('ADMIN', 'MENU_MANAGE');
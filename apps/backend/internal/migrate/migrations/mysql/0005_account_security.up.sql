@@ -0,0 +1,173 @@
+-- 刷新令牌、登录锁定与日志、两步验证、密码策略、API密钥和外部身份登录
+
+ALTER TABLE `sys_user`
+  ADD COLUMN `email` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '邮箱（单点登录按邮箱关联账号）' AFTER `real_name`,
+  ADD COLUMN `password_changed_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '密码修改时间，为空须在下次登录时修改' AFTER `status`,
+  ADD KEY `idx_email` (`email`);
+
+-- 刷新令牌表
+CREATE TABLE `sys_refresh_token` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `token_hash` CHAR(64) NOT NULL COMMENT '刷新令牌SHA-256摘要',
+  `access_jti` VARCHAR(64) DEFAULT NULL COMMENT '同批签发的访问令牌jti',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `revoked_at` DATETIME DEFAULT NULL COMMENT '吊销/轮换时间',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '登录IP',
+  `user_agent` VARCHAR(255) DEFAULT NULL COMMENT '客户端UA',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='刷新令牌表';
+
+-- 访问令牌黑名单表
+CREATE TABLE `sys_token_denylist` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `jti` VARCHAR(64) NOT NULL COMMENT '访问令牌jti',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `expires_at` DATETIME NOT NULL COMMENT '令牌过期时间，过期后可清理',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_jti` (`jti`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='访问令牌黑名单表';
+
+-- 登录锁定表
+CREATE TABLE `sys_login_lock` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `subject_type` VARCHAR(16) NOT NULL COMMENT 'USERNAME/IP',
+  `subject` VARCHAR(64) NOT NULL COMMENT '账号或IP',
+  `failures` INT NOT NULL DEFAULT 0 COMMENT '窗口期内连续失败次数',
+  `lock_count` INT NOT NULL DEFAULT 0 COMMENT '累计锁定次数，用于指数退避',
+  `locked_until` DATETIME DEFAULT NULL COMMENT '锁定截止时间',
+  `last_failed_at` DATETIME DEFAULT NULL COMMENT '最近失败时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_subject` (`subject_type`, `subject`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录锁定表';
+
+-- 登录日志表
+CREATE TABLE `sys_login_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT DEFAULT NULL COMMENT '用户ID（账号不存在时为空）',
+  `username` VARCHAR(64) NOT NULL COMMENT '登录账号',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `user_agent` VARCHAR(255) DEFAULT NULL COMMENT '客户端UA',
+  `success` TINYINT NOT NULL DEFAULT 0 COMMENT '1-成功 0-失败',
+  `message` VARCHAR(255) DEFAULT NULL COMMENT '结果说明',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_username` (`username`),
+  KEY `idx_ip` (`ip`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录日志表';
+
+-- 两步验证表
+CREATE TABLE `sys_user_mfa` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `secret` VARCHAR(64) NOT NULL COMMENT 'TOTP密钥（Base32）',
+  `enabled` TINYINT NOT NULL DEFAULT 0 COMMENT '1-已启用 0-待确认',
+  `confirmed_at` DATETIME DEFAULT NULL COMMENT '启用时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证表';
+
+-- 两步验证恢复码表
+CREATE TABLE `sys_user_recovery_code` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `code_hash` CHAR(64) NOT NULL COMMENT '恢复码SHA-256摘要',
+  `used_at` DATETIME DEFAULT NULL COMMENT '使用时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+-- 历史密码表
+CREATE TABLE `sys_password_history` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `password` VARCHAR(128) NOT NULL COMMENT '历史密码(BCrypt)',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='历史密码表';
+
+-- API密钥表
+CREATE TABLE `sys_api_key` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '名称（调用方）',
+  `key_prefix` VARCHAR(16) NOT NULL COMMENT '密钥前缀，用于辨认',
+  `key_hash` CHAR(64) NOT NULL COMMENT '密钥SHA-256摘要',
+  `user_id` BIGINT NOT NULL COMMENT '绑定用户ID（调用时的身份）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '过期时间，为空不过期',
+  `last_used_at` DATETIME DEFAULT NULL COMMENT '最近使用时间',
+  `last_used_ip` VARCHAR(64) DEFAULT NULL COMMENT '最近使用IP',
+  `created_by` BIGINT NOT NULL COMMENT '创建人ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_key_hash` (`key_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥表';
+
+-- API密钥权限范围表
+CREATE TABLE `sys_api_key_permission` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `api_key_id` BIGINT NOT NULL COMMENT 'API密钥ID',
+  `permission_code` VARCHAR(50) NOT NULL COMMENT '权限码',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_key_permission` (`api_key_id`, `permission_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥权限范围表';
+
+-- API密钥调用日志表
+CREATE TABLE `sys_api_key_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `api_key_id` BIGINT NOT NULL COMMENT 'API密钥ID',
+  `method` VARCHAR(10) NOT NULL COMMENT '请求方法',
+  `path` VARCHAR(255) NOT NULL COMMENT '请求路径',
+  `status_code` INT NOT NULL COMMENT '响应状态码',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_api_key_id` (`api_key_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥调用日志表';
+
+-- 单点登录请求状态表
+CREATE TABLE `sys_oidc_state` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `state` VARCHAR(64) NOT NULL COMMENT 'state参数',
+  `nonce` VARCHAR(64) NOT NULL COMMENT 'ID令牌nonce',
+  `code_verifier` VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_state` (`state`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单点登录请求状态表';
+
+-- 外部身份关联表
+CREATE TABLE `sys_user_identity` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `provider` VARCHAR(20) NOT NULL COMMENT '身份来源: OIDC/LDAP',
+  `subject` VARCHAR(255) NOT NULL COMMENT '外部用户标识: OIDC为sub, LDAP为登录账号',
+  `user_id` BIGINT NOT NULL COMMENT '系统用户ID',
+  `email` VARCHAR(128) DEFAULT NULL COMMENT '身份提供商返回的邮箱',
+  `last_login_at` DATETIME DEFAULT NULL COMMENT '最近登录时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_provider_subject` (`provider`, `subject`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='外部身份关联表';
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('API_KEY_MANAGE', 'API密钥管理', '管理外部系统调用的API密钥', 'user');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'API_KEY_MANAGE');
@@ -0,0 +1,32 @@
+DELETE FROM `sys_role_permission` WHERE `permission_code` IN ('AUDIT_VIEW');
+DELETE FROM `sys_permission` WHERE `code` IN ('AUDIT_VIEW');
+
+ALTER TABLE `biz_inventory_check`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `biz_outbound`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `biz_inbound`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `biz_procurement`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `base_product`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `base_category`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `base_supplier`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+DROP TABLE IF EXISTS `sys_audit_log`;
@@ -0,0 +1,57 @@
+-- 数据变更审计日志，基础数据和单据支持软删除与恢复
+
+-- 数据变更审计日志表
+CREATE TABLE `sys_audit_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
+  `username` VARCHAR(50) DEFAULT NULL COMMENT '操作人账号',
+  `api_key_id` BIGINT DEFAULT NULL COMMENT '通过API密钥调用时的密钥ID',
+  `action` VARCHAR(10) NOT NULL COMMENT '操作类型: CREATE/UPDATE/DELETE',
+  `entity` VARCHAR(50) NOT NULL COMMENT '实体类型',
+  `entity_id` VARCHAR(64) NOT NULL COMMENT '实体ID',
+  `before_data` LONGTEXT COMMENT '变更前快照(JSON)',
+  `after_data` LONGTEXT COMMENT '变更后快照(JSON)',
+  `diff` LONGTEXT COMMENT '变更字段(JSON)',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `request_id` VARCHAR(64) DEFAULT NULL COMMENT '请求ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_entity` (`entity`, `entity_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_request_id` (`request_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据变更审计日志表';
+
+ALTER TABLE `base_supplier`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `base_category`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `base_product`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `biz_procurement`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `biz_inbound`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `biz_outbound`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `biz_inventory_check`
+  ADD COLUMN `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('AUDIT_VIEW', '审计日志查看', '查看数据变更审计日志', 'user');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'AUDIT_VIEW');
@@ -0,0 +1,8 @@
+ALTER TABLE `biz_stock_log` MODIFY COLUMN `related_no` VARCHAR(32) DEFAULT NULL COMMENT '关联单号';
+ALTER TABLE `biz_inventory_check` MODIFY COLUMN `check_no` VARCHAR(32) NOT NULL COMMENT '盘点单号';
+ALTER TABLE `biz_outbound` MODIFY COLUMN `outbound_no` VARCHAR(32) NOT NULL COMMENT '出库单号';
+ALTER TABLE `biz_inbound` MODIFY COLUMN `inbound_no` VARCHAR(32) NOT NULL COMMENT '入库单号';
+ALTER TABLE `biz_procurement` MODIFY COLUMN `order_no` VARCHAR(32) NOT NULL COMMENT '采购单号';
+
+DROP TABLE IF EXISTS `sys_idempotency_key`;
+DROP TABLE IF EXISTS `sys_doc_sequence`;
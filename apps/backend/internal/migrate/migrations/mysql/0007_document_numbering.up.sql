@@ -0,0 +1,36 @@
+-- 按单据类型和日期累加的编号计数器（单号加长以容纳可配置的编号格式），以及幂等请求记录
+
+-- 单据编号计数器表
+CREATE TABLE `sys_doc_sequence` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `doc_type` VARCHAR(32) NOT NULL COMMENT '单据类型',
+  `prefix` VARCHAR(64) NOT NULL COMMENT '编号前缀(含日期)',
+  `current_value` BIGINT NOT NULL DEFAULT 0 COMMENT '当前流水号',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_type_prefix` (`doc_type`, `prefix`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单据编号计数器表';
+
+-- 幂等请求记录表
+CREATE TABLE `sys_idempotency_key` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '请求用户ID',
+  `idem_key` VARCHAR(128) NOT NULL COMMENT '幂等键',
+  `method` VARCHAR(10) NOT NULL COMMENT '请求方法',
+  `path` VARCHAR(255) NOT NULL COMMENT '请求路径',
+  `request_hash` CHAR(64) NOT NULL COMMENT '请求指纹(SHA-256)',
+  `status` VARCHAR(20) NOT NULL COMMENT '状态: PROCESSING/COMPLETED',
+  `status_code` INT DEFAULT NULL COMMENT '首次响应状态码',
+  `response_body` LONGTEXT COMMENT '首次响应内容',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_key` (`user_id`, `idem_key`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='幂等请求记录表';
+
+ALTER TABLE `biz_procurement` MODIFY COLUMN `order_no` VARCHAR(64) NOT NULL COMMENT '采购单号';
+ALTER TABLE `biz_inbound` MODIFY COLUMN `inbound_no` VARCHAR(64) NOT NULL COMMENT '入库单号';
+ALTER TABLE `biz_outbound` MODIFY COLUMN `outbound_no` VARCHAR(64) NOT NULL COMMENT '出库单号';
+ALTER TABLE `biz_inventory_check` MODIFY COLUMN `check_no` VARCHAR(64) NOT NULL COMMENT '盘点单号';
+ALTER TABLE `biz_stock_log` MODIFY COLUMN `related_no` VARCHAR(64) DEFAULT NULL COMMENT '关联单号';
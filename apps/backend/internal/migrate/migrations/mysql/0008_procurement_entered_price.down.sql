@@ -0,0 +1 @@
+ALTER TABLE `biz_procurement_item` DROP COLUMN `entered_price`;
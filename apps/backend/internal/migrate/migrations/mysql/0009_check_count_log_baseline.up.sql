@@ -0,0 +1,10 @@
+-- 盘点计数记录保存录入时最新的库存流水ID，完成盘点时按流水ID区分实盘前后的出入库，不受同一秒内时间戳相同的影响
+
+ALTER TABLE `biz_inventory_check_count`
+  ADD COLUMN `stock_log_id` BIGINT NOT NULL DEFAULT 0 COMMENT '录入时最新的库存流水ID' AFTER `counter_id`;
+
+-- 已有计数记录按录入时间回填
+UPDATE `biz_inventory_check_count` SET `stock_log_id` = (
+  SELECT COALESCE(MAX(`id`), 0) FROM `biz_stock_log`
+  WHERE `biz_stock_log`.`created_at` <= `biz_inventory_check_count`.`created_at`
+);
@@ -0,0 +1,18 @@
+-- 删除基线版本创建的全部表
+
+DROP TABLE IF EXISTS `biz_inventory_check_item`;
+DROP TABLE IF EXISTS `biz_inventory_check`;
+DROP TABLE IF EXISTS `biz_stock_log`;
+DROP TABLE IF EXISTS `biz_outbound_item`;
+DROP TABLE IF EXISTS `biz_outbound`;
+DROP TABLE IF EXISTS `biz_inbound_item`;
+DROP TABLE IF EXISTS `biz_inbound`;
+DROP TABLE IF EXISTS `biz_procurement_item`;
+DROP TABLE IF EXISTS `biz_procurement`;
+DROP TABLE IF EXISTS `base_product`;
+DROP TABLE IF EXISTS `base_category`;
+DROP TABLE IF EXISTS `base_supplier`;
+DROP TABLE IF EXISTS `sys_role_permission`;
+DROP TABLE IF EXISTS `sys_permission`;
+DROP TABLE IF EXISTS `sys_user`;
+DROP TABLE IF EXISTS `base_department`;
@@ -0,0 +1,294 @@
+-- 基线版本（SQLite）：与 mysql/0001_baseline 的表结构和权限数据一致
+-- 自增主键使用 INTEGER PRIMARY KEY，索引单独创建，索引名加表名前缀避免重名
+
+-- =============================================
+-- 表结构
+-- =============================================
+
+-- 1. 部门表
+CREATE TABLE `base_department` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` VARCHAR(64) NOT NULL,
+  `parent_id` BIGINT NOT NULL DEFAULT 0,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `base_department_idx_parent_id` ON `base_department` (`parent_id`);
+
+-- 2. 系统用户表
+CREATE TABLE `sys_user` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `username` VARCHAR(64) NOT NULL,
+  `password` VARCHAR(128) NOT NULL,
+  `real_name` VARCHAR(64) NOT NULL,
+  `dept_id` BIGINT NOT NULL,
+  `role_code` VARCHAR(20) NOT NULL,
+  `status` TINYINT NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_user_uk_username` ON `sys_user` (`username`);
+CREATE INDEX `sys_user_idx_dept_id` ON `sys_user` (`dept_id`);
+CREATE INDEX `sys_user_idx_role_code` ON `sys_user` (`role_code`);
+
+-- 3. 权限码表
+CREATE TABLE `sys_permission` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `code` VARCHAR(50) NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `description` VARCHAR(255) DEFAULT NULL,
+  `module` VARCHAR(50) DEFAULT NULL,
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_permission_uk_code` ON `sys_permission` (`code`);
+
+-- 4. 角色权限关联表
+CREATE TABLE `sys_role_permission` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `role_code` VARCHAR(20) NOT NULL,
+  `permission_code` VARCHAR(50) NOT NULL,
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_role_permission_uk_role_perm` ON `sys_role_permission` (`role_code`, `permission_code`);
+
+-- 5. 供应商表
+CREATE TABLE `base_supplier` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` VARCHAR(128) NOT NULL,
+  `contact` VARCHAR(32) DEFAULT NULL,
+  `phone` VARCHAR(20) DEFAULT NULL,
+  `address` VARCHAR(255) DEFAULT NULL,
+  `status` TINYINT NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `base_supplier_idx_name` ON `base_supplier` (`name`);
+
+-- 6. 物资分类表
+CREATE TABLE `base_category` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` VARCHAR(64) NOT NULL,
+  `parent_id` BIGINT NOT NULL DEFAULT 0,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `base_category_idx_parent_id` ON `base_category` (`parent_id`);
+
+-- 7. 物资档案表
+CREATE TABLE `base_product` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `category_id` BIGINT NOT NULL,
+  `sku_code` VARCHAR(64) NOT NULL,
+  `name` VARCHAR(128) NOT NULL,
+  `specification` VARCHAR(128) DEFAULT NULL,
+  `unit` VARCHAR(20) NOT NULL,
+  `stock_qty` DECIMAL(14,4) NOT NULL DEFAULT 0.0000,
+  `alert_threshold` DECIMAL(14,4) NOT NULL DEFAULT 0.0000,
+  `status` TINYINT NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `base_product_uk_sku_code` ON `base_product` (`sku_code`);
+CREATE INDEX `base_product_idx_category_id` ON `base_product` (`category_id`);
+CREATE INDEX `base_product_idx_name` ON `base_product` (`name`);
+
+-- 8. 采购订单主表
+CREATE TABLE `biz_procurement` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `order_no` VARCHAR(64) NOT NULL,
+  `applicant_id` BIGINT NOT NULL,
+  `supplier_id` BIGINT DEFAULT NULL,
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  `reason` TEXT,
+  `expected_date` DATE DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `biz_procurement_uk_order_no` ON `biz_procurement` (`order_no`);
+CREATE INDEX `biz_procurement_idx_applicant_id` ON `biz_procurement` (`applicant_id`);
+CREATE INDEX `biz_procurement_idx_supplier_id` ON `biz_procurement` (`supplier_id`);
+CREATE INDEX `biz_procurement_idx_status` ON `biz_procurement` (`status`);
+
+-- 9. 采购明细表
+CREATE TABLE `biz_procurement_item` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `procurement_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `plan_qty` DECIMAL(14,4) NOT NULL,
+  `unit_price` DECIMAL(14,4) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_procurement_item_idx_procurement_id` ON `biz_procurement_item` (`procurement_id`);
+CREATE INDEX `biz_procurement_item_idx_product_id` ON `biz_procurement_item` (`product_id`);
+
+-- 10. 入库单主表
+CREATE TABLE `biz_inbound` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `inbound_no` VARCHAR(64) NOT NULL,
+  `source_id` BIGINT DEFAULT NULL,
+  `is_temporary` TINYINT NOT NULL DEFAULT 0,
+  `status` TINYINT NOT NULL DEFAULT 0,
+  `inbound_date` DATETIME DEFAULT NULL,
+  `warehouse_user_id` BIGINT DEFAULT NULL,
+  `remark` TEXT,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `biz_inbound_uk_inbound_no` ON `biz_inbound` (`inbound_no`);
+CREATE INDEX `biz_inbound_idx_source_id` ON `biz_inbound` (`source_id`);
+CREATE INDEX `biz_inbound_idx_status` ON `biz_inbound` (`status`);
+
+-- 11. 入库明细表
+CREATE TABLE `biz_inbound_item` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `inbound_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `actual_qty` DECIMAL(14,4) NOT NULL,
+  `location` VARCHAR(64) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_inbound_item_idx_inbound_id` ON `biz_inbound_item` (`inbound_id`);
+CREATE INDEX `biz_inbound_item_idx_product_id` ON `biz_inbound_item` (`product_id`);
+
+-- 12. 出库主表
+CREATE TABLE `biz_outbound` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `outbound_no` VARCHAR(64) NOT NULL,
+  `applicant_id` BIGINT NOT NULL,
+  `dept_id` BIGINT NOT NULL,
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  `purpose` TEXT,
+  `reviewer_id` BIGINT DEFAULT NULL,
+  `review_time` DATETIME DEFAULT NULL,
+  `outbound_date` DATETIME DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `biz_outbound_uk_outbound_no` ON `biz_outbound` (`outbound_no`);
+CREATE INDEX `biz_outbound_idx_applicant_id` ON `biz_outbound` (`applicant_id`);
+CREATE INDEX `biz_outbound_idx_dept_id` ON `biz_outbound` (`dept_id`);
+CREATE INDEX `biz_outbound_idx_status` ON `biz_outbound` (`status`);
+
+-- 13. 领用明细表
+CREATE TABLE `biz_outbound_item` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `outbound_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `apply_qty` DECIMAL(14,4) NOT NULL,
+  `actual_qty` DECIMAL(14,4) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_outbound_item_idx_outbound_id` ON `biz_outbound_item` (`outbound_id`);
+CREATE INDEX `biz_outbound_item_idx_product_id` ON `biz_outbound_item` (`product_id`);
+
+-- 14. 库存流水表
+CREATE TABLE `biz_stock_log` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `product_id` BIGINT NOT NULL,
+  `type` VARCHAR(10) NOT NULL,
+  `change_qty` DECIMAL(14,4) NOT NULL,
+  `snapshot_qty` DECIMAL(14,4) NOT NULL,
+  `related_no` VARCHAR(64) DEFAULT NULL,
+  `operator_id` BIGINT DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_stock_log_idx_product_id` ON `biz_stock_log` (`product_id`);
+CREATE INDEX `biz_stock_log_idx_type` ON `biz_stock_log` (`type`);
+CREATE INDEX `biz_stock_log_idx_related_no` ON `biz_stock_log` (`related_no`);
+CREATE INDEX `biz_stock_log_idx_created_at` ON `biz_stock_log` (`created_at`);
+
+-- 15. 盘点主表
+CREATE TABLE `biz_inventory_check` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `check_no` VARCHAR(64) NOT NULL,
+  `status` VARCHAR(20) NOT NULL DEFAULT 'CHECKING',
+  `check_date` DATE NOT NULL,
+  `checker_id` BIGINT DEFAULT NULL,
+  `remark` TEXT,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `biz_inventory_check_uk_check_no` ON `biz_inventory_check` (`check_no`);
+CREATE INDEX `biz_inventory_check_idx_status` ON `biz_inventory_check` (`status`);
+CREATE INDEX `biz_inventory_check_idx_check_date` ON `biz_inventory_check` (`check_date`);
+
+-- 16. 盘点差异表
+CREATE TABLE `biz_inventory_check_item` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `check_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `book_qty` DECIMAL(14,4) NOT NULL,
+  `actual_qty` DECIMAL(14,4) NOT NULL,
+  `diff_qty` DECIMAL(14,4) NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_inventory_check_item_idx_check_id` ON `biz_inventory_check_item` (`check_id`);
+CREATE INDEX `biz_inventory_check_item_idx_product_id` ON `biz_inventory_check_item` (`product_id`);
+
+-- =============================================
+-- 权限数据
+-- =============================================
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('BASIC_VIEW', '基础数据查看', '查看物资档案、供应商等', 'basic'),
+('BASIC_MANAGE', '基础数据管理', '新增、修改、删除基础数据', 'basic'),
+('PRODUCT_VIEW', '产品查看', '查看产品列表', 'product'),
+('PRODUCT_CREATE', '产品新增', '新增产品', 'product'),
+('PRODUCT_EDIT', '产品编辑', '编辑产品信息', 'product'),
+('PRODUCT_DELETE', '产品删除', '删除产品', 'product'),
+('SUPPLIER_MANAGE', '供应商管理', '管理供应商档案', 'supplier'),
+('DEPARTMENT_MANAGE', '部门管理', '管理部门架构', 'department'),
+('USER_MANAGE', '用户管理', '管理系统用户', 'user'),
+('INIT_STOCK', '期初库存录入', '录入期初库存', 'stock'),
+('PROCUREMENT_VIEW', '采购单查看', '查看采购申请列表', 'procurement'),
+('PROCUREMENT_CREATE', '采购申请', '发起采购申请', 'procurement'),
+('PROCUREMENT_APPROVE', '采购审批', '审批采购申请', 'procurement'),
+('PROCUREMENT_ORDER', '生成订单', '将批准的申请转为订单', 'procurement'),
+('INBOUND_VIEW', '入库单查看', '查看入库单列表', 'inbound'),
+('INBOUND_CREATE', '入库操作', '执行入库操作', 'inbound'),
+('INBOUND_APPROVE', '入库审核', '审核入库单', 'inbound'),
+('OUTBOUND_VIEW', '出库单查看', '查看出库单列表', 'outbound'),
+('OUTBOUND_CREATE', '领用申请', '发起物资领用申请', 'outbound'),
+('OUTBOUND_APPROVE', '出库审核', '审核出库申请', 'outbound'),
+('OUTBOUND_EXECUTE', '出库执行', '执行出库操作', 'outbound'),
+('INVENTORY_VIEW', '库存查看', '查看库存信息', 'inventory'),
+('INVENTORY_CHECK', '库存盘点', '执行库存盘点', 'inventory'),
+('INVENTORY_ADJUST', '库存调整', '调整库存数量', 'inventory'),
+('REPORT_VIEW', '报表查看', '查看统计报表', 'report'),
+('DASHBOARD_VIEW', '仪表盘查看', '查看仪表盘数据', 'dashboard');
+
+-- ADMIN (系统管理员) - 全部权限
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'BASIC_VIEW'), ('ADMIN', 'BASIC_MANAGE'), ('ADMIN', 'PRODUCT_VIEW'), ('ADMIN', 'PRODUCT_CREATE'),
+('ADMIN', 'PRODUCT_EDIT'), ('ADMIN', 'PRODUCT_DELETE'), ('ADMIN', 'SUPPLIER_MANAGE'), ('ADMIN', 'DEPARTMENT_MANAGE'),
+('ADMIN', 'USER_MANAGE'), ('ADMIN', 'INIT_STOCK'), ('ADMIN', 'PROCUREMENT_VIEW'), ('ADMIN', 'PROCUREMENT_CREATE'),
+('ADMIN', 'PROCUREMENT_APPROVE'), ('ADMIN', 'PROCUREMENT_ORDER'), ('ADMIN', 'INBOUND_VIEW'), ('ADMIN', 'INBOUND_CREATE'),
+('ADMIN', 'INBOUND_APPROVE'), ('ADMIN', 'OUTBOUND_VIEW'), ('ADMIN', 'OUTBOUND_CREATE'), ('ADMIN', 'OUTBOUND_APPROVE'),
+('ADMIN', 'OUTBOUND_EXECUTE'), ('ADMIN', 'INVENTORY_VIEW'), ('ADMIN', 'INVENTORY_CHECK'), ('ADMIN', 'INVENTORY_ADJUST'),
+('ADMIN', 'REPORT_VIEW'), ('ADMIN', 'DASHBOARD_VIEW');
+
+-- BUYER (采购专员)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('BUYER', 'BASIC_VIEW'), ('BUYER', 'PRODUCT_VIEW'), ('BUYER', 'SUPPLIER_MANAGE'),
+('BUYER', 'PROCUREMENT_VIEW'), ('BUYER', 'PROCUREMENT_CREATE'), ('BUYER', 'PROCUREMENT_ORDER'),
+('BUYER', 'INVENTORY_VIEW'), ('BUYER', 'DASHBOARD_VIEW');
+
+-- W_MGR (仓库管理员)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('W_MGR', 'BASIC_VIEW'), ('W_MGR', 'PRODUCT_VIEW'), ('W_MGR', 'PRODUCT_CREATE'), ('W_MGR', 'PRODUCT_EDIT'),
+('W_MGR', 'INIT_STOCK'), ('W_MGR', 'INBOUND_VIEW'), ('W_MGR', 'INBOUND_CREATE'), ('W_MGR', 'INBOUND_APPROVE'),
+('W_MGR', 'OUTBOUND_VIEW'), ('W_MGR', 'OUTBOUND_APPROVE'), ('W_MGR', 'OUTBOUND_EXECUTE'),
+('W_MGR', 'INVENTORY_VIEW'), ('W_MGR', 'INVENTORY_CHECK'), ('W_MGR', 'INVENTORY_ADJUST'), ('W_MGR', 'DASHBOARD_VIEW');
+
+-- STAFF (部门员工)
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('STAFF', 'BASIC_VIEW'), ('STAFF', 'PRODUCT_VIEW'), ('STAFF', 'OUTBOUND_VIEW'),
+('STAFF', 'OUTBOUND_CREATE'), ('STAFF', 'INVENTORY_VIEW'), ('STAFF', 'DASHBOARD_VIEW');
+
+-- =============================================
+-- 初始管理员账号 (admin / 123456)
+-- =============================================
+INSERT INTO `base_department` (`id`, `name`, `parent_id`) VALUES
+(1, '总经办', 0);
+
+INSERT INTO `sys_user` (`id`, `username`, `password`, `real_name`, `dept_id`, `role_code`, `status`) VALUES
+(1, 'admin', '$2a$10$N.zmdr9k7uOCQb376NoUnuTJ8iAt6Z5EHsM8lE9lBOsl7iKTVKIUi', '系统管理员', 1, 'ADMIN', 1);
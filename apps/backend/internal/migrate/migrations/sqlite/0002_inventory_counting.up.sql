@@ -0,0 +1,31 @@
+-- 盘点账面快照与冻结、盲盘计数和复盘轮次、ABC分类循环盘点
+
+ALTER TABLE `biz_inventory_check` ADD COLUMN `freeze` TINYINT NOT NULL DEFAULT 0;
+ALTER TABLE `biz_inventory_check` ADD COLUMN `snapshot_at` DATETIME DEFAULT NULL;
+ALTER TABLE `biz_inventory_check` ADD COLUMN `round` INT NOT NULL DEFAULT 1;
+ALTER TABLE `biz_inventory_check` ADD COLUMN `tolerance` DECIMAL(14,4) DEFAULT NULL;
+ALTER TABLE `biz_inventory_check` ADD COLUMN `cycle_class` CHAR(1) DEFAULT NULL;
+
+ALTER TABLE `biz_inventory_check_item` ADD COLUMN `counted` TINYINT NOT NULL DEFAULT 1;
+ALTER TABLE `biz_inventory_check_item` ADD COLUMN `round` INT NOT NULL DEFAULT 1;
+ALTER TABLE `biz_inventory_check_item` ADD COLUMN `need_recount` TINYINT NOT NULL DEFAULT 0;
+
+ALTER TABLE `base_product` ADD COLUMN `abc_class` CHAR(1) DEFAULT NULL;
+
+-- 盘点计数记录表
+CREATE TABLE `biz_inventory_check_count` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `check_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `round` INT NOT NULL,
+  `qty` DECIMAL(14,4) NOT NULL,
+  `counter_id` BIGINT DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `biz_inventory_check_count_idx_check_product` ON `biz_inventory_check_count` (`check_id`, `product_id`);
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('INVENTORY_SUPERVISE', '盘点监盘', '查看盘点账面数量、发起复盘', 'inventory');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'INVENTORY_SUPERVISE'), ('W_MGR', 'INVENTORY_SUPERVISE');
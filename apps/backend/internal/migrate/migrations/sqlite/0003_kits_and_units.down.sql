@@ -0,0 +1,13 @@
+ALTER TABLE `biz_outbound_item` DROP COLUMN `kit_id`;
+ALTER TABLE `biz_outbound_item` DROP COLUMN `unit_qty`;
+ALTER TABLE `biz_outbound_item` DROP COLUMN `unit`;
+
+ALTER TABLE `biz_inbound_item` DROP COLUMN `unit_qty`;
+ALTER TABLE `biz_inbound_item` DROP COLUMN `unit`;
+
+ALTER TABLE `biz_procurement_item` DROP COLUMN `unit_qty`;
+ALTER TABLE `biz_procurement_item` DROP COLUMN `unit`;
+
+DROP TABLE IF EXISTS `base_product_unit`;
+DROP TABLE IF EXISTS `biz_kit_assembly`;
+DROP TABLE IF EXISTS `base_product_kit`;
@@ -0,0 +1,51 @@
+-- 套件组成与组装单、产品辅助计量单位，单据明细记录录入单位和数量
+
+-- 套件组成表
+CREATE TABLE `base_product_kit` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `kit_id` BIGINT NOT NULL,
+  `product_id` BIGINT NOT NULL,
+  `qty` DECIMAL(14,4) NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `base_product_kit_uk_kit_product` ON `base_product_kit` (`kit_id`, `product_id`);
+CREATE INDEX `base_product_kit_idx_product_id` ON `base_product_kit` (`product_id`);
+
+-- 套件组装单表
+CREATE TABLE `biz_kit_assembly` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `assembly_no` VARCHAR(64) NOT NULL,
+  `kit_id` BIGINT NOT NULL,
+  `qty` DECIMAL(14,4) NOT NULL,
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  `operator_id` BIGINT DEFAULT NULL,
+  `assembled_at` DATETIME DEFAULT NULL,
+  `remark` TEXT,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL
+);
+CREATE UNIQUE INDEX `biz_kit_assembly_uk_assembly_no` ON `biz_kit_assembly` (`assembly_no`);
+CREATE INDEX `biz_kit_assembly_idx_kit_id` ON `biz_kit_assembly` (`kit_id`);
+CREATE INDEX `biz_kit_assembly_idx_status` ON `biz_kit_assembly` (`status`);
+CREATE INDEX `biz_kit_assembly_idx_deleted_at` ON `biz_kit_assembly` (`deleted_at`);
+
+-- 产品辅助计量单位表
+CREATE TABLE `base_product_unit` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `product_id` BIGINT NOT NULL,
+  `unit` VARCHAR(20) NOT NULL,
+  `factor` DECIMAL(14,4) NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `base_product_unit_uk_product_unit` ON `base_product_unit` (`product_id`, `unit`);
+
+ALTER TABLE `biz_procurement_item` ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL;
+ALTER TABLE `biz_procurement_item` ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL;
+
+ALTER TABLE `biz_inbound_item` ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL;
+ALTER TABLE `biz_inbound_item` ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL;
+
+ALTER TABLE `biz_outbound_item` ADD COLUMN `unit` VARCHAR(20) DEFAULT NULL;
+ALTER TABLE `biz_outbound_item` ADD COLUMN `unit_qty` DECIMAL(14,4) DEFAULT NULL;
+ALTER TABLE `biz_outbound_item` ADD COLUMN `kit_id` BIGINT DEFAULT NULL;
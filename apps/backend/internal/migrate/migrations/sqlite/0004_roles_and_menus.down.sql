@@ -0,0 +1,5 @@
+DELETE FROM `sys_role_permission` WHERE `permission_code` IN ('ROLE_MANAGE', 'MENU_MANAGE');
+DELETE FROM `sys_permission` WHERE `code` IN ('ROLE_MANAGE', 'MENU_MANAGE');
+
+DROP TABLE IF EXISTS `sys_menu`;
+DROP TABLE IF EXISTS `sys_role`;
@@ -0,0 +1,19 @@
+DELETE FROM `sys_role_permission` WHERE `permission_code` IN ('API_KEY_MANAGE');
+DELETE FROM `sys_permission` WHERE `code` IN ('API_KEY_MANAGE');
+
+DROP TABLE IF EXISTS `sys_user_identity`;
+DROP TABLE IF EXISTS `sys_oidc_state`;
+DROP TABLE IF EXISTS `sys_api_key_log`;
+DROP TABLE IF EXISTS `sys_api_key_permission`;
+DROP TABLE IF EXISTS `sys_api_key`;
+DROP TABLE IF EXISTS `sys_password_history`;
+DROP TABLE IF EXISTS `sys_user_recovery_code`;
+DROP TABLE IF EXISTS `sys_user_mfa`;
+DROP TABLE IF EXISTS `sys_login_log`;
+DROP TABLE IF EXISTS `sys_login_lock`;
+DROP TABLE IF EXISTS `sys_token_denylist`;
+DROP TABLE IF EXISTS `sys_refresh_token`;
+
+DROP INDEX `sys_user_idx_email`;
+ALTER TABLE `sys_user` DROP COLUMN `password_changed_at`;
+ALTER TABLE `sys_user` DROP COLUMN `email`;
@@ -0,0 +1,179 @@
+-- 刷新令牌、登录锁定与日志、两步验证、密码策略、API密钥和外部身份登录
+
+-- SQLite 新增列不能以 CURRENT_TIMESTAMP 为默认值，重建用户表；已有账号的密码修改时间记为迁移时间
+CREATE TABLE `sys_user_new` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `username` VARCHAR(64) NOT NULL,
+  `password` VARCHAR(128) NOT NULL,
+  `real_name` VARCHAR(64) NOT NULL,
+  `email` VARCHAR(128) NOT NULL DEFAULT '',
+  `dept_id` BIGINT NOT NULL,
+  `role_code` VARCHAR(20) NOT NULL,
+  `status` TINYINT NOT NULL DEFAULT 1,
+  `password_changed_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO `sys_user_new` (`id`, `username`, `password`, `real_name`, `dept_id`, `role_code`, `status`, `created_at`, `updated_at`)
+SELECT `id`, `username`, `password`, `real_name`, `dept_id`, `role_code`, `status`, `created_at`, `updated_at` FROM `sys_user`;
+DROP TABLE `sys_user`;
+ALTER TABLE `sys_user_new` RENAME TO `sys_user`;
+CREATE UNIQUE INDEX `sys_user_uk_username` ON `sys_user` (`username`);
+CREATE INDEX `sys_user_idx_dept_id` ON `sys_user` (`dept_id`);
+CREATE INDEX `sys_user_idx_role_code` ON `sys_user` (`role_code`);
+CREATE INDEX `sys_user_idx_email` ON `sys_user` (`email`);
+
+-- 刷新令牌表
+CREATE TABLE `sys_refresh_token` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT NOT NULL,
+  `token_hash` CHAR(64) NOT NULL,
+  `access_jti` VARCHAR(64) DEFAULT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `revoked_at` DATETIME DEFAULT NULL,
+  `ip` VARCHAR(64) DEFAULT NULL,
+  `user_agent` VARCHAR(255) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_refresh_token_uk_token_hash` ON `sys_refresh_token` (`token_hash`);
+CREATE INDEX `sys_refresh_token_idx_user_id` ON `sys_refresh_token` (`user_id`);
+
+-- 访问令牌黑名单表
+CREATE TABLE `sys_token_denylist` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `jti` VARCHAR(64) NOT NULL,
+  `user_id` BIGINT NOT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_token_denylist_uk_jti` ON `sys_token_denylist` (`jti`);
+CREATE INDEX `sys_token_denylist_idx_expires_at` ON `sys_token_denylist` (`expires_at`);
+
+-- 登录锁定表
+CREATE TABLE `sys_login_lock` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `subject_type` VARCHAR(16) NOT NULL,
+  `subject` VARCHAR(64) NOT NULL,
+  `failures` INT NOT NULL DEFAULT 0,
+  `lock_count` INT NOT NULL DEFAULT 0,
+  `locked_until` DATETIME DEFAULT NULL,
+  `last_failed_at` DATETIME DEFAULT NULL,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_login_lock_uk_subject` ON `sys_login_lock` (`subject_type`, `subject`);
+
+-- 登录日志表
+CREATE TABLE `sys_login_log` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT DEFAULT NULL,
+  `username` VARCHAR(64) NOT NULL,
+  `ip` VARCHAR(64) DEFAULT NULL,
+  `user_agent` VARCHAR(255) DEFAULT NULL,
+  `success` TINYINT NOT NULL DEFAULT 0,
+  `message` VARCHAR(255) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `sys_login_log_idx_username` ON `sys_login_log` (`username`);
+CREATE INDEX `sys_login_log_idx_ip` ON `sys_login_log` (`ip`);
+CREATE INDEX `sys_login_log_idx_created_at` ON `sys_login_log` (`created_at`);
+
+-- 两步验证表
+CREATE TABLE `sys_user_mfa` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT NOT NULL,
+  `secret` VARCHAR(64) NOT NULL,
+  `enabled` TINYINT NOT NULL DEFAULT 0,
+  `confirmed_at` DATETIME DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_user_mfa_uk_user_id` ON `sys_user_mfa` (`user_id`);
+
+-- 两步验证恢复码表
+CREATE TABLE `sys_user_recovery_code` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT NOT NULL,
+  `code_hash` CHAR(64) NOT NULL,
+  `used_at` DATETIME DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `sys_user_recovery_code_idx_user_id` ON `sys_user_recovery_code` (`user_id`);
+
+-- 历史密码表
+CREATE TABLE `sys_password_history` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT NOT NULL,
+  `password` VARCHAR(128) NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `sys_password_history_idx_user_id` ON `sys_password_history` (`user_id`);
+
+-- API密钥表
+CREATE TABLE `sys_api_key` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` VARCHAR(64) NOT NULL,
+  `key_prefix` VARCHAR(16) NOT NULL,
+  `key_hash` CHAR(64) NOT NULL,
+  `user_id` BIGINT NOT NULL,
+  `status` TINYINT NOT NULL DEFAULT 1,
+  `expires_at` DATETIME DEFAULT NULL,
+  `last_used_at` DATETIME DEFAULT NULL,
+  `last_used_ip` VARCHAR(64) DEFAULT NULL,
+  `created_by` BIGINT NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_api_key_uk_key_hash` ON `sys_api_key` (`key_hash`);
+CREATE INDEX `sys_api_key_idx_user_id` ON `sys_api_key` (`user_id`);
+
+-- API密钥权限范围表
+CREATE TABLE `sys_api_key_permission` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `api_key_id` BIGINT NOT NULL,
+  `permission_code` VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX `sys_api_key_permission_uk_key_permission` ON `sys_api_key_permission` (`api_key_id`, `permission_code`);
+
+-- API密钥调用日志表
+CREATE TABLE `sys_api_key_log` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `api_key_id` BIGINT NOT NULL,
+  `method` VARCHAR(10) NOT NULL,
+  `path` VARCHAR(255) NOT NULL,
+  `status_code` INT NOT NULL,
+  `ip` VARCHAR(64) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `sys_api_key_log_idx_api_key_id` ON `sys_api_key_log` (`api_key_id`);
+CREATE INDEX `sys_api_key_log_idx_created_at` ON `sys_api_key_log` (`created_at`);
+
+-- 单点登录请求状态表
+CREATE TABLE `sys_oidc_state` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `state` VARCHAR(64) NOT NULL,
+  `nonce` VARCHAR(64) NOT NULL,
+  `code_verifier` VARCHAR(128) NOT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_oidc_state_uk_state` ON `sys_oidc_state` (`state`);
+CREATE INDEX `sys_oidc_state_idx_expires_at` ON `sys_oidc_state` (`expires_at`);
+
+-- 外部身份关联表
+CREATE TABLE `sys_user_identity` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `provider` VARCHAR(20) NOT NULL,
+  `subject` VARCHAR(255) NOT NULL,
+  `user_id` BIGINT NOT NULL,
+  `email` VARCHAR(128) DEFAULT NULL,
+  `last_login_at` DATETIME DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_user_identity_uk_provider_subject` ON `sys_user_identity` (`provider`, `subject`);
+CREATE INDEX `sys_user_identity_idx_user_id` ON `sys_user_identity` (`user_id`);
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('API_KEY_MANAGE', 'API密钥管理', '管理外部系统调用的API密钥', 'user');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'API_KEY_MANAGE');
@@ -0,0 +1,25 @@
+DELETE FROM `sys_role_permission` WHERE `permission_code` IN ('AUDIT_VIEW');
+DELETE FROM `sys_permission` WHERE `code` IN ('AUDIT_VIEW');
+
+DROP INDEX `biz_inventory_check_idx_deleted_at`;
+ALTER TABLE `biz_inventory_check` DROP COLUMN `deleted_at`;
+
+DROP INDEX `biz_outbound_idx_deleted_at`;
+ALTER TABLE `biz_outbound` DROP COLUMN `deleted_at`;
+
+DROP INDEX `biz_inbound_idx_deleted_at`;
+ALTER TABLE `biz_inbound` DROP COLUMN `deleted_at`;
+
+DROP INDEX `biz_procurement_idx_deleted_at`;
+ALTER TABLE `biz_procurement` DROP COLUMN `deleted_at`;
+
+DROP INDEX `base_product_idx_deleted_at`;
+ALTER TABLE `base_product` DROP COLUMN `deleted_at`;
+
+DROP INDEX `base_category_idx_deleted_at`;
+ALTER TABLE `base_category` DROP COLUMN `deleted_at`;
+
+DROP INDEX `base_supplier_idx_deleted_at`;
+ALTER TABLE `base_supplier` DROP COLUMN `deleted_at`;
+
+DROP TABLE IF EXISTS `sys_audit_log`;
@@ -0,0 +1,49 @@
+-- 数据变更审计日志，基础数据和单据支持软删除与恢复
+
+-- 数据变更审计日志表
+CREATE TABLE `sys_audit_log` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT DEFAULT NULL,
+  `username` VARCHAR(50) DEFAULT NULL,
+  `api_key_id` BIGINT DEFAULT NULL,
+  `action` VARCHAR(10) NOT NULL,
+  `entity` VARCHAR(50) NOT NULL,
+  `entity_id` VARCHAR(64) NOT NULL,
+  `before_data` TEXT,
+  `after_data` TEXT,
+  `diff` TEXT,
+  `ip` VARCHAR(64) DEFAULT NULL,
+  `request_id` VARCHAR(64) DEFAULT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX `sys_audit_log_idx_entity` ON `sys_audit_log` (`entity`, `entity_id`);
+CREATE INDEX `sys_audit_log_idx_user_id` ON `sys_audit_log` (`user_id`);
+CREATE INDEX `sys_audit_log_idx_request_id` ON `sys_audit_log` (`request_id`);
+CREATE INDEX `sys_audit_log_idx_created_at` ON `sys_audit_log` (`created_at`);
+
+ALTER TABLE `base_supplier` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `base_supplier_idx_deleted_at` ON `base_supplier` (`deleted_at`);
+
+ALTER TABLE `base_category` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `base_category_idx_deleted_at` ON `base_category` (`deleted_at`);
+
+ALTER TABLE `base_product` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `base_product_idx_deleted_at` ON `base_product` (`deleted_at`);
+
+ALTER TABLE `biz_procurement` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `biz_procurement_idx_deleted_at` ON `biz_procurement` (`deleted_at`);
+
+ALTER TABLE `biz_inbound` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `biz_inbound_idx_deleted_at` ON `biz_inbound` (`deleted_at`);
+
+ALTER TABLE `biz_outbound` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `biz_outbound_idx_deleted_at` ON `biz_outbound` (`deleted_at`);
+
+ALTER TABLE `biz_inventory_check` ADD COLUMN `deleted_at` DATETIME DEFAULT NULL;
+CREATE INDEX `biz_inventory_check_idx_deleted_at` ON `biz_inventory_check` (`deleted_at`);
+
+INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
+('AUDIT_VIEW', '审计日志查看', '查看数据变更审计日志', 'user');
+
+INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
+('ADMIN', 'AUDIT_VIEW');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `sys_idempotency_key`;
+DROP TABLE IF EXISTS `sys_doc_sequence`;
@@ -0,0 +1,30 @@
+-- 按单据类型和日期累加的编号计数器（单号加长以容纳可配置的编号格式），以及幂等请求记录
+
+-- SQLite 不限制 VARCHAR 长度，单号列无需修改
+
+-- 单据编号计数器表
+CREATE TABLE `sys_doc_sequence` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `doc_type` VARCHAR(32) NOT NULL,
+  `prefix` VARCHAR(64) NOT NULL,
+  `current_value` BIGINT NOT NULL DEFAULT 0,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_doc_sequence_uk_type_prefix` ON `sys_doc_sequence` (`doc_type`, `prefix`);
+
+-- 幂等请求记录表
+CREATE TABLE `sys_idempotency_key` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `user_id` BIGINT NOT NULL,
+  `idem_key` VARCHAR(128) NOT NULL,
+  `method` VARCHAR(10) NOT NULL,
+  `path` VARCHAR(255) NOT NULL,
+  `request_hash` CHAR(64) NOT NULL,
+  `status` VARCHAR(20) NOT NULL,
+  `status_code` INT DEFAULT NULL,
+  `response_body` TEXT,
+  `expires_at` DATETIME NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX `sys_idempotency_key_uk_user_key` ON `sys_idempotency_key` (`user_id`, `idem_key`);
+CREATE INDEX `sys_idempotency_key_idx_expires_at` ON `sys_idempotency_key` (`expires_at`);
@@ -0,0 +1,7 @@
+-- 采购明细保留录入单位下的单价，基本单位单价换算后会丢失精度
+
+ALTER TABLE `biz_procurement_item` ADD COLUMN `entered_price` DECIMAL(14,4) DEFAULT NULL;
+
+-- 已有明细按录入数量反算录入单价
+UPDATE `biz_procurement_item` SET `entered_price` = `unit_price` * `plan_qty` / `unit_qty`
+WHERE `unit_price` IS NOT NULL AND `unit_qty` > 0;
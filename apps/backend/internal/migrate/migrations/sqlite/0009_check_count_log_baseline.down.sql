@@ -0,0 +1 @@
+ALTER TABLE `biz_inventory_check_count` DROP COLUMN `stock_log_id`;
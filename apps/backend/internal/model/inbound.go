@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Inbound 入库单模型
+type Inbound struct {
+	ID              int64          `json:"id" gorm:"column:id;primaryKey"`
+	InboundNo       string         `json:"orderNo" gorm:"column:inbound_no"`
+	SourceID        *int64         `json:"sourceId" gorm:"column:source_id"`
+	IsTemporary     int            `json:"isTemporary" gorm:"column:is_temporary"`
+	Status          int            `json:"statusCode" gorm:"column:status"`
+	InboundDate     *time.Time     `json:"inboundDate" gorm:"column:inbound_date"`
+	WarehouseUserID *int64         `json:"operatorId" gorm:"column:warehouse_user_id"`
+	Remark          string         `json:"remark" gorm:"column:remark"`
+	CreatedAt       time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
+	// 关联字段
+	Status_       string  `json:"status" gorm:"-"`
+	Type          string  `json:"type" gorm:"-"`
+	SourceOrderNo string  `json:"sourceOrderNo" gorm:"-"`
+	OperatorName  string  `json:"operatorName" gorm:"-"`
+	TotalQuantity float64 `json:"totalQuantity" gorm:"-"`
+	WarehouseName string  `json:"warehouseName" gorm:"-"`
+}
+
+func (Inbound) TableName() string {
+	return "biz_inbound"
+}
+
+// InboundItem 入库明细模型
+type InboundItem struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	InboundID int64     `json:"inboundId" gorm:"column:inbound_id"`
+	ProductID int64     `json:"productId" gorm:"column:product_id"`
+	ActualQty float64   `json:"quantity" gorm:"column:actual_qty"`
+	Unit      string    `json:"unit" gorm:"column:unit"`
+	UnitQty   *float64  `json:"unitQuantity" gorm:"column:unit_qty"`
+	Location  string    `json:"locationName" gorm:"column:location"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	// 关联字段
+	ProductName string `json:"productName" gorm:"-"`
+	ProductCode string `json:"productCode" gorm:"-"`
+}
+
+func (InboundItem) TableName() string {
+	return "biz_inbound_item"
+}
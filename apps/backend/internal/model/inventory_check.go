@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryCheck 盘点单模型
+type InventoryCheck struct {
+	ID         int64          `json:"id" gorm:"column:id;primaryKey"`
+	CheckNo    string         `json:"checkNo" gorm:"column:check_no"`
+	CheckerID  *int64         `json:"checkerId" gorm:"column:checker_id"`
+	Status     string         `json:"status" gorm:"column:status"`
+	CheckDate  *time.Time     `json:"checkDate" gorm:"column:check_date"`
+	Freeze     int            `json:"freeze" gorm:"column:freeze"`
+	SnapshotAt *time.Time     `json:"snapshotTime" gorm:"column:snapshot_at"`
+	Round      int            `json:"round" gorm:"column:round"`
+	Tolerance  *float64       `json:"tolerance" gorm:"column:tolerance"`
+	CycleClass *string        `json:"cycleClass" gorm:"column:cycle_class"`
+	Remark     string         `json:"remark" gorm:"column:remark"`
+	CreatedAt  time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
+	// 关联字段
+	OperatorID    int64  `json:"operatorId" gorm:"-"`
+	OperatorName  string `json:"operatorName" gorm:"-"`
+	WarehouseID   string `json:"warehouseId" gorm:"-"`
+	WarehouseName string `json:"warehouseName" gorm:"-"`
+}
+
+func (InventoryCheck) TableName() string {
+	return "biz_inventory_check"
+}
+
+// InventoryCheckItem 盘点明细模型
+type InventoryCheckItem struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey"`
+	CheckID     int64     `json:"checkId" gorm:"column:check_id"`
+	ProductID   int64     `json:"productId" gorm:"column:product_id"`
+	BookQty     float64   `json:"systemQuantity" gorm:"column:book_qty"`
+	ActualQty   float64   `json:"actualQuantity" gorm:"column:actual_qty"`
+	DiffQty     float64   `json:"differenceQuantity" gorm:"column:diff_qty"`
+	Counted     int       `json:"counted" gorm:"column:counted"`
+	Round       int       `json:"round" gorm:"column:round"`
+	NeedRecount int       `json:"needRecount" gorm:"column:need_recount"` // 差异超出容差，需在下一轮复盘
+	CreatedAt   time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	// 关联字段
+	ProductName string `json:"productName" gorm:"-"`
+	ProductCode string `json:"productCode" gorm:"-"`
+}
+
+func (InventoryCheckItem) TableName() string {
+	return "biz_inventory_check_item"
+}
+
+// InventoryCheckCount 盘点计数记录模型，每轮每次录入保留一条
+type InventoryCheckCount struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey"`
+	CheckID    int64     `json:"checkId" gorm:"column:check_id"`
+	ProductID  int64     `json:"productId" gorm:"column:product_id"`
+	Round      int       `json:"round" gorm:"column:round"`
+	Qty        float64   `json:"quantity" gorm:"column:qty"`
+	CounterID  *int64    `json:"counterId" gorm:"column:counter_id"`
+	StockLogID int64     `json:"stockLogId" gorm:"column:stock_log_id"` // 录入时最新的库存流水ID，之后的流水发生在实盘之后
+	CreatedAt  time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (InventoryCheckCount) TableName() string {
+	return "biz_inventory_check_count"
+}
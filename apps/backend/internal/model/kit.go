@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// KitComponent 套件组成模型
+type KitComponent struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	KitID     int64     `json:"kitId" gorm:"column:kit_id"`
+	ProductID int64     `json:"productId" gorm:"column:product_id"`
+	Qty       float64   `json:"quantity" gorm:"column:qty"`
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	// 关联字段
+	ProductName string `json:"productName" gorm:"-"`
+	ProductCode string `json:"productCode" gorm:"-"`
+	Unit        string `json:"unit" gorm:"-"`
+}
+
+func (KitComponent) TableName() string {
+	return "base_product_kit"
+}
+
+// KitAssembly 套件组装单模型
+type KitAssembly struct {
+	ID          int64          `json:"id" gorm:"column:id;primaryKey"`
+	AssemblyNo  string         `json:"orderNo" gorm:"column:assembly_no"`
+	KitID       int64          `json:"kitId" gorm:"column:kit_id"`
+	Qty         float64        `json:"quantity" gorm:"column:qty"`
+	Status      string         `json:"status" gorm:"column:status"`
+	OperatorID  *int64         `json:"operatorId" gorm:"column:operator_id"`
+	AssembledAt *time.Time     `json:"assembleTime" gorm:"column:assembled_at"`
+	Remark      string         `json:"remark" gorm:"column:remark"`
+	CreatedAt   time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
+	// 关联字段
+	KitName      string `json:"kitName" gorm:"-"`
+	KitCode      string `json:"kitCode" gorm:"-"`
+	OperatorName string `json:"operatorName" gorm:"-"`
+}
+
+func (KitAssembly) TableName() string {
+	return "biz_kit_assembly"
+}
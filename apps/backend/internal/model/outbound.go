@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbound 出库单模型
+type Outbound struct {
+	ID           int64          `json:"id" gorm:"column:id;primaryKey"`
+	OutboundNo   string         `json:"orderNo" gorm:"column:outbound_no"`
+	ApplicantID  int64          `json:"applicantId" gorm:"column:applicant_id"`
+	DeptID       int64          `json:"deptId" gorm:"column:dept_id"`
+	Status       string         `json:"status" gorm:"column:status"`
+	Purpose      string         `json:"purpose" gorm:"column:purpose"`
+	ReviewerID   *int64         `json:"reviewerId" gorm:"column:reviewer_id"`
+	ReviewTime   *time.Time     `json:"reviewTime" gorm:"column:review_time"`
+	OutboundDate *time.Time     `json:"outboundDate" gorm:"column:outbound_date"`
+	CreatedAt    time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
+	// 关联字段
+	ApplicantName string  `json:"applicantName" gorm:"-"`
+	DeptName      string  `json:"deptName" gorm:"-"`
+	ReviewerName  string  `json:"reviewerName" gorm:"-"`
+	TotalQuantity float64 `json:"totalQuantity" gorm:"-"`
+	Type          string  `json:"type" gorm:"-"`
+	WarehouseName string  `json:"warehouseName" gorm:"-"`
+	OperatorName  string  `json:"operatorName" gorm:"-"`
+}
+
+func (Outbound) TableName() string {
+	return "biz_outbound"
+}
+
+// OutboundItem 出库明细模型
+type OutboundItem struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey"`
+	OutboundID int64     `json:"outboundId" gorm:"column:outbound_id"`
+	ProductID  int64     `json:"productId" gorm:"column:product_id"`
+	ApplyQty   float64   `json:"quantity" gorm:"column:apply_qty"`
+	ActualQty  *float64  `json:"pickedQuantity" gorm:"column:actual_qty"`
+	Unit       string    `json:"unit" gorm:"column:unit"`
+	UnitQty    *float64  `json:"unitQuantity" gorm:"column:unit_qty"`
+	KitID      *int64    `json:"kitId" gorm:"column:kit_id"`
+	CreatedAt  time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	// 关联字段
+	ProductName string `json:"productName" gorm:"-"`
+	ProductCode string `json:"productCode" gorm:"-"`
+}
+
+func (OutboundItem) TableName() string {
+	return "biz_outbound_item"
+}
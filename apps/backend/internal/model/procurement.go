@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Procurement 采购单模型
+type Procurement struct {
+	ID           int64          `json:"id" gorm:"column:id;primaryKey"`
+	OrderNo      string         `json:"orderNo" gorm:"column:order_no"`
+	ApplicantID  int64          `json:"applicantId" gorm:"column:applicant_id"`
+	SupplierID   *int64         `json:"supplierId" gorm:"column:supplier_id"`
+	Status       string         `json:"status" gorm:"column:status"`
+	Reason       string         `json:"reason" gorm:"column:reason"`
+	ExpectedDate *time.Time     `json:"expectedDate" gorm:"column:expected_date"`
+	CreatedAt    time.Time      `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time      `json:"updateTime" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `json:"deleteTime" gorm:"column:deleted_at;index"`
+	TotalAmount  float64        `json:"totalAmount" gorm:"-"`
+	// 关联字段
+	ApplicantName string `json:"applicantName" gorm:"-"`
+	SupplierName  string `json:"supplierName" gorm:"-"`
+}
+
+func (Procurement) TableName() string {
+	return "biz_procurement"
+}
+
+// ProcurementItem 采购明细模型
+type ProcurementItem struct {
+	ID            int64     `json:"id" gorm:"column:id;primaryKey"`
+	ProcurementID int64     `json:"procurementId" gorm:"column:procurement_id"`
+	ProductID     int64     `json:"productId" gorm:"column:product_id"`
+	PlanQty       float64   `json:"quantity" gorm:"column:plan_qty"`
+	UnitPrice     *float64  `json:"price" gorm:"column:unit_price"`
+	Unit          string    `json:"unit" gorm:"column:unit"`
+	UnitQty       *float64  `json:"unitQuantity" gorm:"column:unit_qty"`
+	EnteredPrice  *float64  `json:"unitPrice" gorm:"column:entered_price"`
+	Amount        float64   `json:"amount" gorm:"-"`
+	CreatedAt     time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+	// 关联字段
+	ProductName string `json:"productName" gorm:"-"`
+	ProductCode string `json:"productCode" gorm:"-"`
+}
+
+func (ProcurementItem) TableName() string {
+	return "biz_procurement_item"
+}
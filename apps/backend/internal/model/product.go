@@ -2,21 +2,24 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Product 物资档案模型
 type Product struct {
-	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	CategoryID     int64     `gorm:"not null;index" json:"category_id"`
-	SKUCode        string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"sku_code"`
-	Name           string    `gorm:"type:varchar(128);not null;index" json:"name"`
-	Specification  string    `gorm:"type:varchar(128)" json:"specification"`
-	Unit           string    `gorm:"type:varchar(20);not null" json:"unit"`
-	StockQty       float64   `gorm:"type:decimal(14,4);not null;default:0" json:"stock_qty"`
-	AlertThreshold float64   `gorm:"type:decimal(14,4);not null;default:0" json:"alert_threshold"`
-	Status         int8      `gorm:"type:tinyint;not null;default:1" json:"status"`
-	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID             int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CategoryID     int64          `gorm:"not null;index" json:"category_id"`
+	SKUCode        string         `gorm:"type:varchar(64);uniqueIndex;not null" json:"sku_code"`
+	Name           string         `gorm:"type:varchar(128);not null;index" json:"name"`
+	Specification  string         `gorm:"type:varchar(128)" json:"specification"`
+	Unit           string         `gorm:"type:varchar(20);not null" json:"unit"`
+	StockQty       float64        `gorm:"type:decimal(14,4);not null;default:0" json:"stock_qty"`
+	AlertThreshold float64        `gorm:"type:decimal(14,4);not null;default:0" json:"alert_threshold"`
+	Status         int8           `gorm:"type:tinyint;not null;default:1" json:"status"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	// 关联
 	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
@@ -29,14 +32,28 @@ func (Product) TableName() string {
 
 // Category 物资分类模型
 type Category struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `gorm:"type:varchar(64);not null" json:"name"`
-	ParentID  int64     `gorm:"not null;default:0;index" json:"parent_id"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID        int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string         `gorm:"type:varchar(64);not null" json:"name"`
+	ParentID  int64          `gorm:"not null;default:0;index" json:"parent_id"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 // TableName 指定表名
 func (Category) TableName() string {
 	return "base_category"
 }
+
+// ProductUnit 产品辅助计量单位模型
+type ProductUnit struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	ProductID int64     `json:"productId" gorm:"column:product_id"`
+	Unit      string    `json:"unit" gorm:"column:unit"`
+	Factor    float64   `json:"factor" gorm:"column:factor"` // 1个辅助单位折合的基本单位数量
+	CreatedAt time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ProductUnit) TableName() string {
+	return "base_product_unit"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// StockLog 库存流水模型
+type StockLog struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey"`
+	ProductID   int64     `json:"productId" gorm:"column:product_id"`
+	Type        string    `json:"type" gorm:"column:type"`
+	ChangeQty   float64   `json:"changeQty" gorm:"column:change_qty"`
+	SnapshotQty float64   `json:"snapshotQty" gorm:"column:snapshot_qty"`
+	RelatedNo   string    `json:"relatedNo" gorm:"column:related_no"`
+	OperatorID  *int64    `json:"operatorId" gorm:"column:operator_id"`
+	CreatedAt   time.Time `json:"createTime" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (StockLog) TableName() string {
+	return "biz_stock_log"
+}
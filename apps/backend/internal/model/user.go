@@ -10,12 +10,16 @@ type User struct {
 	Username  string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"username"`
 	Password  string    `gorm:"type:varchar(128);not null" json:"-"`
 	RealName  string    `gorm:"type:varchar(64);not null" json:"real_name"`
+	Email     string    `gorm:"type:varchar(128);index" json:"email"`
 	DeptID    int64     `gorm:"not null;index" json:"dept_id"`
 	RoleCode  string    `gorm:"type:varchar(20);not null;index" json:"role_code"`
 	Status    int8      `gorm:"type:tinyint;not null;default:1" json:"status"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// PasswordChangedAt 密码最近修改时间，为空表示须在下次登录时修改
+	PasswordChangedAt *time.Time `gorm:"column:password_changed_at" json:"password_changed_at"`
+
 	// 关联
 	Department *Department `gorm:"foreignKey:DeptID" json:"department,omitempty"`
 }
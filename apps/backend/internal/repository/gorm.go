@@ -0,0 +1,790 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/sequence"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormStore 基于 GORM 的仓储实现
+type gormStore struct {
+	db      *gorm.DB
+	numbers *sequence.Generator
+}
+
+// NewStore 创建基于 GORM 的仓储集合
+func NewStore(db *gorm.DB, numbers *sequence.Generator) Store {
+	return &gormStore{db: db, numbers: numbers}
+}
+
+func (s *gormStore) Inbounds() InboundRepository         { return gormInbounds{s.db} }
+func (s *gormStore) Outbounds() OutboundRepository       { return gormOutbounds{s.db} }
+func (s *gormStore) Products() ProductRepository         { return gormProducts{s.db} }
+func (s *gormStore) Stock() StockRepository              { return gormStock{s.db} }
+func (s *gormStore) Users() UserRepository               { return gormUsers{s.db} }
+func (s *gormStore) Departments() DepartmentRepository   { return gormDepartments{s.db} }
+func (s *gormStore) Procurements() ProcurementRepository { return gormProcurements{s.db} }
+func (s *gormStore) Suppliers() SupplierRepository       { return gormSuppliers{s.db} }
+func (s *gormStore) Kits() KitRepository                 { return gormKits{s.db} }
+func (s *gormStore) Assemblies() AssemblyRepository      { return gormAssemblies{s.db} }
+func (s *gormStore) InventoryChecks() InventoryCheckRepository {
+	return gormInventoryChecks{s.db}
+}
+
+func (s *gormStore) NextNumber(docType string) (string, error) {
+	return s.numbers.Next(s.db, docType)
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx, numbers: s.numbers})
+	})
+}
+
+// first 查询单条记录，找不到时返回 ErrNotFound
+func first(db *gorm.DB, dest interface{}, id int64) error {
+	err := db.First(dest, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ScopeFilter 返回数据权限查询条件。userColumn 为单据归属人字段；deptColumn 为归属部门字段，
+// 为空时按归属人当前所在部门过滤
+func ScopeFilter(scope Scope, userColumn, deptColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch scope.Level {
+		case ScopeAll:
+			return db
+		case ScopeDept:
+			if deptColumn != "" {
+				return db.Where(deptColumn+" IN ?", scope.DeptIDs)
+			}
+			return db.Where(userColumn+" IN (?)", db.Session(&gorm.Session{NewDB: true}).
+				Table("sys_user").Select("id").Where("dept_id IN ?", scope.DeptIDs))
+		default:
+			return db.Where(userColumn+" = ?", scope.UserID)
+		}
+	}
+}
+
+// trash 列表查询的删除状态过滤：deleted 为真时只查已删除的记录
+func trash(deleted bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if deleted {
+			return db.Unscoped().Where("deleted_at IS NOT NULL")
+		}
+		return db
+	}
+}
+
+// createdBetween 按创建日期过滤，endDate 当天全天包含在内
+func createdBetween(startDate, endDate string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if startDate != "" {
+			db = db.Where("created_at >= ?", startDate)
+		}
+		if endDate != "" {
+			db = db.Where("created_at <= ?", endDate+" 23:59:59")
+		}
+		return db
+	}
+}
+
+// names 按 ID 批量查询 table 中的 column 列
+func names(db *gorm.DB, table, column string, ids []int64) (map[int64]string, error) {
+	result := make(map[int64]string)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ID   int64  `gorm:"column:id"`
+		Name string `gorm:"column:name"`
+	}
+	err := db.Table(table).Select("id, "+column+" AS name").Where("id IN ?", ids).Find(&rows).Error
+	for _, row := range rows {
+		result[row.ID] = row.Name
+	}
+	return result, err
+}
+
+// sumBy 按 groupColumn 分组汇总 expr
+func sumBy(db *gorm.DB, table, groupColumn, expr string, ids []int64) (map[int64]float64, error) {
+	result := make(map[int64]float64)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ID    int64   `gorm:"column:id"`
+		Total float64 `gorm:"column:total"`
+	}
+	err := db.Table(table).
+		Select(groupColumn+" AS id, SUM("+expr+") AS total").
+		Where(groupColumn+" IN ?", ids).
+		Group(groupColumn).
+		Find(&rows).Error
+	for _, row := range rows {
+		result[row.ID] = row.Total
+	}
+	return result, err
+}
+
+type gormInbounds struct{ db *gorm.DB }
+
+func (r gormInbounds) List(filter InboundFilter) ([]model.Inbound, int64, error) {
+	query := r.db.Model(&model.Inbound{}).Scopes(trash(filter.Deleted), createdBetween(filter.StartDate, filter.EndDate))
+	if filter.OrderNo != "" {
+		query = query.Where("inbound_no LIKE ?", "%"+filter.OrderNo+"%")
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var inbounds []model.Inbound
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&inbounds).Error
+	return inbounds, total, err
+}
+
+func (r gormInbounds) Get(id int64) (*model.Inbound, error) {
+	var inbound model.Inbound
+	if err := first(r.db, &inbound, id); err != nil {
+		return nil, err
+	}
+	return &inbound, nil
+}
+
+func (r gormInbounds) GetDeleted(id int64) (*model.Inbound, error) {
+	var inbound model.Inbound
+	if err := first(r.db.Unscoped().Where("deleted_at IS NOT NULL"), &inbound, id); err != nil {
+		return nil, err
+	}
+	return &inbound, nil
+}
+
+func (r gormInbounds) Items(inboundID int64) ([]model.InboundItem, error) {
+	var items []model.InboundItem
+	err := r.db.Where("inbound_id = ?", inboundID).Find(&items).Error
+	return items, err
+}
+
+func (r gormInbounds) TotalQuantities(inboundIDs []int64) (map[int64]float64, error) {
+	return sumBy(r.db, "biz_inbound_item", "inbound_id", "actual_qty", inboundIDs)
+}
+
+func (r gormInbounds) Create(inbound *model.Inbound, items []model.InboundItem) error {
+	if err := r.db.Create(inbound).Error; err != nil {
+		return err
+	}
+	return r.createItems(inbound.ID, items)
+}
+
+func (r gormInbounds) Update(inbound *model.Inbound, status int) error {
+	result := r.db.Model(inbound).
+		Where("status = ?", status).
+		Select("source_id", "is_temporary", "status", "inbound_date", "remark", "updated_at").
+		Updates(inbound)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r gormInbounds) ReplaceItems(inboundID int64, items []model.InboundItem) error {
+	if err := r.db.Where("inbound_id = ?", inboundID).Delete(&model.InboundItem{}).Error; err != nil {
+		return err
+	}
+	return r.createItems(inboundID, items)
+}
+
+func (r gormInbounds) createItems(inboundID int64, items []model.InboundItem) error {
+	for i := range items {
+		items[i].ID = 0
+		items[i].InboundID = inboundID
+		if err := r.db.Create(&items[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r gormInbounds) Delete(id int64) error {
+	return r.db.Delete(&model.Inbound{}, id).Error
+}
+
+func (r gormInbounds) Restore(id int64) error {
+	return r.db.Unscoped().Model(&model.Inbound{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+type gormOutbounds struct{ db *gorm.DB }
+
+func (r gormOutbounds) scoped(scope Scope) *gorm.DB {
+	return r.db.Scopes(ScopeFilter(scope, "applicant_id", "dept_id"))
+}
+
+func (r gormOutbounds) List(filter OutboundFilter) ([]model.Outbound, int64, error) {
+	query := r.scoped(filter.Scope).Model(&model.Outbound{}).
+		Scopes(trash(filter.Deleted), createdBetween(filter.StartDate, filter.EndDate))
+	if filter.OrderNo != "" {
+		query = query.Where("outbound_no LIKE ?", "%"+filter.OrderNo+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var outbounds []model.Outbound
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&outbounds).Error
+	return outbounds, total, err
+}
+
+func (r gormOutbounds) Get(scope Scope, id int64) (*model.Outbound, error) {
+	var outbound model.Outbound
+	if err := first(r.scoped(scope), &outbound, id); err != nil {
+		return nil, err
+	}
+	return &outbound, nil
+}
+
+func (r gormOutbounds) GetDeleted(scope Scope, id int64) (*model.Outbound, error) {
+	var outbound model.Outbound
+	if err := first(r.scoped(scope).Scopes(trash(true)), &outbound, id); err != nil {
+		return nil, err
+	}
+	return &outbound, nil
+}
+
+func (r gormOutbounds) Items(outboundID int64) ([]model.OutboundItem, error) {
+	var items []model.OutboundItem
+	err := r.db.Where("outbound_id = ?", outboundID).Find(&items).Error
+	return items, err
+}
+
+func (r gormOutbounds) TotalQuantities(outboundIDs []int64) (map[int64]float64, error) {
+	return sumBy(r.db, "biz_outbound_item", "outbound_id", "apply_qty", outboundIDs)
+}
+
+func (r gormOutbounds) Create(outbound *model.Outbound, items []model.OutboundItem) error {
+	if err := r.db.Create(outbound).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].OutboundID = outbound.ID
+		if err := r.db.Create(&items[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r gormOutbounds) Update(outbound *model.Outbound) error {
+	result := r.db.Model(outbound).
+		Where("status <> ?", "DONE").
+		Select("status", "purpose", "reviewer_id", "review_time", "outbound_date", "updated_at").
+		Updates(outbound)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r gormOutbounds) SetActualQty(itemID int64, qty float64) error {
+	return r.db.Model(&model.OutboundItem{}).Where("id = ?", itemID).Update("actual_qty", qty).Error
+}
+
+func (r gormOutbounds) Delete(id int64) error {
+	return r.db.Delete(&model.Outbound{}, id).Error
+}
+
+func (r gormOutbounds) Restore(id int64) error {
+	return r.db.Unscoped().Model(&model.Outbound{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+type gormProducts struct{ db *gorm.DB }
+
+func (r gormProducts) Get(id int64) (*model.Product, error) {
+	var product model.Product
+	if err := first(r.db, &product, id); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r gormProducts) FindWithDeleted(ids []int64) ([]model.Product, error) {
+	var products []model.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.Unscoped().Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+func (r gormProducts) FirstDeletedName(ids []int64) (string, bool, error) {
+	if len(ids) == 0 {
+		return "", false, nil
+	}
+	var names []string
+	err := r.db.Unscoped().Model(&model.Product{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Limit(1).
+		Pluck("name", &names).Error
+	if err != nil || len(names) == 0 {
+		return "", false, err
+	}
+	return names[0], true, nil
+}
+
+func (r gormProducts) Unit(productID int64, unit string) (*model.ProductUnit, error) {
+	var productUnit model.ProductUnit
+	err := r.db.Where("product_id = ? AND unit = ?", productID, unit).First(&productUnit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &productUnit, nil
+}
+
+func (r gormProducts) Match(filter ProductMatch) ([]int64, error) {
+	query := r.db.Model(&model.Product{}).Where("status = ?", 1)
+	if filter.CategoryID > 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.AbcClass != "" {
+		query = query.Where("abc_class = ?", filter.AbcClass)
+	}
+	if filter.LocationFrom != "" || filter.LocationTo != "" {
+		// 库位记录在入库明细上，按入库过的库位筛选产品
+		located := r.db.Session(&gorm.Session{NewDB: true}).Model(&model.InboundItem{}).Select("DISTINCT product_id")
+		if filter.LocationFrom != "" {
+			located = located.Where("location >= ?", filter.LocationFrom)
+		}
+		if filter.LocationTo != "" {
+			located = located.Where("location <= ?", filter.LocationTo)
+		}
+		query = query.Where("id IN (?)", located)
+	}
+
+	var ids []int64
+	err := query.Order("sku_code ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+type gormStock struct{ db *gorm.DB }
+
+func (r gormStock) FreezingCheck(productIDs []int64) (string, bool, error) {
+	if len(productIDs) == 0 {
+		return "", false, nil
+	}
+	var checkNos []string
+	err := r.db.Table("biz_inventory_check_item i").
+		Joins("JOIN biz_inventory_check c ON c.id = i.check_id").
+		Where("c.status = ? AND c.freeze = ? AND c.deleted_at IS NULL AND i.product_id IN ?", "CHECKING", 1, productIDs).
+		Limit(1).
+		Pluck("c.check_no", &checkNos).Error
+	if err != nil || len(checkNos) == 0 {
+		return "", false, err
+	}
+	return checkNos[0], true, nil
+}
+
+func (r gormStock) Adjust(productID int64, delta float64) (float64, error) {
+	if err := r.db.Model(&model.Product{}).Where("id = ?", productID).
+		Update("stock_qty", gorm.Expr("stock_qty + ?", delta)).Error; err != nil {
+		return 0, err
+	}
+	return r.current(productID)
+}
+
+func (r gormStock) Deduct(productID int64, qty float64) (float64, error) {
+	result := r.db.Model(&model.Product{}).Where("id = ? AND stock_qty >= ?", productID, qty).
+		Update("stock_qty", gorm.Expr("stock_qty - ?", qty))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrConflict
+	}
+	return r.current(productID)
+}
+
+// current 查询产品当前库存
+func (r gormStock) current(productID int64) (float64, error) {
+	var qty []float64
+	if err := r.db.Model(&model.Product{}).Unscoped().Where("id = ?", productID).Pluck("stock_qty", &qty).Error; err != nil {
+		return 0, err
+	}
+	if len(qty) == 0 {
+		return 0, ErrNotFound
+	}
+	return qty[0], nil
+}
+
+func (r gormStock) AddLog(log *model.StockLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r gormStock) LastLogID() (int64, error) {
+	var id int64
+	err := r.db.Model(&model.StockLog{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (r gormStock) LastLogIDBefore(t time.Time) (int64, error) {
+	var id int64
+	err := r.db.Model(&model.StockLog{}).Where("created_at <= ?", t).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (r gormStock) MovedSince(productID, logID int64) (float64, error) {
+	var moved float64
+	err := r.db.Model(&model.StockLog{}).
+		Where("product_id = ? AND id > ?", productID, logID).
+		Select("COALESCE(SUM(change_qty), 0)").Scan(&moved).Error
+	return moved, err
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r gormUsers) RealNames(ids []int64) (map[int64]string, error) {
+	return names(r.db, "sys_user", "real_name", ids)
+}
+
+func (r gormUsers) DeptID(userID int64) (int64, error) {
+	var deptIDs []int64
+	if err := r.db.Model(&model.User{}).Where("id = ?", userID).Pluck("dept_id", &deptIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(deptIDs) == 0 {
+		return 0, nil
+	}
+	return deptIDs[0], nil
+}
+
+type gormDepartments struct{ db *gorm.DB }
+
+func (r gormDepartments) Names(ids []int64) (map[int64]string, error) {
+	return names(r.db, "base_department", "name", ids)
+}
+
+type gormProcurements struct{ db *gorm.DB }
+
+func (r gormProcurements) scoped(scope Scope) *gorm.DB {
+	return r.db.Scopes(ScopeFilter(scope, "applicant_id", ""))
+}
+
+func (r gormProcurements) List(filter ProcurementFilter) ([]model.Procurement, int64, error) {
+	query := r.scoped(filter.Scope).Model(&model.Procurement{}).
+		Scopes(trash(filter.Deleted), createdBetween(filter.StartDate, filter.EndDate))
+	if filter.OrderNo != "" {
+		query = query.Where("order_no LIKE ?", "%"+filter.OrderNo+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var procurements []model.Procurement
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&procurements).Error
+	return procurements, total, err
+}
+
+func (r gormProcurements) Get(scope Scope, id int64) (*model.Procurement, error) {
+	var procurement model.Procurement
+	if err := first(r.scoped(scope), &procurement, id); err != nil {
+		return nil, err
+	}
+	return &procurement, nil
+}
+
+func (r gormProcurements) GetDeleted(scope Scope, id int64) (*model.Procurement, error) {
+	var procurement model.Procurement
+	if err := first(r.scoped(scope).Scopes(trash(true)), &procurement, id); err != nil {
+		return nil, err
+	}
+	return &procurement, nil
+}
+
+func (r gormProcurements) Items(procurementID int64) ([]model.ProcurementItem, error) {
+	var items []model.ProcurementItem
+	err := r.db.Where("procurement_id = ?", procurementID).Find(&items).Error
+	return items, err
+}
+
+func (r gormProcurements) TotalAmounts(procurementIDs []int64) (map[int64]float64, error) {
+	return sumBy(r.db, "biz_procurement_item", "procurement_id", "plan_qty * COALESCE(unit_price, 0)", procurementIDs)
+}
+
+func (r gormProcurements) Create(procurement *model.Procurement, items []model.ProcurementItem) error {
+	if err := r.db.Create(procurement).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].ProcurementID = procurement.ID
+		if err := r.db.Create(&items[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r gormProcurements) Update(procurement *model.Procurement) error {
+	return r.db.Model(procurement).
+		Select("supplier_id", "status", "reason", "expected_date", "updated_at").
+		Updates(procurement).Error
+}
+
+func (r gormProcurements) Delete(id int64) error {
+	return r.db.Delete(&model.Procurement{}, id).Error
+}
+
+func (r gormProcurements) Restore(id int64) error {
+	return r.db.Unscoped().Model(&model.Procurement{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r gormProcurements) OrderNos(ids []int64) (map[int64]string, error) {
+	return names(r.db, "biz_procurement", "order_no", ids)
+}
+
+type gormSuppliers struct{ db *gorm.DB }
+
+func (r gormSuppliers) Names(ids []int64) (map[int64]string, error) {
+	return names(r.db, "base_supplier", "name", ids)
+}
+
+type gormKits struct{ db *gorm.DB }
+
+func (r gormKits) Components(kitID int64) ([]model.KitComponent, error) {
+	var components []model.KitComponent
+	err := r.db.Where("kit_id = ?", kitID).Order("id ASC").Find(&components).Error
+	return components, err
+}
+
+func (r gormKits) IsComponent(productID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.KitComponent{}).Where("product_id = ?", productID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r gormKits) ReplaceComponents(kitID int64, components []model.KitComponent) error {
+	if err := r.db.Where("kit_id = ?", kitID).Delete(&model.KitComponent{}).Error; err != nil {
+		return err
+	}
+	for i := range components {
+		components[i].ID = 0
+		components[i].KitID = kitID
+		if err := r.db.Create(&components[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type gormAssemblies struct{ db *gorm.DB }
+
+func (r gormAssemblies) List(filter AssemblyFilter) ([]model.KitAssembly, int64, error) {
+	query := r.db.Model(&model.KitAssembly{}).Scopes(trash(filter.Deleted))
+	if filter.OrderNo != "" {
+		query = query.Where("assembly_no LIKE ?", "%"+filter.OrderNo+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var assemblies []model.KitAssembly
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&assemblies).Error
+	return assemblies, total, err
+}
+
+func (r gormAssemblies) Get(id int64) (*model.KitAssembly, error) {
+	var assembly model.KitAssembly
+	if err := first(r.db, &assembly, id); err != nil {
+		return nil, err
+	}
+	return &assembly, nil
+}
+
+func (r gormAssemblies) GetDeleted(id int64) (*model.KitAssembly, error) {
+	var assembly model.KitAssembly
+	if err := first(r.db.Scopes(trash(true)), &assembly, id); err != nil {
+		return nil, err
+	}
+	return &assembly, nil
+}
+
+func (r gormAssemblies) Create(assembly *model.KitAssembly) error {
+	return r.db.Create(assembly).Error
+}
+
+func (r gormAssemblies) Update(assembly *model.KitAssembly, status string) error {
+	result := r.db.Model(assembly).
+		Where("status = ?", status).
+		Select("status", "remark", "assembled_at", "updated_at").
+		Updates(assembly)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r gormAssemblies) Delete(id int64) error {
+	return r.db.Delete(&model.KitAssembly{}, id).Error
+}
+
+func (r gormAssemblies) Restore(id int64) error {
+	return r.db.Unscoped().Model(&model.KitAssembly{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+type gormInventoryChecks struct{ db *gorm.DB }
+
+func (r gormInventoryChecks) List(filter InventoryCheckFilter) ([]model.InventoryCheck, int64, error) {
+	query := r.db.Model(&model.InventoryCheck{}).
+		Scopes(trash(filter.Deleted), createdBetween(filter.StartDate, filter.EndDate))
+	if filter.CheckNo != "" {
+		query = query.Where("check_no LIKE ?", "%"+filter.CheckNo+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var checks []model.InventoryCheck
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&checks).Error
+	return checks, total, err
+}
+
+func (r gormInventoryChecks) Get(id int64) (*model.InventoryCheck, error) {
+	var check model.InventoryCheck
+	if err := first(r.db, &check, id); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (r gormInventoryChecks) GetDeleted(id int64) (*model.InventoryCheck, error) {
+	var check model.InventoryCheck
+	if err := first(r.db.Scopes(trash(true)), &check, id); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (r gormInventoryChecks) Lock(id int64) (*model.InventoryCheck, error) {
+	var check model.InventoryCheck
+	if err := first(r.db.Clauses(clause.Locking{Strength: "UPDATE"}), &check, id); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (r gormInventoryChecks) Items(checkID int64) ([]model.InventoryCheckItem, error) {
+	var items []model.InventoryCheckItem
+	err := r.db.Where("check_id = ?", checkID).Find(&items).Error
+	return items, err
+}
+
+func (r gormInventoryChecks) Create(check *model.InventoryCheck) error {
+	return r.db.Create(check).Error
+}
+
+func (r gormInventoryChecks) CreateItem(item *model.InventoryCheckItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r gormInventoryChecks) CountItem(checkID, productID int64, round int, qty float64) error {
+	result := r.db.Model(&model.InventoryCheckItem{}).
+		Where("check_id = ? AND product_id = ?", checkID, productID).
+		Where("(need_recount = 1 OR ? = 1)", round).
+		Updates(map[string]interface{}{
+			"actual_qty": qty,
+			"diff_qty":   gorm.Expr("? - book_qty", qty),
+			"counted":    1,
+			"round":      round,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormInventoryChecks) AddCount(count *model.InventoryCheckCount) error {
+	return r.db.Create(count).Error
+}
+
+func (r gormInventoryChecks) LastCount(checkID, productID int64) (*model.InventoryCheckCount, error) {
+	var counts []model.InventoryCheckCount
+	if err := r.db.Where("check_id = ? AND product_id = ?", checkID, productID).
+		Order("id DESC").Limit(1).Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, ErrNotFound
+	}
+	return &counts[0], nil
+}
+
+func (r gormInventoryChecks) StartRound(checkID int64, round int, itemIDs []int64) error {
+	if err := r.db.Model(&model.InventoryCheckItem{}).Where("check_id = ?", checkID).
+		Update("need_recount", 0).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&model.InventoryCheckItem{}).Where("id IN ?", itemIDs).Updates(map[string]interface{}{
+		"need_recount": 1,
+		"counted":      0,
+	}).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&model.InventoryCheck{}).Where("id = ?", checkID).Update("round", round).Error
+}
+
+func (r gormInventoryChecks) Update(check *model.InventoryCheck) error {
+	return r.db.Model(check).Select("status", "remark", "check_date", "updated_at").Updates(check).Error
+}
+
+func (r gormInventoryChecks) Delete(id int64) error {
+	return r.db.Delete(&model.InventoryCheck{}, id).Error
+}
+
+func (r gormInventoryChecks) Restore(id int64) error {
+	return r.db.Unscoped().Model(&model.InventoryCheck{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
@@ -0,0 +1,268 @@
+// Package repository 定义各业务聚合的数据访问接口，业务逻辑通过这些接口读写数据，
+// 便于在单元测试中替换为内存实现，也便于在命令行工具和后台任务中复用
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"easywms/internal/model"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict 记录已被其他请求修改，条件更新没有命中
+var ErrConflict = errors.New("record changed concurrently")
+
+// Store 仓储集合，同一个 Store 上的操作共用一个数据库会话
+type Store interface {
+	Inbounds() InboundRepository
+	Outbounds() OutboundRepository
+	Products() ProductRepository
+	Stock() StockRepository
+	Users() UserRepository
+	Departments() DepartmentRepository
+	Procurements() ProcurementRepository
+	Suppliers() SupplierRepository
+	Kits() KitRepository
+	Assemblies() AssemblyRepository
+	InventoryChecks() InventoryCheckRepository
+
+	// NextNumber 生成单据编号，在事务中调用时编号随事务回滚释放
+	NextNumber(docType string) (string, error)
+	// Transaction 在事务中执行 fn，fn 返回错误时回滚
+	Transaction(fn func(tx Store) error) error
+}
+
+// 数据权限范围
+const (
+	ScopeAll  = "ALL"  // 全部数据
+	ScopeDept = "DEPT" // 本部门及下级部门数据
+	ScopeSelf = "SELF" // 仅本人数据
+)
+
+// Scope 数据权限，DEPT 时限 DeptIDs 中部门的数据，SELF 时只限 UserID 本人的数据
+type Scope struct {
+	Level   string
+	UserID  int64
+	DeptIDs []int64
+}
+
+// InboundFilter 入库单列表查询条件
+type InboundFilter struct {
+	OrderNo   string
+	Status    *int
+	StartDate string
+	EndDate   string
+	Deleted   bool // 只查已删除的入库单
+	Offset    int
+	Limit     int
+}
+
+// InboundRepository 入库单仓储
+type InboundRepository interface {
+	List(filter InboundFilter) ([]model.Inbound, int64, error)
+	Get(id int64) (*model.Inbound, error)
+	GetDeleted(id int64) (*model.Inbound, error)
+	Items(inboundID int64) ([]model.InboundItem, error)
+	// TotalQuantities 按入库单汇总明细的基本单位数量
+	TotalQuantities(inboundIDs []int64) (map[int64]float64, error)
+	// Create 创建入库单及明细，回填 ID
+	Create(inbound *model.Inbound, items []model.InboundItem) error
+	// Update 仅当入库单当前状态为 status 时更新表头中可修改的字段，否则返回 ErrConflict
+	Update(inbound *model.Inbound, status int) error
+	// ReplaceItems 用新明细替换入库单的全部明细
+	ReplaceItems(inboundID int64, items []model.InboundItem) error
+	Delete(id int64) error
+	Restore(id int64) error
+}
+
+// OutboundFilter 出库单列表查询条件
+type OutboundFilter struct {
+	Scope     Scope
+	OrderNo   string
+	Status    string
+	StartDate string
+	EndDate   string
+	Deleted   bool // 只查已删除的出库单
+	Offset    int
+	Limit     int
+}
+
+// OutboundRepository 出库单仓储，按申请人和申请部门做数据权限过滤
+type OutboundRepository interface {
+	List(filter OutboundFilter) ([]model.Outbound, int64, error)
+	Get(scope Scope, id int64) (*model.Outbound, error)
+	GetDeleted(scope Scope, id int64) (*model.Outbound, error)
+	Items(outboundID int64) ([]model.OutboundItem, error)
+	// TotalQuantities 按出库单汇总明细的申请数量
+	TotalQuantities(outboundIDs []int64) (map[int64]float64, error)
+	// Create 创建出库单及明细，回填 ID
+	Create(outbound *model.Outbound, items []model.OutboundItem) error
+	// Update 仅当出库单未完成时更新表头中可修改的字段，否则返回 ErrConflict
+	Update(outbound *model.Outbound) error
+	// SetActualQty 回写明细的实发数量
+	SetActualQty(itemID int64, qty float64) error
+	Delete(id int64) error
+	Restore(id int64) error
+}
+
+// ProductRepository 产品仓储
+type ProductRepository interface {
+	Get(id int64) (*model.Product, error)
+	// FindWithDeleted 按 ID 批量查询产品，包含已删除的产品
+	FindWithDeleted(ids []int64) ([]model.Product, error)
+	// FirstDeletedName 返回 ids 中第一个已删除产品的名称
+	FirstDeletedName(ids []int64) (string, bool, error)
+	// Unit 查询产品的辅助计量单位
+	Unit(productID int64, unit string) (*model.ProductUnit, error)
+	// Match 查询符合条件的启用产品ID，按产品编码排序
+	Match(filter ProductMatch) ([]int64, error)
+}
+
+// ProductMatch 按分类、ABC分类和入库库位区间筛选产品的条件，零值的条件不生效
+type ProductMatch struct {
+	CategoryID   int64
+	AbcClass     string
+	LocationFrom string
+	LocationTo   string
+}
+
+// StockRepository 库存账仓储
+type StockRepository interface {
+	// FreezingCheck 返回冻结了 productIDs 中任一产品的进行中盘点单号
+	FreezingCheck(productIDs []int64) (string, bool, error)
+	// Adjust 按增量调整产品库存，返回调整后的库存
+	Adjust(productID int64, delta float64) (float64, error)
+	// Deduct 扣减产品库存，返回扣减后的库存；库存不足时不扣减并返回 ErrConflict
+	Deduct(productID int64, qty float64) (float64, error)
+	// AddLog 记录库存流水
+	AddLog(log *model.StockLog) error
+	// LastLogID 最新的库存流水ID，没有流水时为0
+	LastLogID() (int64, error)
+	// LastLogIDBefore 不晚于 t 的最新库存流水ID，没有时为0
+	LastLogIDBefore(t time.Time) (int64, error)
+	// MovedSince 产品在 logID 之后的库存流水净变动
+	MovedSince(productID, logID int64) (float64, error)
+}
+
+// UserRepository 用户仓储
+type UserRepository interface {
+	// RealNames 按 ID 批量查询用户姓名
+	RealNames(ids []int64) (map[int64]string, error)
+	// DeptID 查询用户所在部门，用户不存在时返回0
+	DeptID(userID int64) (int64, error)
+}
+
+// DepartmentRepository 部门仓储
+type DepartmentRepository interface {
+	// Names 按 ID 批量查询部门名称
+	Names(ids []int64) (map[int64]string, error)
+}
+
+// ProcurementFilter 采购单列表查询条件
+type ProcurementFilter struct {
+	Scope     Scope
+	OrderNo   string
+	Status    string
+	StartDate string
+	EndDate   string
+	Deleted   bool // 只查已删除的采购单
+	Offset    int
+	Limit     int
+}
+
+// ProcurementRepository 采购单仓储，按申请人做数据权限过滤
+type ProcurementRepository interface {
+	List(filter ProcurementFilter) ([]model.Procurement, int64, error)
+	Get(scope Scope, id int64) (*model.Procurement, error)
+	GetDeleted(scope Scope, id int64) (*model.Procurement, error)
+	Items(procurementID int64) ([]model.ProcurementItem, error)
+	// TotalAmounts 按采购单汇总明细金额
+	TotalAmounts(procurementIDs []int64) (map[int64]float64, error)
+	// Create 创建采购单及明细，回填 ID
+	Create(procurement *model.Procurement, items []model.ProcurementItem) error
+	// Update 更新表头中可修改的字段
+	Update(procurement *model.Procurement) error
+	Delete(id int64) error
+	Restore(id int64) error
+	// OrderNos 按 ID 批量查询采购单号
+	OrderNos(ids []int64) (map[int64]string, error)
+}
+
+// SupplierRepository 供应商仓储
+type SupplierRepository interface {
+	// Names 按 ID 批量查询供应商名称，包含已删除的供应商
+	Names(ids []int64) (map[int64]string, error)
+}
+
+// KitRepository 套件组成仓储
+type KitRepository interface {
+	// Components 套件组成，按录入顺序排列
+	Components(kitID int64) ([]model.KitComponent, error)
+	// IsComponent 产品是否是某个套件的组件
+	IsComponent(productID int64) (bool, error)
+	// ReplaceComponents 用新组成替换套件的全部组成，回填 ID
+	ReplaceComponents(kitID int64, components []model.KitComponent) error
+}
+
+// AssemblyFilter 组装单列表查询条件
+type AssemblyFilter struct {
+	OrderNo string
+	Status  string
+	Deleted bool // 只查已删除的组装单
+	Offset  int
+	Limit   int
+}
+
+// AssemblyRepository 套件组装单仓储
+type AssemblyRepository interface {
+	List(filter AssemblyFilter) ([]model.KitAssembly, int64, error)
+	Get(id int64) (*model.KitAssembly, error)
+	GetDeleted(id int64) (*model.KitAssembly, error)
+	// Create 创建组装单，回填 ID
+	Create(assembly *model.KitAssembly) error
+	// Update 仅当组装单当前状态为 status 时更新可修改的字段，否则返回 ErrConflict
+	Update(assembly *model.KitAssembly, status string) error
+	Delete(id int64) error
+	Restore(id int64) error
+}
+
+// InventoryCheckFilter 盘点单列表查询条件
+type InventoryCheckFilter struct {
+	CheckNo   string
+	Status    string
+	StartDate string
+	EndDate   string
+	Deleted   bool // 只查已删除的盘点单
+	Offset    int
+	Limit     int
+}
+
+// InventoryCheckRepository 盘点单仓储
+type InventoryCheckRepository interface {
+	List(filter InventoryCheckFilter) ([]model.InventoryCheck, int64, error)
+	Get(id int64) (*model.InventoryCheck, error)
+	GetDeleted(id int64) (*model.InventoryCheck, error)
+	// Lock 锁定并重新读取盘点单，须在事务中调用，用于串行化录入、复盘和完成
+	Lock(id int64) (*model.InventoryCheck, error)
+	Items(checkID int64) ([]model.InventoryCheckItem, error)
+	// Create 创建盘点单，回填 ID
+	Create(check *model.InventoryCheck) error
+	// CreateItem 创建盘点明细，回填 ID
+	CreateItem(item *model.InventoryCheckItem) error
+	// CountItem 录入明细第 round 轮的实盘数量，盈亏相对于账面快照计算；
+	// round 大于1时只更新需要复盘的明细，没有命中的明细时返回 ErrNotFound
+	CountItem(checkID, productID int64, round int, qty float64) error
+	// AddCount 保存计数记录
+	AddCount(count *model.InventoryCheckCount) error
+	// LastCount 产品最后一次计数记录，没有时返回 ErrNotFound
+	LastCount(checkID, productID int64) (*model.InventoryCheckCount, error)
+	// StartRound 进入第 round 轮，只有 itemIDs 中的明细需要复盘并重置为未盘
+	StartRound(checkID int64, round int, itemIDs []int64) error
+	// Update 更新表头的状态、备注和盘点日期
+	Update(check *model.InventoryCheck) error
+	Delete(id int64) error
+	Restore(id int64) error
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"log"
+
 	"easywms/internal/config"
 	"easywms/internal/handler"
 	"easywms/internal/middleware"
@@ -13,8 +15,15 @@ import (
 func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 	r := gin.Default()
 
+	// 登录限制和日志按客户端IP记录，只信任配置的反向代理转发的地址
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Printf("Invalid server.trustedProxies, trusting no proxy: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+
 	// 应用中间件
 	r.Use(middleware.CORS(cfg))
+	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger())
 
 	// 健康检查
@@ -31,11 +40,19 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 	productHandler := handler.NewProductHandler(db)
 	supplierHandler := handler.NewSupplierHandler(db)
 	categoryHandler := handler.NewCategoryHandler(db)
-	procurementHandler := handler.NewProcurementHandler(db)
-	inboundHandler := handler.NewInboundHandler(db)
-	outboundHandler := handler.NewOutboundHandler(db)
+	procurementHandler := handler.NewProcurementHandler(db, cfg)
+	inboundHandler := handler.NewInboundHandler(db, cfg)
+	outboundHandler := handler.NewOutboundHandler(db, cfg)
 	stockHandler := handler.NewStockHandler(db)
-	inventoryCheckHandler := handler.NewInventoryCheckHandler(db)
+	inventoryCheckHandler := handler.NewInventoryCheckHandler(db, cfg)
+	cycleCountHandler := handler.NewCycleCountHandler(db, cfg)
+	kitHandler := handler.NewKitHandler(db, cfg)
+	userHandler := handler.NewUserHandler(db, cfg)
+	departmentHandler := handler.NewDepartmentHandler(db)
+	roleHandler := handler.NewRoleHandler(db)
+	menuHandler := handler.NewMenuHandler(db)
+	apiKeyHandler := handler.NewAPIKeyHandler(db)
+	auditHandler := handler.NewAuditHandler(db)
 
 	// API路由组
 	api := r.Group("/api")
@@ -44,16 +61,30 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.RefreshAccessToken)
 			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/login/2fa", authHandler.LoginMFA)
+			auth.GET("/oidc/login", authHandler.OIDCLogin)
+			auth.GET("/oidc/callback", authHandler.OIDCCallback)
 		}
 
 		// 需要认证的路由
 		authorized := api.Group("")
-		authorized.Use(middleware.JWTAuth(cfg))
+		authorized.Use(middleware.JWTAuth(cfg, db))
+		authorized.Use(handler.MFAEnforcement(db, cfg))
+		authorized.Use(handler.PasswordExpiry(db, cfg))
 		{
 			// 用户相关
 			authorized.GET("/user/info", authHandler.GetUserInfo)
 			authorized.GET("/auth/codes", authHandler.GetAccessCodes)
+			authorized.PUT("/auth/password", authHandler.ChangePassword)
+
+			// 两步验证
+			authorized.GET("/auth/2fa/status", authHandler.GetMFAStatus)
+			authorized.POST("/auth/2fa/setup", authHandler.SetupMFA)
+			authorized.POST("/auth/2fa/confirm", authHandler.ConfirmMFA)
+			authorized.POST("/auth/2fa/disable", authHandler.DisableMFA)
+			authorized.POST("/auth/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
 
 			// 仪表盘统计接口
 			authorized.GET("/dashboard/overview", dashboardHandler.GetOverviewStats)
@@ -68,6 +99,11 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 			authorized.POST("/products", productHandler.CreateProduct)
 			authorized.PUT("/products/:id", productHandler.UpdateProduct)
 			authorized.DELETE("/products/:id", productHandler.DeleteProduct)
+			authorized.POST("/products/:id/restore", productHandler.RestoreProduct)
+			authorized.GET("/products/:id/units", productHandler.GetProductUnits)
+			authorized.PUT("/products/:id/units", productHandler.SaveProductUnits)
+			authorized.GET("/products/:id/kit", kitHandler.GetKit)
+			authorized.PUT("/products/:id/kit", kitHandler.SaveKit)
 
 			// 基础数据管理 - 供应商
 			authorized.GET("/suppliers", supplierHandler.GetSupplierList)
@@ -75,6 +111,7 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 			authorized.POST("/suppliers", supplierHandler.CreateSupplier)
 			authorized.PUT("/suppliers/:id", supplierHandler.UpdateSupplier)
 			authorized.DELETE("/suppliers/:id", supplierHandler.DeleteSupplier)
+			authorized.POST("/suppliers/:id/restore", supplierHandler.RestoreSupplier)
 
 			// 基础数据管理 - 分类
 			authorized.GET("/categories", categoryHandler.GetCategoryList)
@@ -83,27 +120,42 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 			authorized.POST("/categories", categoryHandler.CreateCategory)
 			authorized.PUT("/categories/:id", categoryHandler.UpdateCategory)
 			authorized.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+			authorized.POST("/categories/:id/restore", categoryHandler.RestoreCategory)
+
+			// 创建单据和变动库存的接口支持 Idempotency-Key 防重复提交
+			idempotent := middleware.Idempotency(cfg, db)
 
 			// 采购管理
 			authorized.GET("/procurements", procurementHandler.GetProcurementList)
 			authorized.GET("/procurements/:id", procurementHandler.GetProcurement)
-			authorized.POST("/procurements", procurementHandler.CreateProcurement)
+			authorized.POST("/procurements", idempotent, procurementHandler.CreateProcurement)
 			authorized.PUT("/procurements/:id", procurementHandler.UpdateProcurement)
 			authorized.DELETE("/procurements/:id", procurementHandler.DeleteProcurement)
+			authorized.POST("/procurements/:id/restore", procurementHandler.RestoreProcurement)
 
 			// 入库管理
 			authorized.GET("/inbounds", inboundHandler.GetInboundList)
 			authorized.GET("/inbounds/:id", inboundHandler.GetInbound)
-			authorized.POST("/inbounds", inboundHandler.CreateInbound)
-			authorized.PUT("/inbounds/:id", inboundHandler.UpdateInbound)
+			authorized.POST("/inbounds", idempotent, inboundHandler.CreateInbound)
+			authorized.PUT("/inbounds/:id", idempotent, inboundHandler.UpdateInbound)
 			authorized.DELETE("/inbounds/:id", inboundHandler.DeleteInbound)
+			authorized.POST("/inbounds/:id/restore", inboundHandler.RestoreInbound)
 
 			// 出库管理
 			authorized.GET("/outbounds", outboundHandler.GetOutboundList)
 			authorized.GET("/outbounds/:id", outboundHandler.GetOutbound)
-			authorized.POST("/outbounds", outboundHandler.CreateOutbound)
-			authorized.PUT("/outbounds/:id", outboundHandler.UpdateOutbound)
+			authorized.POST("/outbounds", idempotent, outboundHandler.CreateOutbound)
+			authorized.PUT("/outbounds/:id", idempotent, outboundHandler.UpdateOutbound)
 			authorized.DELETE("/outbounds/:id", outboundHandler.DeleteOutbound)
+			authorized.POST("/outbounds/:id/restore", outboundHandler.RestoreOutbound)
+
+			// 套件组装
+			authorized.GET("/kit-assemblies", kitHandler.GetAssemblyList)
+			authorized.GET("/kit-assemblies/:id", kitHandler.GetAssembly)
+			authorized.POST("/kit-assemblies", idempotent, kitHandler.CreateAssembly)
+			authorized.PUT("/kit-assemblies/:id", idempotent, kitHandler.UpdateAssembly)
+			authorized.DELETE("/kit-assemblies/:id", kitHandler.DeleteAssembly)
+			authorized.POST("/kit-assemblies/:id/restore", kitHandler.RestoreAssembly)
 
 			// 库存管理
 			authorized.GET("/inventory/stock", stockHandler.GetStockList)
@@ -113,12 +165,75 @@ func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
 			// 盘点管理
 			authorized.GET("/inventory/checks", inventoryCheckHandler.GetInventoryCheckList)
 			authorized.GET("/inventory/checks/:id", inventoryCheckHandler.GetInventoryCheck)
-			authorized.POST("/inventory/checks", inventoryCheckHandler.CreateInventoryCheck)
-			authorized.PUT("/inventory/checks/:id", inventoryCheckHandler.UpdateInventoryCheck)
+			authorized.POST("/inventory/checks", idempotent, inventoryCheckHandler.CreateInventoryCheck)
+			authorized.POST("/inventory/checks/generate", idempotent, inventoryCheckHandler.GenerateInventoryCheck)
+			authorized.POST("/inventory/checks/:id/recount", idempotent, inventoryCheckHandler.RecountInventoryCheck)
+			authorized.PUT("/inventory/checks/:id", idempotent, inventoryCheckHandler.UpdateInventoryCheck)
 			authorized.DELETE("/inventory/checks/:id", inventoryCheckHandler.DeleteInventoryCheck)
+			authorized.POST("/inventory/checks/:id/restore", inventoryCheckHandler.RestoreInventoryCheck)
+
+			// 循环盘点
+			inventoryCheck := handler.RequirePermission(db, "INVENTORY_CHECK")
+			authorized.POST("/inventory/abc/classify", inventoryCheck, cycleCountHandler.ClassifyABC)
+			authorized.GET("/inventory/cycle-count", cycleCountHandler.GetCycleCountStatus)
+			authorized.POST("/inventory/cycle-count/plan", inventoryCheck, idempotent, cycleCountHandler.PlanCycleCount)
+
+			// 用户管理
+			userManage := handler.RequirePermission(db, "USER_MANAGE")
+			authorized.GET("/users", userManage, userHandler.GetUserList)
+			authorized.GET("/users/:id", userManage, userHandler.GetUser)
+			authorized.POST("/users", userManage, userHandler.CreateUser)
+			authorized.PUT("/users/:id", userManage, userHandler.UpdateUser)
+			authorized.DELETE("/users/:id", userManage, userHandler.DeleteUser)
+			authorized.PUT("/users/:id/password", userManage, userHandler.ResetPassword)
+			authorized.POST("/users/:id/unlock", userManage, userHandler.UnlockUser)
+			authorized.POST("/users/:id/ldap-link", userManage, userHandler.LinkDirectoryUser)
+			authorized.GET("/login-locks", userManage, userHandler.GetLoginLocks)
+			authorized.DELETE("/login-locks/:id", userManage, userHandler.UnlockLogin)
+			authorized.GET("/login-logs", userManage, userHandler.GetLoginLogs)
+
+			// 部门管理
+			departmentManage := handler.RequirePermission(db, "DEPARTMENT_MANAGE")
+			authorized.GET("/departments", departmentHandler.GetDepartmentList)
+			authorized.GET("/departments/tree", departmentHandler.GetDepartmentTree)
+			authorized.GET("/departments/:id", departmentHandler.GetDepartment)
+			authorized.POST("/departments", departmentManage, departmentHandler.CreateDepartment)
+			authorized.PUT("/departments/:id", departmentManage, departmentHandler.UpdateDepartment)
+			authorized.DELETE("/departments/:id", departmentManage, departmentHandler.DeleteDepartment)
+
+			// 角色权限管理
+			roleManage := handler.RequirePermission(db, "ROLE_MANAGE")
+			authorized.GET("/roles", roleManage, roleHandler.GetRoleList)
+			authorized.GET("/roles/:id", roleManage, roleHandler.GetRole)
+			authorized.POST("/roles", roleManage, roleHandler.CreateRole)
+			authorized.PUT("/roles/:id", roleManage, roleHandler.UpdateRole)
+			authorized.DELETE("/roles/:id", roleManage, roleHandler.DeleteRole)
+			authorized.PUT("/roles/:id/permissions", roleManage, roleHandler.SaveRolePermissions)
+			authorized.GET("/permissions", roleManage, roleHandler.GetPermissionList)
 
 			// 菜单接口
-			authorized.GET("/menu/all", authHandler.GetMenus)
+			authorized.GET("/menu/all", menuHandler.GetMenus)
+
+			// 菜单管理
+			menuManage := handler.RequirePermission(db, "MENU_MANAGE")
+			authorized.GET("/menus", menuManage, menuHandler.GetMenuTree)
+			authorized.POST("/menus", menuManage, menuHandler.CreateMenu)
+			authorized.PUT("/menus/sort", menuManage, menuHandler.SortMenus)
+			authorized.PUT("/menus/:id", menuManage, menuHandler.UpdateMenu)
+			authorized.DELETE("/menus/:id", menuManage, menuHandler.DeleteMenu)
+
+			// API密钥管理
+			apiKeyManage := handler.RequirePermission(db, "API_KEY_MANAGE")
+			authorized.GET("/api-keys", apiKeyManage, apiKeyHandler.GetAPIKeyList)
+			authorized.POST("/api-keys", apiKeyManage, apiKeyHandler.CreateAPIKey)
+			authorized.PUT("/api-keys/:id", apiKeyManage, apiKeyHandler.UpdateAPIKey)
+			authorized.DELETE("/api-keys/:id", apiKeyManage, apiKeyHandler.DeleteAPIKey)
+			authorized.GET("/api-keys/:id/logs", apiKeyManage, apiKeyHandler.GetAPIKeyLogs)
+
+			// 审计日志
+			auditView := handler.RequirePermission(db, "AUDIT_VIEW")
+			authorized.GET("/audit-logs", auditView, auditHandler.GetAuditLogs)
+			authorized.GET("/audit-logs/:id", auditView, auditHandler.GetAuditLog)
 		}
 	}
 
@@ -0,0 +1,137 @@
+package sequence
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"easywms/internal/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 单据类型
+const (
+	Procurement    = "procurement"
+	Inbound        = "inbound"
+	Outbound       = "outbound"
+	InventoryCheck = "inventoryCheck"
+	KitAssembly    = "kitAssembly"
+)
+
+// defaultFormats 未配置时的编号格式
+var defaultFormats = map[string]string{
+	Procurement:    "PO-{date}-{seq:4}",
+	Inbound:        "IN-{date}-{seq:4}",
+	Outbound:       "OUT-{date}-{seq:4}",
+	InventoryCheck: "CHK-{date}-{seq:4}",
+	KitAssembly:    "KIT-{date}-{seq:4}",
+}
+
+// seqPattern 匹配流水号占位符 {seq} 或 {seq:宽度}
+var seqPattern = regexp.MustCompile(`\{seq(?::(\d+))?\}`)
+
+// Counter 单据编号计数器，每个单据类型的每个前缀（通常按天）一行
+type Counter struct {
+	ID           int64     `gorm:"column:id;primaryKey"`
+	DocType      string    `gorm:"column:doc_type"`
+	Prefix       string    `gorm:"column:prefix"`
+	CurrentValue int64     `gorm:"column:current_value"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Counter) TableName() string {
+	return "sys_doc_sequence"
+}
+
+// Generator 单据编号生成器
+type Generator struct {
+	warehouse string
+	formats   map[string]string
+}
+
+// New 按配置创建编号生成器。格式支持占位符:
+// {wh} 仓库代码、{date} 日期 yyyyMMdd、{seq:N} N位流水号，
+// 例如 IN-{wh}-{date}-{seq:4} 生成 IN-WH1-20261017-0001
+func New(cfg config.NumberingConfig) *Generator {
+	formats := make(map[string]string, len(defaultFormats))
+	for docType, format := range defaultFormats {
+		formats[docType] = format
+	}
+	for docType, format := range cfg.Formats {
+		// viper 读取时键名会转为小写
+		docType = canonicalType(docType)
+		if _, ok := defaultFormats[docType]; !ok {
+			log.Printf("numbering: unknown document type %q ignored", docType)
+			continue
+		}
+		if !seqPattern.MatchString(format) {
+			log.Printf("numbering: format %q for %s has no {seq} placeholder, using default", format, docType)
+			continue
+		}
+		formats[docType] = format
+	}
+	return &Generator{warehouse: cfg.Warehouse, formats: formats}
+}
+
+// Next 在调用方事务中分配下一个单据编号。
+// 计数器与单据在同一事务内提交，单据创建失败回滚时编号一并释放，不产生断号；
+// 计数器行由 upsert 自增并持有行锁直到事务结束，并发请求依次取号，不会重复
+func (g *Generator) Next(tx *gorm.DB, docType string) (string, error) {
+	format, ok := g.formats[docType]
+	if !ok {
+		return "", fmt.Errorf("numbering: unknown document type %q", docType)
+	}
+
+	now := time.Now()
+	rendered := g.render(format, now)
+	prefix := seqPattern.ReplaceAllString(rendered, "{seq}")
+
+	counter := Counter{DocType: docType, Prefix: prefix, CurrentValue: 1}
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "doc_type"}, {Name: "prefix"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"current_value": gorm.Expr("current_value + 1"),
+			"updated_at":    now,
+		}),
+	}).Create(&counter).Error
+	if err != nil {
+		return "", fmt.Errorf("numbering: allocate %s: %w", docType, err)
+	}
+
+	// 取回自增后的值，事务内可见本次更新
+	var current Counter
+	if err := tx.Where("doc_type = ? AND prefix = ?", docType, prefix).First(&current).Error; err != nil {
+		return "", fmt.Errorf("numbering: read %s counter: %w", docType, err)
+	}
+
+	return seqPattern.ReplaceAllStringFunc(rendered, func(token string) string {
+		width := 4
+		if m := seqPattern.FindStringSubmatch(token); m[1] != "" {
+			width, _ = strconv.Atoi(m[1])
+		}
+		return fmt.Sprintf("%0*d", width, current.CurrentValue)
+	}), nil
+}
+
+// render 替换流水号以外的占位符
+func (g *Generator) render(format string, now time.Time) string {
+	return strings.NewReplacer(
+		"{wh}", g.warehouse,
+		"{date}", now.Format("20060102"),
+	).Replace(format)
+}
+
+// canonicalType 将配置中的类型名还原为单据类型常量
+func canonicalType(name string) string {
+	for docType := range defaultFormats {
+		if strings.EqualFold(docType, name) {
+			return docType
+		}
+	}
+	return name
+}
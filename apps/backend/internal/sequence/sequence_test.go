@@ -0,0 +1,100 @@
+package sequence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"easywms/internal/config"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec(`CREATE TABLE sys_doc_sequence (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		doc_type VARCHAR(32) NOT NULL,
+		prefix VARCHAR(64) NOT NULL,
+		current_value BIGINT NOT NULL DEFAULT 0,
+		updated_at DATETIME
+	)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	db.Exec("CREATE UNIQUE INDEX uk_type_prefix ON sys_doc_sequence (doc_type, prefix)")
+	return db
+}
+
+func next(t *testing.T, db *gorm.DB, g *Generator, docType string) string {
+	t.Helper()
+	var no string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		no, err = g.Next(tx, docType)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("next %s: %v", docType, err)
+	}
+	return no
+}
+
+func TestNextFormatsAndIncrementsPerType(t *testing.T) {
+	db := newTestDB(t)
+	g := New(config.NumberingConfig{
+		Warehouse: "WH1",
+		// viper 读出的键为小写
+		Formats: map[string]string{"inbound": "IN-{wh}-{date}-{seq:4}"},
+	})
+	today := time.Now().Format("20060102")
+
+	if got, want := next(t, db, g, Inbound), "IN-WH1-"+today+"-0001"; got != want {
+		t.Errorf("first inbound = %s, want %s", got, want)
+	}
+	if got, want := next(t, db, g, Inbound), "IN-WH1-"+today+"-0002"; got != want {
+		t.Errorf("second inbound = %s, want %s", got, want)
+	}
+	if got, want := next(t, db, g, Outbound), "OUT-"+today+"-0001"; got != want {
+		t.Errorf("first outbound = %s, want %s", got, want)
+	}
+}
+
+func TestNextReleasesNumberOnRollback(t *testing.T) {
+	db := newTestDB(t)
+	g := New(config.NumberingConfig{})
+
+	first := next(t, db, g, Procurement)
+
+	errAbort := errors.New("abort")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := g.Next(tx, Procurement); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("transaction error = %v", err)
+	}
+
+	second := next(t, db, g, Procurement)
+	if first[len(first)-4:] != "0001" || second[len(second)-4:] != "0002" {
+		t.Errorf("numbers = %s, %s; rolled back number should be reused", first, second)
+	}
+}
+
+func TestNewIgnoresFormatWithoutSeq(t *testing.T) {
+	g := New(config.NumberingConfig{Formats: map[string]string{"outbound": "OUT-{date}"}})
+	if g.formats[Outbound] != defaultFormats[Outbound] {
+		t.Errorf("format = %q, want default", g.formats[Outbound])
+	}
+}
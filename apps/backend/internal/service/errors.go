@@ -0,0 +1,42 @@
+// Package service 业务服务层，承载单据流转和库存记账等业务规则。
+// 服务只依赖 repository 中的接口，可在接口层、命令行工具和后台任务中复用
+package service
+
+import "errors"
+
+// Kind 业务错误类别，接口层据此选择响应状态码
+type Kind int
+
+const (
+	// KindInvalid 请求不满足业务规则
+	KindInvalid Kind = iota + 1
+	// KindNotFound 操作的对象不存在
+	KindNotFound
+)
+
+// Error 业务错误，Message 可直接展示给用户
+type Error struct {
+	Kind    Kind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func invalid(message string) error {
+	return &Error{Kind: KindInvalid, Message: message}
+}
+
+func notFound(message string) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+// KindOf 返回业务错误的类别，非业务错误返回0
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return 0
+}
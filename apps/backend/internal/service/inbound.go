@@ -0,0 +1,294 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+)
+
+// 入库单状态
+const (
+	InboundDraft     = 0
+	InboundCompleted = 1
+)
+
+// InboundItemInput 入库明细，数量按 Unit 计，Unit 为空时为基本单位
+type InboundItemInput struct {
+	ProductID int64
+	Quantity  float64
+	Unit      string
+	Location  string
+}
+
+// InboundInput 创建或修改入库单的内容
+type InboundInput struct {
+	SourceID    *int64
+	IsTemporary int
+	Remark      string
+	Items       []InboundItemInput
+	// Complete 修改时确认入库，入库数量计入库存
+	Complete bool
+}
+
+// InboundService 入库单业务
+type InboundService struct {
+	store repository.Store
+}
+
+// NewInboundService 创建入库单业务服务
+func NewInboundService(store repository.Store) *InboundService {
+	return &InboundService{store: store}
+}
+
+// List 查询入库单列表，并填充经办人、来源采购单号、总数量等展示字段
+func (s *InboundService) List(filter repository.InboundFilter) ([]model.Inbound, int64, error) {
+	inbounds, total, err := s.store.Inbounds().List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0)
+	sourceIDs := make([]int64, 0)
+	inboundIDs := make([]int64, 0, len(inbounds))
+	for _, i := range inbounds {
+		if i.WarehouseUserID != nil {
+			userIDs = append(userIDs, *i.WarehouseUserID)
+		}
+		if i.SourceID != nil {
+			sourceIDs = append(sourceIDs, *i.SourceID)
+		}
+		inboundIDs = append(inboundIDs, i.ID)
+	}
+
+	userMap, err := s.store.Users().RealNames(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	sourceMap, err := s.store.Procurements().OrderNos(sourceIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	qtyMap, err := s.store.Inbounds().TotalQuantities(inboundIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range inbounds {
+		if inbounds[i].WarehouseUserID != nil {
+			inbounds[i].OperatorName = userMap[*inbounds[i].WarehouseUserID]
+		}
+		inbounds[i].Type = "other"
+		if inbounds[i].SourceID != nil {
+			inbounds[i].SourceOrderNo = sourceMap[*inbounds[i].SourceID]
+			inbounds[i].Type = "purchase"
+		}
+		inbounds[i].TotalQuantity = qtyMap[inbounds[i].ID]
+		inbounds[i].Status_ = InboundStatusName(inbounds[i].Status)
+		inbounds[i].WarehouseName = "默认仓库"
+	}
+	return inbounds, total, nil
+}
+
+// Get 查询入库单及明细，明细填充产品名称和编码（包括已删除的产品）
+func (s *InboundService) Get(id int64) (*model.Inbound, []model.InboundItem, error) {
+	inbound, err := s.get(s.store, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.store.Inbounds().Items(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productMap, err := productsByID(s.store, productIDs(items))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		if p, ok := productMap[items[i].ProductID]; ok {
+			items[i].ProductName = p.Name
+			items[i].ProductCode = p.SKUCode
+		}
+	}
+	return inbound, items, nil
+}
+
+// Create 创建草稿入库单，入库日期为当前时间
+func (s *InboundService) Create(operatorID int64, in InboundInput) (*model.Inbound, error) {
+	now := time.Now()
+	inbound := &model.Inbound{
+		SourceID:        in.SourceID,
+		IsTemporary:     in.IsTemporary,
+		Status:          InboundDraft,
+		InboundDate:     &now,
+		WarehouseUserID: &operatorID,
+		Remark:          in.Remark,
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		items, err := s.items(tx, in.Items)
+		if err != nil {
+			return err
+		}
+		if inbound.InboundNo, err = tx.NextNumber(sequence.Inbound); err != nil {
+			return fmt.Errorf("生成单号失败: %w", err)
+		}
+		return tx.Inbounds().Create(inbound, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inbound, nil
+}
+
+// Update 修改草稿入库单。Items 非空时替换明细；
+// Complete 为真时确认入库，按明细增加库存并记录流水，未提交明细时按已保存的明细入库
+func (s *InboundService) Update(id int64, in InboundInput) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		inbound, err := s.get(tx, id)
+		if err != nil {
+			return err
+		}
+		if inbound.Status == InboundCompleted {
+			return invalid("已完成的入库单不能修改")
+		}
+
+		items, err := s.items(tx, in.Items)
+		if err != nil {
+			return err
+		}
+
+		inbound.SourceID = in.SourceID
+		inbound.IsTemporary = in.IsTemporary
+		inbound.Remark = in.Remark
+
+		if in.Complete {
+			now := time.Now()
+			inbound.Status = InboundCompleted
+			inbound.InboundDate = &now
+		}
+
+		// 先以草稿状态为条件更新表头，并发确认时只有一个请求能命中并记账
+		if err := tx.Inbounds().Update(inbound, InboundDraft); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return invalid("已完成的入库单不能修改")
+			}
+			return err
+		}
+
+		if in.Complete {
+			posting := items
+			if len(posting) == 0 {
+				if posting, err = tx.Inbounds().Items(id); err != nil {
+					return err
+				}
+			}
+			if err := s.post(tx, inbound, posting); err != nil {
+				return err
+			}
+		}
+		if len(items) > 0 {
+			return tx.Inbounds().ReplaceItems(id, items)
+		}
+		return nil
+	})
+}
+
+// Delete 删除草稿入库单（软删除，明细保留以便恢复）
+func (s *InboundService) Delete(id int64) error {
+	inbound, err := s.get(s.store, id)
+	if err != nil {
+		return err
+	}
+	if inbound.Status == InboundCompleted {
+		return invalid("已完成的入库单不能删除")
+	}
+	return s.store.Inbounds().Delete(id)
+}
+
+// Restore 恢复已删除的入库单，明细中的产品已删除时不能恢复
+func (s *InboundService) Restore(id int64) error {
+	if _, err := s.store.Inbounds().GetDeleted(id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("已删除的入库单不存在")
+		}
+		return err
+	}
+
+	items, err := s.store.Inbounds().Items(id)
+	if err != nil {
+		return err
+	}
+	if err := checkDeletedProducts(s.store, productIDs(items)); err != nil {
+		return err
+	}
+	return s.store.Inbounds().Restore(id)
+}
+
+// get 查询未删除的入库单
+func (s *InboundService) get(store repository.Store, id int64) (*model.Inbound, error) {
+	inbound, err := store.Inbounds().Get(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("入库单不存在")
+	}
+	return inbound, err
+}
+
+// items 把明细数量换算为基本单位
+func (s *InboundService) items(store repository.Store, inputs []InboundItemInput) ([]model.InboundItem, error) {
+	items := make([]model.InboundItem, 0, len(inputs))
+	for _, input := range inputs {
+		factor, unit, err := UnitFactor(store, input.ProductID, input.Unit)
+		if err != nil {
+			return nil, err
+		}
+		unitQty := input.Quantity
+		items = append(items, model.InboundItem{
+			ProductID: input.ProductID,
+			ActualQty: input.Quantity * factor,
+			Unit:      unit,
+			UnitQty:   &unitQty,
+			Location:  input.Location,
+		})
+	}
+	return items, nil
+}
+
+// post 确认入库：冻结盘点中的产品不允许入库，其余按明细增加库存并记录流水
+func (s *InboundService) post(tx repository.Store, inbound *model.Inbound, items []model.InboundItem) error {
+	checkNo, frozen, err := tx.Stock().FreezingCheck(productIDs(items))
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return invalid("产品正在盘点中(" + checkNo + ")，暂不能入库")
+	}
+
+	for _, item := range items {
+		if err := PostStock(tx, item.ProductID, "IN", item.ActualQty, inbound.InboundNo, inbound.WarehouseUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InboundStatusName 入库单状态的接口表示
+func InboundStatusName(status int) string {
+	if status == InboundCompleted {
+		return "completed"
+	}
+	return "draft"
+}
+
+// productIDs 明细中的产品ID
+func productIDs(items []model.InboundItem) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
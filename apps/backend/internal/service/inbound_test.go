@@ -0,0 +1,262 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// newInboundFixture 产品1基本单位为“个”，1箱折合12个；产品2为“件”
+func newInboundFixture() (*fakeStore, *InboundService) {
+	store := newFakeStore()
+	store.data.products[1] = model.Product{ID: 1, Name: "螺丝", SKUCode: "SKU-1", Unit: "个", StockQty: 10}
+	store.data.products[2] = model.Product{ID: 2, Name: "扳手", SKUCode: "SKU-2", Unit: "件"}
+	store.data.units["1/箱"] = model.ProductUnit{ProductID: 1, Unit: "箱", Factor: 12}
+	store.data.users[7] = "仓管员"
+	store.data.procurements[3] = model.Procurement{ID: 3, OrderNo: "PO-0003"}
+	return store, NewInboundService(store)
+}
+
+func wantKind(t *testing.T, err error, kind Kind, message string) {
+	t.Helper()
+	if KindOf(err) != kind || err.Error() != message {
+		t.Fatalf("err = %v, want kind %d %q", err, kind, message)
+	}
+}
+
+func TestInboundCreateConvertsUnits(t *testing.T) {
+	store, svc := newInboundFixture()
+
+	inbound, err := svc.Create(7, InboundInput{Items: []InboundItemInput{
+		{ProductID: 1, Quantity: 2, Unit: "箱"},
+		{ProductID: 2, Quantity: 3},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if inbound.InboundNo == "" || inbound.Status != InboundDraft || *inbound.WarehouseUserID != 7 {
+		t.Errorf("inbound = %+v", inbound)
+	}
+
+	items := store.data.inboundItems[inbound.ID]
+	if len(items) != 2 || items[0].ActualQty != 24 || items[0].Unit != "箱" || *items[0].UnitQty != 2 ||
+		items[1].ActualQty != 3 || items[1].Unit != "件" {
+		t.Errorf("items = %+v", items)
+	}
+	if store.data.products[1].StockQty != 10 || len(store.data.logs) != 0 {
+		t.Error("draft inbound must not change stock")
+	}
+}
+
+func TestInboundCreateRejectsUnknownUnit(t *testing.T) {
+	store, svc := newInboundFixture()
+
+	_, err := svc.Create(7, InboundInput{Items: []InboundItemInput{
+		{ProductID: 1, Quantity: 1},
+		{ProductID: 2, Quantity: 1, Unit: "箱"},
+	}})
+	wantKind(t, err, KindInvalid, "产品扳手未定义计量单位: 箱")
+
+	_, err = svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 9, Quantity: 1}}})
+	wantKind(t, err, KindInvalid, "产品不存在: 9")
+
+	if len(store.data.inbounds) != 0 || store.data.nextNo != 0 {
+		t.Error("rejected inbound must not be saved or consume a number")
+	}
+}
+
+func TestInboundCompletePostsStock(t *testing.T) {
+	store, svc := newInboundFixture()
+	inbound, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 2, Quantity: 1}}})
+
+	err := svc.Update(inbound.ID, InboundInput{
+		SourceID: ptr(int64(3)),
+		Remark:   "到货",
+		Complete: true,
+		Items:    []InboundItemInput{{ProductID: 1, Quantity: 1, Unit: "箱"}, {ProductID: 1, Quantity: 5}},
+	})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	saved := store.data.inbounds[inbound.ID]
+	if saved.Status != InboundCompleted || saved.Remark != "到货" || *saved.SourceID != 3 {
+		t.Errorf("inbound = %+v", saved)
+	}
+	if qty := store.data.products[1].StockQty; qty != 27 {
+		t.Errorf("stock = %v, want 27", qty)
+	}
+	if qty := store.data.products[2].StockQty; qty != 0 {
+		t.Errorf("replaced item posted: stock = %v", qty)
+	}
+
+	logs := store.data.logs
+	if len(logs) != 2 {
+		t.Fatalf("logs = %+v", logs)
+	}
+	if l := logs[0]; l.Type != "IN" || l.ChangeQty != 12 || l.SnapshotQty != 22 ||
+		l.RelatedNo != inbound.InboundNo || *l.OperatorID != 7 {
+		t.Errorf("first log = %+v", l)
+	}
+	if l := logs[1]; l.ChangeQty != 5 || l.SnapshotQty != 27 {
+		t.Errorf("second log = %+v", l)
+	}
+
+	wantKind(t, svc.Update(inbound.ID, InboundInput{Complete: true}), KindInvalid, "已完成的入库单不能修改")
+	wantKind(t, svc.Delete(inbound.ID), KindInvalid, "已完成的入库单不能删除")
+	if len(store.data.logs) != 2 {
+		t.Error("completed inbound posted twice")
+	}
+}
+
+// staleStore 读取入库单时总是返回旧快照，模拟并发请求在对方提交前读到的草稿状态
+type staleStore struct {
+	*fakeStore
+	snapshot model.Inbound
+}
+
+func (s staleStore) Inbounds() repository.InboundRepository {
+	return staleInbounds{fakeInbounds{s.data}, s.snapshot}
+}
+
+func (s staleStore) Transaction(fn func(tx repository.Store) error) error {
+	return s.fakeStore.Transaction(func(tx repository.Store) error {
+		return fn(staleStore{tx.(*fakeStore), s.snapshot})
+	})
+}
+
+type staleInbounds struct {
+	fakeInbounds
+	snapshot model.Inbound
+}
+
+func (r staleInbounds) Get(id int64) (*model.Inbound, error) {
+	inbound := r.snapshot
+	return &inbound, nil
+}
+
+func TestInboundConcurrentCompletePostsOnce(t *testing.T) {
+	store, svc := newInboundFixture()
+	inbound, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 1, Quantity: 5}}})
+	draft := store.data.inbounds[inbound.ID]
+
+	if err := svc.Update(inbound.ID, InboundInput{Complete: true}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	// 第二个请求在第一个提交前读到了草稿状态，条件更新不命中，不能再次记账
+	err := NewInboundService(staleStore{store, draft}).Update(inbound.ID, InboundInput{Complete: true})
+	wantKind(t, err, KindInvalid, "已完成的入库单不能修改")
+
+	if qty := store.data.products[1].StockQty; qty != 15 || len(store.data.logs) != 1 {
+		t.Errorf("stock = %v, logs = %+v; completed inbound posted twice", qty, store.data.logs)
+	}
+}
+
+func TestInboundCompleteWithoutItemsPostsSavedItems(t *testing.T) {
+	store, svc := newInboundFixture()
+	inbound, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 1, Quantity: 2, Unit: "箱"}}})
+
+	if err := svc.Update(inbound.ID, InboundInput{Complete: true}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if qty := store.data.products[1].StockQty; qty != 34 {
+		t.Errorf("stock = %v, want 34", qty)
+	}
+	if items := store.data.inboundItems[inbound.ID]; len(items) != 1 {
+		t.Errorf("saved items replaced: %+v", items)
+	}
+}
+
+func TestInboundCompleteBlockedByFreezingCheck(t *testing.T) {
+	store, svc := newInboundFixture()
+	inbound, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 1, Quantity: 1}}})
+	store.data.frozen[1] = "CHK-0001"
+
+	err := svc.Update(inbound.ID, InboundInput{Complete: true})
+	wantKind(t, err, KindInvalid, "产品正在盘点中(CHK-0001)，暂不能入库")
+
+	if store.data.inbounds[inbound.ID].Status != InboundDraft || store.data.products[1].StockQty != 10 || len(store.data.logs) != 0 {
+		t.Error("blocked completion must not change anything")
+	}
+
+	// 不确认入库的修改不受盘点冻结影响
+	if err := svc.Update(inbound.ID, InboundInput{Remark: "待盘点结束"}); err != nil {
+		t.Errorf("draft update: %v", err)
+	}
+}
+
+func TestInboundNotFound(t *testing.T) {
+	_, svc := newInboundFixture()
+
+	_, _, err := svc.Get(1)
+	wantKind(t, err, KindNotFound, "入库单不存在")
+	wantKind(t, svc.Update(1, InboundInput{}), KindNotFound, "入库单不存在")
+	wantKind(t, svc.Delete(1), KindNotFound, "入库单不存在")
+	wantKind(t, svc.Restore(1), KindNotFound, "已删除的入库单不存在")
+}
+
+func TestInboundRestoreRejectsDeletedProduct(t *testing.T) {
+	store, svc := newInboundFixture()
+	inbound, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 2, Quantity: 1}}})
+	if err := svc.Delete(inbound.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	product := store.data.products[2]
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	store.data.products[2] = product
+	wantKind(t, svc.Restore(inbound.ID), KindInvalid, "明细中的产品已删除: 扳手")
+
+	product.DeletedAt = gorm.DeletedAt{}
+	store.data.products[2] = product
+	if err := svc.Restore(inbound.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if _, _, err := svc.Get(inbound.ID); err != nil {
+		t.Errorf("restored inbound: %v", err)
+	}
+}
+
+func TestInboundListAndGetFillDisplayFields(t *testing.T) {
+	store, svc := newInboundFixture()
+	purchase, _ := svc.Create(7, InboundInput{SourceID: ptr(int64(3)), Items: []InboundItemInput{
+		{ProductID: 1, Quantity: 1, Unit: "箱"}, {ProductID: 2, Quantity: 2},
+	}})
+	other, _ := svc.Create(7, InboundInput{Items: []InboundItemInput{{ProductID: 2, Quantity: 1}}})
+	svc.Update(other.ID, InboundInput{Complete: true})
+
+	inbounds, total, err := svc.List(repository.InboundFilter{})
+	if err != nil || total != 2 {
+		t.Fatalf("list = %d, %v", total, err)
+	}
+	if i := inbounds[1]; i.ID != purchase.ID || i.Type != "purchase" || i.SourceOrderNo != "PO-0003" ||
+		i.OperatorName != "仓管员" || i.TotalQuantity != 14 || i.Status_ != "draft" || i.WarehouseName != "默认仓库" {
+		t.Errorf("purchase inbound = %+v", i)
+	}
+	if i := inbounds[0]; i.Type != "other" || i.Status_ != "completed" {
+		t.Errorf("other inbound = %+v", i)
+	}
+
+	completed := InboundCompleted
+	if _, total, _ := svc.List(repository.InboundFilter{Status: &completed}); total != 1 {
+		t.Errorf("completed total = %d", total)
+	}
+
+	// 详情中已删除产品仍显示名称
+	product := store.data.products[2]
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	store.data.products[2] = product
+	_, items, err := svc.Get(purchase.ID)
+	if err != nil || len(items) != 2 || items[1].ProductName != "扳手" || items[0].ProductCode != "SKU-1" {
+		t.Errorf("items = %+v, %v", items, err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+)
+
+// InventoryCheckItemInput 盘点明细，ActualQty 不为空时视为第一轮已盘
+type InventoryCheckItemInput struct {
+	ProductID int64
+	ActualQty *float64
+}
+
+// InventoryCheckInput 创建盘点单的内容，CheckerID 为空时表示由计划任务生成
+type InventoryCheckInput struct {
+	CheckerID  *int64
+	Freeze     bool
+	Tolerance  *float64
+	CycleClass *string
+	Remark     string
+	Items      []InventoryCheckItemInput
+}
+
+// InventoryCountInput 录入的实盘数量
+type InventoryCountInput struct {
+	ProductID int64
+	Quantity  float64
+}
+
+// InventoryCheckUpdate 修改盘点单的内容，Status 为数据库中的状态，为空时保留原状态
+type InventoryCheckUpdate struct {
+	Status string
+	Remark string
+	Counts []InventoryCountInput
+}
+
+// InventoryCheckService 盘点单业务
+type InventoryCheckService struct {
+	store repository.Store
+}
+
+// NewInventoryCheckService 创建盘点单业务服务
+func NewInventoryCheckService(store repository.Store) *InventoryCheckService {
+	return &InventoryCheckService{store: store}
+}
+
+// List 查询盘点单列表，并填充盘点人和仓库，状态转为接口表示
+func (s *InventoryCheckService) List(filter repository.InventoryCheckFilter) ([]model.InventoryCheck, int64, error) {
+	checks, total, err := s.store.InventoryChecks().List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0)
+	for _, check := range checks {
+		if check.CheckerID != nil {
+			userIDs = append(userIDs, *check.CheckerID)
+		}
+	}
+	userMap, err := s.store.Users().RealNames(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range checks {
+		if checks[i].CheckerID != nil {
+			checks[i].OperatorID = *checks[i].CheckerID
+			checks[i].OperatorName = userMap[*checks[i].CheckerID]
+		}
+		checks[i].WarehouseID = "1"
+		checks[i].WarehouseName = "默认仓库"
+		checks[i].Status = InventoryCheckStatusName(checks[i].Status)
+	}
+	return checks, total, nil
+}
+
+// Get 查询盘点单及明细，填充盘点人和产品名称编码（包括已删除的产品），状态转为接口表示
+func (s *InventoryCheckService) Get(id int64) (*model.InventoryCheck, []model.InventoryCheckItem, error) {
+	check, err := s.Find(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.store.InventoryChecks().Items(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productMap, err := productsByID(s.store, checkProductIDs(items))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		if p, ok := productMap[items[i].ProductID]; ok {
+			items[i].ProductName = p.Name
+			items[i].ProductCode = p.SKUCode
+		}
+	}
+
+	if check.CheckerID != nil {
+		userMap, err := s.store.Users().RealNames([]int64{*check.CheckerID})
+		if err != nil {
+			return nil, nil, err
+		}
+		check.OperatorID = *check.CheckerID
+		check.OperatorName = userMap[*check.CheckerID]
+	}
+	check.Status = InventoryCheckStatusName(check.Status)
+	return check, items, nil
+}
+
+// Find 查询未删除的盘点单
+func (s *InventoryCheckService) Find(id int64) (*model.InventoryCheck, error) {
+	check, err := s.store.InventoryChecks().Get(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("盘点单不存在")
+	}
+	return check, err
+}
+
+// Create 创建进行中的盘点单，账面数量在创建时从当前库存快照，快照时间即创建时间
+func (s *InventoryCheckService) Create(in InventoryCheckInput) (*model.InventoryCheck, error) {
+	now := time.Now()
+	check := &model.InventoryCheck{
+		CheckerID:  in.CheckerID,
+		Status:     "CHECKING",
+		CheckDate:  &now,
+		SnapshotAt: &now,
+		Round:      1,
+		Tolerance:  in.Tolerance,
+		CycleClass: in.CycleClass,
+		Remark:     in.Remark,
+	}
+	if in.Freeze {
+		check.Freeze = 1
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if check.CheckNo, err = tx.NextNumber(sequence.InventoryCheck); err != nil {
+			return fmt.Errorf("生成单号失败: %w", err)
+		}
+		if err := tx.InventoryChecks().Create(check); err != nil {
+			return err
+		}
+
+		for _, input := range in.Items {
+			product, err := tx.Products().Get(input.ProductID)
+			if errors.Is(err, repository.ErrNotFound) {
+				return invalid(fmt.Sprintf("产品不存在: %d", input.ProductID))
+			}
+			if err != nil {
+				return err
+			}
+
+			item := &model.InventoryCheckItem{
+				CheckID:   check.ID,
+				ProductID: input.ProductID,
+				BookQty:   product.StockQty,
+				Round:     1,
+			}
+			if input.ActualQty != nil {
+				item.ActualQty = *input.ActualQty
+				item.DiffQty = *input.ActualQty - product.StockQty
+				item.Counted = 1
+			}
+			if err := tx.InventoryChecks().CreateItem(item); err != nil {
+				return err
+			}
+			if input.ActualQty != nil {
+				if err := recordCount(tx, check.ID, input.ProductID, 1, *input.ActualQty, check.CheckerID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// Generate 为符合条件的全部产品创建盘点单，返回盘点单和明细数
+func (s *InventoryCheckService) Generate(match repository.ProductMatch, in InventoryCheckInput) (*model.InventoryCheck, int, error) {
+	if match.CategoryID == 0 && match.LocationFrom == "" && match.LocationTo == "" && match.AbcClass == "" {
+		return nil, 0, invalid("请至少指定分类、库位区间或ABC分类")
+	}
+	productIDs, err := s.store.Products().Match(match)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(productIDs) == 0 {
+		return nil, 0, invalid("没有符合条件的产品")
+	}
+
+	in.Items = make([]InventoryCheckItemInput, 0, len(productIDs))
+	for _, id := range productIDs {
+		in.Items = append(in.Items, InventoryCheckItemInput{ProductID: id})
+	}
+	check, err := s.Create(in)
+	if err != nil {
+		return nil, 0, err
+	}
+	return check, len(productIDs), nil
+}
+
+// Update 录入本轮实盘数量并修改盘点单的状态和备注。
+// 完成盘点时按实盘数量调整库存，盘点单在事务中锁定后再判断状态，并发完成时不会重复调整
+func (s *InventoryCheckService) Update(id, operatorID int64, in InventoryCheckUpdate) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		check, err := s.lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(in.Counts) > 0 && check.Status != "CHECKING" {
+			return invalid("盘点已结束，不能录入实盘数量")
+		}
+
+		// 盈亏始终相对于开始时的账面快照计算；复盘轮次只接受需要复盘的明细
+		for _, count := range in.Counts {
+			err := tx.InventoryChecks().CountItem(check.ID, count.ProductID, check.Round, count.Quantity)
+			if errors.Is(err, repository.ErrNotFound) {
+				if check.Round > 1 {
+					return invalid(fmt.Sprintf("该产品不在第%d轮复盘范围内: %d", check.Round, count.ProductID))
+				}
+				return invalid(fmt.Sprintf("盘点单中没有该产品: %d", count.ProductID))
+			}
+			if err != nil {
+				return err
+			}
+			if err := recordCount(tx, check.ID, count.ProductID, check.Round, count.Quantity, &operatorID); err != nil {
+				return err
+			}
+		}
+
+		finishing := in.Status == "FINISHED" && check.Status == "CHECKING"
+		if finishing {
+			if err := s.finish(tx, check, operatorID); err != nil {
+				return err
+			}
+			now := time.Now()
+			check.CheckDate = &now
+		}
+
+		if in.Status != "" {
+			check.Status = in.Status
+		}
+		check.Remark = in.Remark
+		return tx.InventoryChecks().Update(check)
+	})
+}
+
+// Recount 结束当前轮次，差异超出容差的明细进入下一轮复盘。
+// 返回新的轮次和需要复盘的明细数，明细数为0时所有明细均在容差范围内，轮次不变
+func (s *InventoryCheckService) Recount(id int64) (int, int, error) {
+	var round, recountCount int
+	err := s.store.Transaction(func(tx repository.Store) error {
+		// 锁定盘点单后再判断状态，防止与录入、完成并发时轮次错乱
+		check, err := s.lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if check.Status != "CHECKING" {
+			return invalid("盘点已结束")
+		}
+		if check.Tolerance == nil {
+			return invalid("盘点单未设置差异容差，无需复盘")
+		}
+
+		items, err := tx.InventoryChecks().Items(check.ID)
+		if err != nil {
+			return err
+		}
+		recountIDs := make([]int64, 0)
+		for _, item := range items {
+			if item.Counted == 0 {
+				return invalid("本轮仍有未盘明细")
+			}
+			if exceedsTolerance(item.DiffQty, *check.Tolerance) {
+				recountIDs = append(recountIDs, item.ID)
+			}
+		}
+
+		round = check.Round
+		if len(recountIDs) == 0 {
+			return nil
+		}
+		round, recountCount = check.Round+1, len(recountIDs)
+		return tx.InventoryChecks().StartRound(check.ID, round, recountIDs)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return round, recountCount, nil
+}
+
+// Delete 删除未完成的盘点单（软删除，明细和计数记录保留以便恢复）
+func (s *InventoryCheckService) Delete(id int64) error {
+	check, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+	if check.Status == "FINISHED" {
+		return invalid("已完成的盘点单不能删除")
+	}
+	return s.store.InventoryChecks().Delete(id)
+}
+
+// Restore 恢复已删除的盘点单，明细中的产品已删除时不能恢复
+func (s *InventoryCheckService) Restore(id int64) error {
+	if _, err := s.store.InventoryChecks().GetDeleted(id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("已删除的盘点单不存在")
+		}
+		return err
+	}
+
+	items, err := s.store.InventoryChecks().Items(id)
+	if err != nil {
+		return err
+	}
+	if err := checkDeletedProducts(s.store, checkProductIDs(items)); err != nil {
+		return err
+	}
+	return s.store.InventoryChecks().Restore(id)
+}
+
+// lock 在事务中锁定未删除的盘点单
+func (s *InventoryCheckService) lock(tx repository.Store, id int64) (*model.InventoryCheck, error) {
+	check, err := tx.InventoryChecks().Lock(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("盘点单不存在")
+	}
+	return check, err
+}
+
+// finish 校验盘点单能否完成，并按实盘数量调整各明细的库存
+func (s *InventoryCheckService) finish(tx repository.Store, check *model.InventoryCheck, operatorID int64) error {
+	items, err := tx.InventoryChecks().Items(check.ID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Counted == 0 {
+			return invalid("仍有未盘明细，不能完成盘点")
+		}
+		// 设置了容差时，超出容差的差异至少需要复盘一次确认
+		if check.Tolerance != nil && exceedsTolerance(item.DiffQty, *check.Tolerance) && item.Round < 2 {
+			return invalid("存在超出容差的差异，请先发起复盘")
+		}
+	}
+
+	for _, item := range items {
+		if err := adjustCountedStock(tx, check, item, operatorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordCount 保存一次计数记录，同时记下当前最新的库存流水ID作为实盘时点
+func recordCount(tx repository.Store, checkID, productID int64, round int, qty float64, counterID *int64) error {
+	stockLogID, err := tx.Stock().LastLogID()
+	if err != nil {
+		return err
+	}
+	return tx.InventoryChecks().AddCount(&model.InventoryCheckCount{
+		CheckID:    checkID,
+		ProductID:  productID,
+		Round:      round,
+		Qty:        qty,
+		CounterID:  counterID,
+		StockLogID: stockLogID,
+	})
+}
+
+// adjustCountedStock 按实盘数量调整库存：实盘之后发生的出入库仍然有效，
+// 新库存为实盘数量加上最后一次实盘之后的库存流水净变动
+func adjustCountedStock(tx repository.Store, check *model.InventoryCheck, item model.InventoryCheckItem, operatorID int64) error {
+	// 按流水ID而非时间区分实盘前后，同一秒内的出入库也能分清先后；
+	// 早期创建盘点单时直接给出的实盘数量没有计数记录，以明细创建时间为实盘时点
+	var countedLogID int64
+	count, err := tx.InventoryChecks().LastCount(check.ID, item.ProductID)
+	switch {
+	case err == nil:
+		countedLogID = count.StockLogID
+	case errors.Is(err, repository.ErrNotFound):
+		if countedLogID, err = tx.Stock().LastLogIDBefore(item.CreatedAt); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	moved, err := tx.Stock().MovedSince(item.ProductID, countedLogID)
+	if err != nil {
+		return err
+	}
+	product, err := tx.Products().Get(item.ProductID)
+	if err != nil {
+		return err
+	}
+	delta := item.ActualQty + moved - product.StockQty
+	if delta == 0 {
+		return nil
+	}
+	return PostStock(tx, item.ProductID, "ADJUST", delta, check.CheckNo, &operatorID)
+}
+
+// exceedsTolerance 判断盈亏是否超出容差
+func exceedsTolerance(diff, tolerance float64) bool {
+	return math.Abs(diff) > tolerance
+}
+
+// InventoryCheckStatus 盘点单状态的接口表示转为数据库中的状态，无法识别时返回空
+func InventoryCheckStatus(name string) string {
+	switch name {
+	case "draft", "checking":
+		return "CHECKING"
+	case "completed":
+		return "FINISHED"
+	case "cancelled":
+		return "CANCELLED"
+	}
+	return ""
+}
+
+// InventoryCheckStatusName 盘点单状态的接口表示
+func InventoryCheckStatusName(status string) string {
+	switch status {
+	case "CHECKING":
+		return "checking"
+	case "FINISHED":
+		return "completed"
+	case "CANCELLED":
+		return "cancelled"
+	}
+	return "draft"
+}
+
+// checkProductIDs 盘点明细中的产品ID
+func checkProductIDs(items []model.InventoryCheckItem) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"testing"
+
+	"easywms/internal/repository"
+)
+
+// newCheckFixture 与入库单共用产品数据：产品1库存10，产品2库存0
+func newCheckFixture() (*fakeStore, *InventoryCheckService) {
+	store, _ := newInboundFixture()
+	return store, NewInventoryCheckService(store)
+}
+
+func TestInventoryCheckCreateSnapshotsBookQuantity(t *testing.T) {
+	store, svc := newCheckFixture()
+	checker := int64(7)
+
+	check, err := svc.Create(InventoryCheckInput{CheckerID: &checker, Freeze: true, Items: []InventoryCheckItemInput{
+		{ProductID: 1, ActualQty: ptr(9.0)},
+		{ProductID: 2},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if check.Status != "CHECKING" || check.Round != 1 || check.Freeze != 1 || check.SnapshotAt == nil {
+		t.Errorf("check = %+v", check)
+	}
+
+	items := store.data.checkItems[check.ID]
+	if len(items) != 2 || items[0].BookQty != 10 || items[0].DiffQty != -1 || items[0].Counted != 1 || items[1].Counted != 0 {
+		t.Errorf("items = %+v", items)
+	}
+	if counts := store.data.checkCounts; len(counts) != 1 || counts[0].ProductID != 1 || *counts[0].CounterID != 7 {
+		t.Errorf("counts = %+v", counts)
+	}
+
+	_, err = svc.Create(InventoryCheckInput{Items: []InventoryCheckItemInput{{ProductID: 9}}})
+	wantKind(t, err, KindInvalid, "产品不存在: 9")
+	if len(store.data.checks) != 1 {
+		t.Error("rejected check must not be saved")
+	}
+
+	_, _, err = svc.Generate(repository.ProductMatch{}, InventoryCheckInput{})
+	wantKind(t, err, KindInvalid, "请至少指定分类、库位区间或ABC分类")
+	_, _, err = svc.Generate(repository.ProductMatch{CategoryID: 3}, InventoryCheckInput{})
+	wantKind(t, err, KindInvalid, "没有符合条件的产品")
+}
+
+func TestInventoryCheckFinishKeepsMovementsAfterCount(t *testing.T) {
+	store, svc := newCheckFixture()
+	check, _ := svc.Create(InventoryCheckInput{Items: []InventoryCheckItemInput{{ProductID: 1}}})
+
+	err := svc.Update(check.ID, 7, InventoryCheckUpdate{Status: "FINISHED"})
+	wantKind(t, err, KindInvalid, "仍有未盘明细，不能完成盘点")
+	err = svc.Update(check.ID, 7, InventoryCheckUpdate{Counts: []InventoryCountInput{{ProductID: 2, Quantity: 1}}})
+	wantKind(t, err, KindInvalid, "盘点单中没有该产品: 2")
+
+	if err := svc.Update(check.ID, 7, InventoryCheckUpdate{Counts: []InventoryCountInput{{ProductID: 1, Quantity: 8}}}); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	// 实盘之后入库的5个仍然有效
+	if err := PostStock(store, 1, "IN", 5, "IN-0001", nil); err != nil {
+		t.Fatalf("inbound: %v", err)
+	}
+
+	if err := svc.Update(check.ID, 7, InventoryCheckUpdate{Status: "FINISHED", Remark: "月末盘点"}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if qty := store.data.products[1].StockQty; qty != 13 {
+		t.Errorf("stock = %v, want 13", qty)
+	}
+	if logs := store.data.logs; len(logs) != 2 || logs[1].Type != "ADJUST" || logs[1].ChangeQty != -2 ||
+		logs[1].SnapshotQty != 13 || logs[1].RelatedNo != check.CheckNo {
+		t.Errorf("logs = %+v", logs)
+	}
+	if saved := store.data.checks[check.ID]; saved.Status != "FINISHED" || saved.Remark != "月末盘点" {
+		t.Errorf("check = %+v", saved)
+	}
+
+	err = svc.Update(check.ID, 7, InventoryCheckUpdate{Counts: []InventoryCountInput{{ProductID: 1, Quantity: 1}}})
+	wantKind(t, err, KindInvalid, "盘点已结束，不能录入实盘数量")
+	if err := svc.Update(check.ID, 7, InventoryCheckUpdate{Status: "FINISHED"}); err != nil || len(store.data.logs) != 2 {
+		t.Errorf("finishing again adjusted stock: %v", err)
+	}
+	wantKind(t, svc.Delete(check.ID), KindInvalid, "已完成的盘点单不能删除")
+}
+
+func TestInventoryCheckRecountsDifferencesBeyondTolerance(t *testing.T) {
+	store, svc := newCheckFixture()
+	check, _ := svc.Create(InventoryCheckInput{Tolerance: ptr(1.0), Items: []InventoryCheckItemInput{
+		{ProductID: 1, ActualQty: ptr(5.0)},
+		{ProductID: 2, ActualQty: ptr(0.0)},
+	}})
+
+	err := svc.Update(check.ID, 7, InventoryCheckUpdate{Status: "FINISHED"})
+	wantKind(t, err, KindInvalid, "存在超出容差的差异，请先发起复盘")
+
+	round, recountCount, err := svc.Recount(check.ID)
+	if err != nil || round != 2 || recountCount != 1 {
+		t.Fatalf("recount = %d, %d, %v", round, recountCount, err)
+	}
+	_, _, err = svc.Recount(check.ID)
+	wantKind(t, err, KindInvalid, "本轮仍有未盘明细")
+	err = svc.Update(check.ID, 7, InventoryCheckUpdate{Counts: []InventoryCountInput{{ProductID: 2, Quantity: 1}}})
+	wantKind(t, err, KindInvalid, "该产品不在第2轮复盘范围内: 2")
+
+	if err := svc.Update(check.ID, 7, InventoryCheckUpdate{Counts: []InventoryCountInput{{ProductID: 1, Quantity: 6}}}); err != nil {
+		t.Fatalf("recount product: %v", err)
+	}
+	if err := svc.Update(check.ID, 7, InventoryCheckUpdate{Status: "FINISHED"}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if qty := store.data.products[1].StockQty; qty != 6 {
+		t.Errorf("stock = %v, want 6", qty)
+	}
+	_, _, err = svc.Recount(check.ID)
+	wantKind(t, err, KindInvalid, "盘点已结束")
+}
+
+func TestInventoryCheckRecountWithinTolerance(t *testing.T) {
+	_, svc := newCheckFixture()
+	plain, _ := svc.Create(InventoryCheckInput{Items: []InventoryCheckItemInput{{ProductID: 1, ActualQty: ptr(9.0)}}})
+	_, _, err := svc.Recount(plain.ID)
+	wantKind(t, err, KindInvalid, "盘点单未设置差异容差，无需复盘")
+
+	check, _ := svc.Create(InventoryCheckInput{Tolerance: ptr(1.0), Items: []InventoryCheckItemInput{{ProductID: 1, ActualQty: ptr(9.0)}}})
+	round, recountCount, err := svc.Recount(check.ID)
+	if err != nil || round != 1 || recountCount != 0 {
+		t.Errorf("recount = %d, %d, %v", round, recountCount, err)
+	}
+
+	if err := svc.Delete(check.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	_, _, err = svc.Recount(check.ID)
+	wantKind(t, err, KindNotFound, "盘点单不存在")
+	if err := svc.Restore(check.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	wantKind(t, svc.Restore(check.ID), KindNotFound, "已删除的盘点单不存在")
+}
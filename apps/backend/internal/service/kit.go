@@ -0,0 +1,353 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+)
+
+// KitComponentInput 套件组件，数量按组件的基本单位计
+type KitComponentInput struct {
+	ProductID int64
+	Quantity  float64
+}
+
+// AssemblyInput 创建组装单的内容
+type AssemblyInput struct {
+	KitID    int64
+	Quantity float64
+	Remark   string
+}
+
+// AssemblyUpdate 修改组装单的内容，Status 为 completed 时完成组装，为 cancelled 时取消
+type AssemblyUpdate struct {
+	Status string
+	Remark string
+}
+
+// KitService 套件组成和组装单业务
+type KitService struct {
+	store repository.Store
+}
+
+// NewKitService 创建套件业务服务
+func NewKitService(store repository.Store) *KitService {
+	return &KitService{store: store}
+}
+
+// Get 查询套件产品及其组成
+func (s *KitService) Get(kitID int64) (*model.Product, []model.KitComponent, error) {
+	kit, err := s.store.Products().Get(kitID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, notFound("产品不存在")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	components, err := kitComponents(s.store, kitID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kit, components, nil
+}
+
+// Save 整体替换套件组成。套件不能嵌套：已是其他套件组件的产品不能定义组成，套件也不能作为组件
+func (s *KitService) Save(kitID int64, inputs []KitComponentInput) ([]model.KitComponent, error) {
+	if _, err := s.store.Products().Get(kitID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, notFound("产品不存在")
+		}
+		return nil, err
+	}
+
+	isComponent, err := s.store.Kits().IsComponent(kitID)
+	if err != nil {
+		return nil, err
+	}
+	if isComponent && len(inputs) > 0 {
+		return nil, invalid("该产品已是其他套件的组件，不能定义为套件")
+	}
+
+	components := make([]model.KitComponent, 0, len(inputs))
+	seen := make(map[int64]bool)
+	for _, input := range inputs {
+		if input.ProductID == kitID {
+			return nil, invalid("套件不能包含自身")
+		}
+		if input.Quantity <= 0 {
+			return nil, invalid("组件数量必须大于0")
+		}
+		if seen[input.ProductID] {
+			return nil, invalid("组件重复")
+		}
+		seen[input.ProductID] = true
+
+		product, err := s.store.Products().Get(input.ProductID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, invalid(fmt.Sprintf("组件产品不存在: %d", input.ProductID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		nested, err := s.store.Kits().Components(input.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if len(nested) > 0 {
+			return nil, invalid("组件不能是套件: " + product.Name)
+		}
+		components = append(components, model.KitComponent{ProductID: input.ProductID, Qty: input.Quantity})
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		return tx.Kits().ReplaceComponents(kitID, components)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// ListAssemblies 查询组装单列表，并填充套件名称编码和操作人
+func (s *KitService) ListAssemblies(filter repository.AssemblyFilter) ([]model.KitAssembly, int64, error) {
+	assemblies, total, err := s.store.Assemblies().List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kitIDs := make([]int64, 0, len(assemblies))
+	userIDs := make([]int64, 0)
+	for _, a := range assemblies {
+		kitIDs = append(kitIDs, a.KitID)
+		if a.OperatorID != nil {
+			userIDs = append(userIDs, *a.OperatorID)
+		}
+	}
+
+	productMap, err := productsByID(s.store, kitIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	userMap, err := s.store.Users().RealNames(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range assemblies {
+		if p, ok := productMap[assemblies[i].KitID]; ok {
+			assemblies[i].KitName = p.Name
+			assemblies[i].KitCode = p.SKUCode
+		}
+		if assemblies[i].OperatorID != nil {
+			assemblies[i].OperatorName = userMap[*assemblies[i].OperatorID]
+		}
+	}
+	return assemblies, total, nil
+}
+
+// GetAssembly 查询组装单及按组装数量展开的组件消耗
+func (s *KitService) GetAssembly(id int64) (*model.KitAssembly, []model.KitComponent, error) {
+	assembly, err := s.getAssembly(s.store, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productMap, err := productsByID(s.store, []int64{assembly.KitID})
+	if err != nil {
+		return nil, nil, err
+	}
+	assembly.KitName = productMap[assembly.KitID].Name
+	assembly.KitCode = productMap[assembly.KitID].SKUCode
+
+	components, err := kitComponents(s.store, assembly.KitID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range components {
+		components[i].Qty = components[i].Qty * assembly.Qty
+	}
+	return assembly, components, nil
+}
+
+// CreateAssembly 创建待处理的组装单，创建时不改变库存
+func (s *KitService) CreateAssembly(operatorID int64, in AssemblyInput) (*model.KitAssembly, error) {
+	if in.Quantity <= 0 {
+		return nil, invalid("组装数量必须大于0")
+	}
+	components, err := s.store.Kits().Components(in.KitID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, invalid("套件未定义组成")
+	}
+
+	assembly := &model.KitAssembly{
+		KitID:      in.KitID,
+		Qty:        in.Quantity,
+		Status:     "PENDING",
+		OperatorID: &operatorID,
+		Remark:     in.Remark,
+	}
+	err = s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if assembly.AssemblyNo, err = tx.NextNumber(sequence.KitAssembly); err != nil {
+			return fmt.Errorf("生成单号失败: %w", err)
+		}
+		return tx.Assemblies().Create(assembly)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assembly, nil
+}
+
+// UpdateAssembly 修改待处理的组装单；完成时消耗组件库存并产出套件库存
+func (s *KitService) UpdateAssembly(id, operatorID int64, in AssemblyUpdate) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		assembly, err := s.getAssembly(tx, id)
+		if err != nil {
+			return err
+		}
+		if assembly.Status != "PENDING" {
+			return invalid("组装单已处理，不能修改")
+		}
+
+		assembly.Remark = in.Remark
+		switch in.Status {
+		case "completed":
+			now := time.Now()
+			assembly.Status = "DONE"
+			assembly.AssembledAt = &now
+		case "cancelled":
+			assembly.Status = "CANCELLED"
+		}
+
+		// 以待处理状态为条件更新，并发提交时只有一个请求能命中，避免重复消耗组件
+		if err := tx.Assemblies().Update(assembly, "PENDING"); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return invalid("组装单已处理，不能修改")
+			}
+			return err
+		}
+
+		if assembly.Status == "DONE" {
+			return s.assemble(tx, assembly, operatorID)
+		}
+		return nil
+	})
+}
+
+// DeleteAssembly 删除未完成的组装单（软删除）
+func (s *KitService) DeleteAssembly(id int64) error {
+	assembly, err := s.getAssembly(s.store, id)
+	if err != nil {
+		return err
+	}
+	if assembly.Status == "DONE" {
+		return invalid("已完成的组装单不能删除")
+	}
+	return s.store.Assemblies().Delete(id)
+}
+
+// RestoreAssembly 恢复已删除的组装单，套件产品已删除时不能恢复
+func (s *KitService) RestoreAssembly(id int64) error {
+	assembly, err := s.store.Assemblies().GetDeleted(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("已删除的组装单不存在")
+	}
+	if err != nil {
+		return err
+	}
+
+	name, deleted, err := s.store.Products().FirstDeletedName([]int64{assembly.KitID})
+	if err != nil {
+		return err
+	}
+	if deleted {
+		return invalid("套件产品已删除: " + name)
+	}
+	return s.store.Assemblies().Restore(id)
+}
+
+// getAssembly 查询未删除的组装单
+func (s *KitService) getAssembly(store repository.Store, id int64) (*model.KitAssembly, error) {
+	assembly, err := store.Assemblies().Get(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("组装单不存在")
+	}
+	return assembly, err
+}
+
+// assemble 消耗组件库存并产出套件库存，冻结盘点中的产品不能组装，须在事务中调用
+func (s *KitService) assemble(tx repository.Store, assembly *model.KitAssembly, operatorID int64) error {
+	components, err := kitComponents(tx, assembly.KitID)
+	if err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		return invalid("套件未定义组成")
+	}
+
+	ids := []int64{assembly.KitID}
+	for _, comp := range components {
+		ids = append(ids, comp.ProductID)
+	}
+	checkNo, frozen, err := tx.Stock().FreezingCheck(ids)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return invalid("产品正在盘点中(" + checkNo + ")，暂不能组装")
+	}
+
+	for _, comp := range components {
+		need := comp.Qty * assembly.Qty
+		snapshot, err := tx.Stock().Deduct(comp.ProductID, need)
+		if errors.Is(err, repository.ErrConflict) {
+			return invalid("组件库存不足: " + comp.ProductName)
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Stock().AddLog(&model.StockLog{
+			ProductID:   comp.ProductID,
+			Type:        "KIT_OUT",
+			ChangeQty:   -need,
+			SnapshotQty: snapshot,
+			RelatedNo:   assembly.AssemblyNo,
+			OperatorID:  &operatorID,
+		}); err != nil {
+			return err
+		}
+	}
+	return PostStock(tx, assembly.KitID, "KIT_IN", assembly.Qty, assembly.AssemblyNo, &operatorID)
+}
+
+// kitComponents 查询套件组成并填充组件的名称、编码和基本单位
+func kitComponents(store repository.Store, kitID int64) ([]model.KitComponent, error) {
+	components, err := store.Kits().Components(kitID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(components))
+	for _, comp := range components {
+		ids = append(ids, comp.ProductID)
+	}
+	productMap, err := productsByID(store, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range components {
+		if p, ok := productMap[components[i].ProductID]; ok {
+			components[i].ProductName = p.Name
+			components[i].ProductCode = p.SKUCode
+			components[i].Unit = p.Unit
+		}
+	}
+	return components, nil
+}
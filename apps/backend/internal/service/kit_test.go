@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+
+	"easywms/internal/model"
+)
+
+// newKitFixture 产品10“工具包”为套件，由3个螺丝和1把扳手组成
+func newKitFixture(t *testing.T) (*fakeStore, *KitService) {
+	t.Helper()
+	store, _ := newInboundFixture()
+	store.data.products[10] = model.Product{ID: 10, Name: "工具包", SKUCode: "KIT-10", Unit: "套"}
+	store.data.products[11] = model.Product{ID: 11, Name: "大工具包", SKUCode: "KIT-11", Unit: "套"}
+	svc := NewKitService(store)
+	if _, err := svc.Save(10, []KitComponentInput{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}}); err != nil {
+		t.Fatalf("save kit: %v", err)
+	}
+	return store, svc
+}
+
+func TestKitSaveValidatesComponents(t *testing.T) {
+	_, svc := newKitFixture(t)
+
+	kit, components, err := svc.Get(10)
+	if err != nil || kit.Name != "工具包" || len(components) != 2 ||
+		components[0].ProductName != "螺丝" || components[0].Unit != "个" || components[1].Qty != 1 {
+		t.Fatalf("kit = %+v, components = %+v, %v", kit, components, err)
+	}
+
+	_, err = svc.Save(1, []KitComponentInput{{ProductID: 2, Quantity: 1}})
+	wantKind(t, err, KindInvalid, "该产品已是其他套件的组件，不能定义为套件")
+	_, err = svc.Save(11, []KitComponentInput{{ProductID: 10, Quantity: 1}})
+	wantKind(t, err, KindInvalid, "组件不能是套件: 工具包")
+	_, err = svc.Save(11, []KitComponentInput{{ProductID: 11, Quantity: 1}})
+	wantKind(t, err, KindInvalid, "套件不能包含自身")
+	_, err = svc.Save(11, []KitComponentInput{{ProductID: 1, Quantity: 0}})
+	wantKind(t, err, KindInvalid, "组件数量必须大于0")
+	_, err = svc.Save(11, []KitComponentInput{{ProductID: 1, Quantity: 1}, {ProductID: 1, Quantity: 2}})
+	wantKind(t, err, KindInvalid, "组件重复")
+	_, err = svc.Save(11, []KitComponentInput{{ProductID: 9, Quantity: 1}})
+	wantKind(t, err, KindInvalid, "组件产品不存在: 9")
+	_, err = svc.Save(9, nil)
+	wantKind(t, err, KindNotFound, "产品不存在")
+}
+
+func TestAssemblyConsumesComponentsOnce(t *testing.T) {
+	store, svc := newKitFixture(t)
+
+	_, err := svc.CreateAssembly(7, AssemblyInput{KitID: 11, Quantity: 1})
+	wantKind(t, err, KindInvalid, "套件未定义组成")
+	_, err = svc.CreateAssembly(7, AssemblyInput{KitID: 10})
+	wantKind(t, err, KindInvalid, "组装数量必须大于0")
+
+	assembly, err := svc.CreateAssembly(7, AssemblyInput{KitID: 10, Quantity: 2})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 扳手没有库存，组装失败时不扣减任何库存
+	err = svc.UpdateAssembly(assembly.ID, 7, AssemblyUpdate{Status: "completed"})
+	wantKind(t, err, KindInvalid, "组件库存不足: 扳手")
+	if store.data.products[1].StockQty != 10 || len(store.data.logs) != 0 || store.data.assemblies[assembly.ID].Status != "PENDING" {
+		t.Fatal("failed assembly must not change anything")
+	}
+
+	product := store.data.products[2]
+	product.StockQty = 5
+	store.data.products[2] = product
+	if err := svc.UpdateAssembly(assembly.ID, 7, AssemblyUpdate{Status: "completed"}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	want := map[int64]float64{1: 4, 2: 3, 10: 2}
+	for id, qty := range want {
+		if got := store.data.products[id].StockQty; got != qty {
+			t.Errorf("product %d stock = %v, want %v", id, got, qty)
+		}
+	}
+	logs := store.data.logs
+	if len(logs) != 3 || logs[0].Type != "KIT_OUT" || logs[0].ChangeQty != -6 || logs[0].SnapshotQty != 4 ||
+		logs[2].Type != "KIT_IN" || logs[2].ChangeQty != 2 || logs[2].RelatedNo != assembly.AssemblyNo {
+		t.Errorf("logs = %+v", logs)
+	}
+	if saved := store.data.assemblies[assembly.ID]; saved.Status != "DONE" || saved.AssembledAt == nil {
+		t.Errorf("assembly = %+v", saved)
+	}
+
+	wantKind(t, svc.UpdateAssembly(assembly.ID, 7, AssemblyUpdate{Status: "completed"}), KindInvalid, "组装单已处理，不能修改")
+	wantKind(t, svc.DeleteAssembly(assembly.ID), KindInvalid, "已完成的组装单不能删除")
+	if len(store.data.logs) != 3 {
+		t.Error("completed assembly consumed components twice")
+	}
+}
+
+func TestAssemblyBlockedByFreezingCheck(t *testing.T) {
+	store, svc := newKitFixture(t)
+	assembly, _ := svc.CreateAssembly(7, AssemblyInput{KitID: 10, Quantity: 1})
+	store.data.frozen[10] = "CHK-0001"
+
+	err := svc.UpdateAssembly(assembly.ID, 7, AssemblyUpdate{Status: "completed"})
+	wantKind(t, err, KindInvalid, "产品正在盘点中(CHK-0001)，暂不能组装")
+
+	// 取消不涉及库存，不受盘点冻结影响
+	if err := svc.UpdateAssembly(assembly.ID, 7, AssemblyUpdate{Status: "cancelled"}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if err := svc.DeleteAssembly(assembly.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := svc.RestoreAssembly(assembly.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	wantKind(t, svc.RestoreAssembly(assembly.ID), KindNotFound, "已删除的组装单不存在")
+}
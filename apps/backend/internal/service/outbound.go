@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+)
+
+// OutboundItemInput 出库明细，数量按 Unit 计，Unit 为空时为基本单位
+type OutboundItemInput struct {
+	ProductID int64
+	Quantity  float64
+	Unit      string
+}
+
+// OutboundKitInput 领用的套件，按套件组成展开为出库明细
+type OutboundKitInput struct {
+	KitID    int64
+	Quantity float64
+}
+
+// OutboundInput 创建出库单的内容
+type OutboundInput struct {
+	Purpose string
+	Items   []OutboundItemInput
+	Kits    []OutboundKitInput
+}
+
+// OutboundUpdate 修改出库单的内容，Status 为数据库中的状态，为空时保留原状态
+type OutboundUpdate struct {
+	Status  string
+	Purpose string
+}
+
+// OutboundService 出库单业务
+type OutboundService struct {
+	store repository.Store
+}
+
+// NewOutboundService 创建出库单业务服务
+func NewOutboundService(store repository.Store) *OutboundService {
+	return &OutboundService{store: store}
+}
+
+// List 查询出库单列表，并填充申请人、审核人、部门、总数量等展示字段，状态转为接口表示
+func (s *OutboundService) List(filter repository.OutboundFilter) ([]model.Outbound, int64, error) {
+	outbounds, total, err := s.store.Outbounds().List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0)
+	deptIDs := make([]int64, 0, len(outbounds))
+	outboundIDs := make([]int64, 0, len(outbounds))
+	for _, o := range outbounds {
+		userIDs = append(userIDs, o.ApplicantID)
+		if o.ReviewerID != nil {
+			userIDs = append(userIDs, *o.ReviewerID)
+		}
+		deptIDs = append(deptIDs, o.DeptID)
+		outboundIDs = append(outboundIDs, o.ID)
+	}
+
+	userMap, err := s.store.Users().RealNames(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	deptMap, err := s.store.Departments().Names(deptIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	qtyMap, err := s.store.Outbounds().TotalQuantities(outboundIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range outbounds {
+		outbounds[i].ApplicantName = userMap[outbounds[i].ApplicantID]
+		if outbounds[i].ReviewerID != nil {
+			outbounds[i].ReviewerName = userMap[*outbounds[i].ReviewerID]
+		}
+		outbounds[i].DeptName = deptMap[outbounds[i].DeptID]
+		outbounds[i].TotalQuantity = qtyMap[outbounds[i].ID]
+		outbounds[i].Type = "other"
+		outbounds[i].WarehouseName = "默认仓库"
+		outbounds[i].OperatorName = outbounds[i].ApplicantName
+		outbounds[i].Status = OutboundStatusName(outbounds[i].Status)
+	}
+	return outbounds, total, nil
+}
+
+// Get 查询出库单及明细，明细填充产品名称和编码（包括已删除的产品）
+func (s *OutboundService) Get(scope repository.Scope, id int64) (*model.Outbound, []model.OutboundItem, error) {
+	outbound, err := s.Find(scope, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.store.Outbounds().Items(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productMap, err := productsByID(s.store, outboundProductIDs(items))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		if p, ok := productMap[items[i].ProductID]; ok {
+			items[i].ProductName = p.Name
+			items[i].ProductCode = p.SKUCode
+		}
+	}
+	return outbound, items, nil
+}
+
+// Find 查询数据权限内未删除的出库单
+func (s *OutboundService) Find(scope repository.Scope, id int64) (*model.Outbound, error) {
+	return s.find(s.store, scope, id)
+}
+
+// Create 创建待审批的出库单，归属申请人当前所在部门。
+// 明细数量换算为基本单位，套件按组成展开为组件明细
+func (s *OutboundService) Create(applicantID int64, in OutboundInput) (*model.Outbound, error) {
+	for _, item := range in.Items {
+		if item.Quantity <= 0 {
+			return nil, invalid("领用数量必须大于0")
+		}
+	}
+	for _, kit := range in.Kits {
+		if kit.Quantity <= 0 {
+			return nil, invalid("套件数量必须大于0")
+		}
+	}
+
+	deptID, err := s.store.Users().DeptID(applicantID)
+	if err != nil {
+		return nil, err
+	}
+	outbound := &model.Outbound{
+		ApplicantID: applicantID,
+		DeptID:      deptID,
+		Status:      "PENDING",
+		Purpose:     in.Purpose,
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		items, err := s.items(tx, in)
+		if err != nil {
+			return err
+		}
+		if outbound.OutboundNo, err = tx.NextNumber(sequence.Outbound); err != nil {
+			return fmt.Errorf("生成单号失败: %w", err)
+		}
+		return tx.Outbounds().Create(outbound, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outbound, nil
+}
+
+// Update 修改出库单的用途和状态，状态流转的权限由调用方校验。
+// 审批通过时记录审核人；改为已完成时发货，按实发数量（未拣货时为申请数量）扣减库存并记录流水
+func (s *OutboundService) Update(scope repository.Scope, id, operatorID int64, in OutboundUpdate) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		outbound, err := s.find(tx, scope, id)
+		if err != nil {
+			return err
+		}
+
+		previous := outbound.Status
+		if in.Status != "" {
+			outbound.Status = in.Status
+		}
+		outbound.Purpose = in.Purpose
+
+		now := time.Now()
+		shipping := outbound.Status == "DONE" && previous != "DONE"
+		if shipping {
+			outbound.OutboundDate = &now
+		} else if outbound.Status == "APPROVED" && previous == "PENDING" {
+			outbound.ReviewerID = &operatorID
+			outbound.ReviewTime = &now
+		}
+
+		// 以未完成为条件更新状态，并发发货时只有一个请求能扣减库存
+		if err := tx.Outbounds().Update(outbound); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return invalid("已完成的出库单不能修改")
+			}
+			return err
+		}
+
+		if shipping {
+			return s.ship(tx, outbound, operatorID)
+		}
+		return nil
+	})
+}
+
+// Delete 删除未完成的出库单（软删除，明细保留以便恢复）
+func (s *OutboundService) Delete(scope repository.Scope, id int64) error {
+	outbound, err := s.Find(scope, id)
+	if err != nil {
+		return err
+	}
+	if outbound.Status == "DONE" {
+		return invalid("已完成的出库单不能删除")
+	}
+	return s.store.Outbounds().Delete(id)
+}
+
+// Restore 恢复已删除的出库单，明细中的产品已删除时不能恢复
+func (s *OutboundService) Restore(scope repository.Scope, id int64) error {
+	if _, err := s.store.Outbounds().GetDeleted(scope, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("已删除的出库单不存在")
+		}
+		return err
+	}
+
+	items, err := s.store.Outbounds().Items(id)
+	if err != nil {
+		return err
+	}
+	if err := checkDeletedProducts(s.store, outboundProductIDs(items)); err != nil {
+		return err
+	}
+	return s.store.Outbounds().Restore(id)
+}
+
+// find 查询数据权限内未删除的出库单
+func (s *OutboundService) find(store repository.Store, scope repository.Scope, id int64) (*model.Outbound, error) {
+	outbound, err := store.Outbounds().Get(scope, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("出库单不存在")
+	}
+	return outbound, err
+}
+
+// items 把明细数量换算为基本单位，并按套件组成展开套件
+func (s *OutboundService) items(store repository.Store, in OutboundInput) ([]model.OutboundItem, error) {
+	items := make([]model.OutboundItem, 0, len(in.Items))
+	for _, input := range in.Items {
+		factor, unit, err := UnitFactor(store, input.ProductID, input.Unit)
+		if err != nil {
+			return nil, err
+		}
+		unitQty := input.Quantity
+		items = append(items, model.OutboundItem{
+			ProductID: input.ProductID,
+			ApplyQty:  input.Quantity * factor,
+			Unit:      unit,
+			UnitQty:   &unitQty,
+		})
+	}
+
+	for _, kit := range in.Kits {
+		components, err := kitComponents(store, kit.KitID)
+		if err != nil {
+			return nil, err
+		}
+		if len(components) == 0 {
+			return nil, invalid(fmt.Sprintf("套件未定义组成: %d", kit.KitID))
+		}
+
+		kitID := kit.KitID
+		for _, comp := range components {
+			// 展开的明细按组件的基本单位记录
+			applyQty := comp.Qty * kit.Quantity
+			items = append(items, model.OutboundItem{
+				ProductID: comp.ProductID,
+				ApplyQty:  applyQty,
+				Unit:      comp.Unit,
+				UnitQty:   &applyQty,
+				KitID:     &kitID,
+			})
+		}
+	}
+	return items, nil
+}
+
+// ship 发货：冻结盘点中的产品不允许出库，其余按实发数量扣减库存、回写明细并记录出库流水
+func (s *OutboundService) ship(tx repository.Store, outbound *model.Outbound, operatorID int64) error {
+	items, err := tx.Outbounds().Items(outbound.ID)
+	if err != nil {
+		return err
+	}
+
+	checkNo, frozen, err := tx.Stock().FreezingCheck(outboundProductIDs(items))
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return invalid("产品正在盘点中(" + checkNo + ")，暂不能出库")
+	}
+
+	for _, item := range items {
+		actualQty := item.ApplyQty
+		if item.ActualQty != nil {
+			actualQty = *item.ActualQty
+		}
+		if err := PostStock(tx, item.ProductID, "OUT", -actualQty, outbound.OutboundNo, &operatorID); err != nil {
+			return err
+		}
+		if err := tx.Outbounds().SetActualQty(item.ID, actualQty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutboundStatus 出库单状态的接口表示转为数据库中的状态，无法识别时返回空
+func OutboundStatus(name string) string {
+	switch name {
+	case "pending":
+		return "PENDING"
+	case "approved", "picking":
+		return "APPROVED"
+	case "completed":
+		return "DONE"
+	case "cancelled":
+		return "REJECT"
+	}
+	return ""
+}
+
+// OutboundStatusName 出库单状态的接口表示
+func OutboundStatusName(status string) string {
+	switch status {
+	case "PENDING":
+		return "pending"
+	case "APPROVED":
+		return "picking"
+	case "DONE":
+		return "completed"
+	case "REJECT":
+		return "cancelled"
+	}
+	return status
+}
+
+// outboundProductIDs 出库明细中的产品ID
+func outboundProductIDs(items []model.OutboundItem) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+
+	"easywms/internal/repository"
+)
+
+// newOutboundFixture 在套件数据的基础上，申请人8属于部门2“研发部”
+func newOutboundFixture(t *testing.T) (*fakeStore, *OutboundService) {
+	t.Helper()
+	store, _ := newKitFixture(t)
+	store.data.users[8] = "申请人"
+	store.data.userDepts[8] = 2
+	store.data.depts[2] = "研发部"
+	return store, NewOutboundService(store)
+}
+
+func TestOutboundCreateConvertsUnitsAndExpandsKits(t *testing.T) {
+	store, svc := newOutboundFixture(t)
+
+	_, err := svc.Create(8, OutboundInput{Items: []OutboundItemInput{{ProductID: 1, Quantity: 0}}})
+	wantKind(t, err, KindInvalid, "领用数量必须大于0")
+	_, err = svc.Create(8, OutboundInput{Kits: []OutboundKitInput{{KitID: 11, Quantity: 1}}})
+	wantKind(t, err, KindInvalid, "套件未定义组成: 11")
+
+	outbound, err := svc.Create(8, OutboundInput{
+		Purpose: "领用",
+		Items:   []OutboundItemInput{{ProductID: 1, Quantity: 1, Unit: "箱"}},
+		Kits:    []OutboundKitInput{{KitID: 10, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if outbound.Status != "PENDING" || outbound.DeptID != 2 || outbound.OutboundNo == "" {
+		t.Errorf("outbound = %+v", outbound)
+	}
+
+	items := store.data.outItems[outbound.ID]
+	if len(items) != 3 || items[0].ApplyQty != 12 || *items[0].UnitQty != 1 || items[0].KitID != nil {
+		t.Fatalf("items = %+v", items)
+	}
+	if items[1].ProductID != 1 || items[1].ApplyQty != 6 || items[1].Unit != "个" || *items[1].KitID != 10 ||
+		items[2].ProductID != 2 || items[2].ApplyQty != 2 {
+		t.Errorf("kit items = %+v", items[1:])
+	}
+
+	list, total, err := svc.List(repository.OutboundFilter{Scope: allData})
+	if err != nil || total != 1 {
+		t.Fatalf("list = %d, %v", total, err)
+	}
+	if o := list[0]; o.ApplicantName != "申请人" || o.DeptName != "研发部" || o.TotalQuantity != 20 || o.Status != "pending" {
+		t.Errorf("listed outbound = %+v", o)
+	}
+}
+
+func TestOutboundShipDeductsActualQuantityOnce(t *testing.T) {
+	store, svc := newOutboundFixture(t)
+	outbound, _ := svc.Create(8, OutboundInput{Items: []OutboundItemInput{{ProductID: 1, Quantity: 4}}})
+
+	if err := svc.Update(allData, outbound.ID, 7, OutboundUpdate{Status: "APPROVED", Purpose: "领用"}); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if saved := store.data.outbounds[outbound.ID]; *saved.ReviewerID != 7 || saved.ReviewTime == nil || store.data.products[1].StockQty != 10 {
+		t.Errorf("approved outbound = %+v", saved)
+	}
+
+	// 已拣货的明细按实发数量扣减
+	store.data.outItems[outbound.ID][0].ActualQty = ptr(3.0)
+	if err := svc.Update(allData, outbound.ID, 7, OutboundUpdate{Status: "DONE", Purpose: "领用"}); err != nil {
+		t.Fatalf("ship: %v", err)
+	}
+	if qty := store.data.products[1].StockQty; qty != 7 {
+		t.Errorf("stock = %v, want 7", qty)
+	}
+	if logs := store.data.logs; len(logs) != 1 || logs[0].Type != "OUT" || logs[0].ChangeQty != -3 ||
+		logs[0].SnapshotQty != 7 || logs[0].RelatedNo != outbound.OutboundNo {
+		t.Errorf("logs = %+v", logs)
+	}
+	if saved := store.data.outbounds[outbound.ID]; saved.OutboundDate == nil {
+		t.Errorf("shipped outbound = %+v", saved)
+	}
+
+	wantKind(t, svc.Update(allData, outbound.ID, 7, OutboundUpdate{Status: "DONE"}), KindInvalid, "已完成的出库单不能修改")
+	wantKind(t, svc.Delete(allData, outbound.ID), KindInvalid, "已完成的出库单不能删除")
+	if len(store.data.logs) != 1 {
+		t.Error("completed outbound shipped twice")
+	}
+}
+
+func TestOutboundShipBlockedByFreezingCheck(t *testing.T) {
+	store, svc := newOutboundFixture(t)
+	outbound, _ := svc.Create(8, OutboundInput{Items: []OutboundItemInput{{ProductID: 1, Quantity: 1}}})
+	store.data.frozen[1] = "CHK-0001"
+
+	err := svc.Update(allData, outbound.ID, 7, OutboundUpdate{Status: "DONE"})
+	wantKind(t, err, KindInvalid, "产品正在盘点中(CHK-0001)，暂不能出库")
+	if store.data.outbounds[outbound.ID].Status != "PENDING" || store.data.products[1].StockQty != 10 {
+		t.Error("blocked shipment must not change anything")
+	}
+}
+
+func TestOutboundDataScope(t *testing.T) {
+	_, svc := newOutboundFixture(t)
+	outbound, _ := svc.Create(8, OutboundInput{Items: []OutboundItemInput{{ProductID: 1, Quantity: 1}}})
+
+	other := repository.Scope{Level: repository.ScopeSelf, UserID: 9}
+	_, _, err := svc.Get(other, outbound.ID)
+	wantKind(t, err, KindNotFound, "出库单不存在")
+	wantKind(t, svc.Update(other, outbound.ID, 9, OutboundUpdate{Status: "DONE"}), KindNotFound, "出库单不存在")
+	wantKind(t, svc.Delete(other, outbound.ID), KindNotFound, "出库单不存在")
+
+	if err := svc.Delete(allData, outbound.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	wantKind(t, svc.Restore(other, outbound.ID), KindNotFound, "已删除的出库单不存在")
+	if err := svc.Restore(repository.Scope{Level: repository.ScopeSelf, UserID: 8}, outbound.ID); err != nil {
+		t.Fatalf("applicant restore: %v", err)
+	}
+}
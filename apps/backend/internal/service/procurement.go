@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+	"easywms/internal/sequence"
+)
+
+// ProcurementItemInput 采购明细，数量和单价按 Unit 计，Unit 为空时为基本单位
+type ProcurementItemInput struct {
+	ProductID int64
+	Quantity  float64
+	Price     float64
+	Unit      string
+}
+
+// ProcurementInput 创建采购单的内容
+type ProcurementInput struct {
+	SupplierID   *int64
+	Reason       string
+	ExpectedDate *time.Time
+	Items        []ProcurementItemInput
+}
+
+// ProcurementUpdate 修改采购单的内容，SupplierID、ExpectedDate 为空或 Status 为空时保留原值
+type ProcurementUpdate struct {
+	SupplierID   *int64
+	Status       string
+	Reason       string
+	ExpectedDate *time.Time
+}
+
+// ProcurementService 采购单业务
+type ProcurementService struct {
+	store repository.Store
+}
+
+// NewProcurementService 创建采购单业务服务
+func NewProcurementService(store repository.Store) *ProcurementService {
+	return &ProcurementService{store: store}
+}
+
+// List 查询采购单列表，并填充申请人、供应商和总金额
+func (s *ProcurementService) List(filter repository.ProcurementFilter) ([]model.Procurement, int64, error) {
+	procurements, total, err := s.store.Procurements().List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(procurements))
+	supplierIDs := make([]int64, 0)
+	procurementIDs := make([]int64, 0, len(procurements))
+	for _, p := range procurements {
+		userIDs = append(userIDs, p.ApplicantID)
+		if p.SupplierID != nil && *p.SupplierID > 0 {
+			supplierIDs = append(supplierIDs, *p.SupplierID)
+		}
+		procurementIDs = append(procurementIDs, p.ID)
+	}
+
+	userMap, err := s.store.Users().RealNames(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	supplierMap, err := s.store.Suppliers().Names(supplierIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	amountMap, err := s.store.Procurements().TotalAmounts(procurementIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range procurements {
+		procurements[i].ApplicantName = userMap[procurements[i].ApplicantID]
+		if procurements[i].SupplierID != nil {
+			procurements[i].SupplierName = supplierMap[*procurements[i].SupplierID]
+		}
+		procurements[i].TotalAmount = amountMap[procurements[i].ID]
+	}
+	return procurements, total, nil
+}
+
+// Get 查询采购单及明细，填充申请人、供应商、产品名称编码和明细金额
+func (s *ProcurementService) Get(scope repository.Scope, id int64) (*model.Procurement, []model.ProcurementItem, error) {
+	procurement, err := s.Find(scope, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.store.Procurements().Items(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productMap, err := productsByID(s.store, procurementProductIDs(items))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		if p, ok := productMap[items[i].ProductID]; ok {
+			items[i].ProductName = p.Name
+			items[i].ProductCode = p.SKUCode
+		}
+		// 优先按录入单位的数量和单价计算金额，避免基本单位单价的舍入误差
+		if items[i].EnteredPrice != nil && items[i].UnitQty != nil {
+			items[i].Amount = *items[i].UnitQty * *items[i].EnteredPrice
+		} else if items[i].UnitPrice != nil {
+			items[i].Amount = items[i].PlanQty * *items[i].UnitPrice
+		}
+	}
+
+	userMap, err := s.store.Users().RealNames([]int64{procurement.ApplicantID})
+	if err != nil {
+		return nil, nil, err
+	}
+	procurement.ApplicantName = userMap[procurement.ApplicantID]
+	if procurement.SupplierID != nil {
+		supplierMap, err := s.store.Suppliers().Names([]int64{*procurement.SupplierID})
+		if err != nil {
+			return nil, nil, err
+		}
+		procurement.SupplierName = supplierMap[*procurement.SupplierID]
+	}
+	return procurement, items, nil
+}
+
+// Find 查询数据权限内未删除的采购单
+func (s *ProcurementService) Find(scope repository.Scope, id int64) (*model.Procurement, error) {
+	procurement, err := s.store.Procurements().Get(scope, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("采购单不存在")
+	}
+	return procurement, err
+}
+
+// Create 创建待审批的采购单，数量和单价按基本单位保存，录入单位、数量和单价保留用于打印
+func (s *ProcurementService) Create(applicantID int64, in ProcurementInput) (*model.Procurement, error) {
+	procurement := &model.Procurement{
+		ApplicantID:  applicantID,
+		SupplierID:   in.SupplierID,
+		Status:       "PENDING",
+		Reason:       in.Reason,
+		ExpectedDate: in.ExpectedDate,
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		items := make([]model.ProcurementItem, 0, len(in.Items))
+		for _, input := range in.Items {
+			factor, unit, err := UnitFactor(tx, input.ProductID, input.Unit)
+			if err != nil {
+				return err
+			}
+			price := input.Price / factor
+			unitQty := input.Quantity
+			enteredPrice := input.Price
+			items = append(items, model.ProcurementItem{
+				ProductID:    input.ProductID,
+				PlanQty:      input.Quantity * factor,
+				UnitPrice:    &price,
+				Unit:         unit,
+				UnitQty:      &unitQty,
+				EnteredPrice: &enteredPrice,
+			})
+		}
+
+		var err error
+		if procurement.OrderNo, err = tx.NextNumber(sequence.Procurement); err != nil {
+			return fmt.Errorf("生成单号失败: %w", err)
+		}
+		return tx.Procurements().Create(procurement, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return procurement, nil
+}
+
+// Update 修改采购单的事由、供应商、预计到货日期和状态，状态流转的权限由调用方校验
+func (s *ProcurementService) Update(scope repository.Scope, id int64, in ProcurementUpdate) error {
+	procurement, err := s.Find(scope, id)
+	if err != nil {
+		return err
+	}
+
+	procurement.Reason = in.Reason
+	if in.Status != "" {
+		procurement.Status = in.Status
+	}
+	if in.SupplierID != nil {
+		procurement.SupplierID = in.SupplierID
+	}
+	if in.ExpectedDate != nil {
+		procurement.ExpectedDate = in.ExpectedDate
+	}
+	return s.store.Procurements().Update(procurement)
+}
+
+// Delete 删除采购单（软删除，明细保留以便恢复）
+func (s *ProcurementService) Delete(scope repository.Scope, id int64) error {
+	if _, err := s.Find(scope, id); err != nil {
+		return err
+	}
+	return s.store.Procurements().Delete(id)
+}
+
+// Restore 恢复已删除的采购单，明细中的产品已删除时不能恢复
+func (s *ProcurementService) Restore(scope repository.Scope, id int64) error {
+	if _, err := s.store.Procurements().GetDeleted(scope, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("已删除的采购单不存在")
+		}
+		return err
+	}
+
+	items, err := s.store.Procurements().Items(id)
+	if err != nil {
+		return err
+	}
+	if err := checkDeletedProducts(s.store, procurementProductIDs(items)); err != nil {
+		return err
+	}
+	return s.store.Procurements().Restore(id)
+}
+
+// procurementProductIDs 采购明细中的产品ID
+func procurementProductIDs(items []model.ProcurementItem) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
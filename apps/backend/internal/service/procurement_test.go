@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// newProcurementFixture 与入库单共用产品数据，供应商5为“五金店”，用户8为采购员
+func newProcurementFixture() (*fakeStore, *ProcurementService) {
+	store, _ := newInboundFixture()
+	store.data.suppliers[5] = "五金店"
+	store.data.users[8] = "采购员"
+	return store, NewProcurementService(store)
+}
+
+var allData = repository.Scope{Level: repository.ScopeAll}
+
+func TestProcurementCreateConvertsUnitsAndPrices(t *testing.T) {
+	store, svc := newProcurementFixture()
+
+	procurement, err := svc.Create(8, ProcurementInput{SupplierID: ptr(int64(5)), Items: []ProcurementItemInput{
+		{ProductID: 1, Quantity: 2, Price: 6, Unit: "箱"},
+		{ProductID: 2, Quantity: 3, Price: 20},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if procurement.OrderNo == "" || procurement.Status != "PENDING" || procurement.ApplicantID != 8 {
+		t.Errorf("procurement = %+v", procurement)
+	}
+
+	items := store.data.procItems[procurement.ID]
+	if len(items) != 2 || items[0].PlanQty != 24 || *items[0].UnitPrice != 0.5 ||
+		*items[0].UnitQty != 2 || *items[0].EnteredPrice != 6 || items[0].Unit != "箱" {
+		t.Errorf("items = %+v", items)
+	}
+
+	_, err = svc.Create(8, ProcurementInput{Items: []ProcurementItemInput{{ProductID: 2, Quantity: 1, Unit: "箱"}}})
+	wantKind(t, err, KindInvalid, "产品扳手未定义计量单位: 箱")
+	if len(store.data.procurements) != 2 {
+		t.Error("rejected procurement must not be saved")
+	}
+}
+
+func TestProcurementListAndGetFillDisplayFields(t *testing.T) {
+	_, svc := newProcurementFixture()
+	procurement, _ := svc.Create(8, ProcurementInput{SupplierID: ptr(int64(5)), Items: []ProcurementItemInput{
+		{ProductID: 1, Quantity: 2, Price: 6, Unit: "箱"},
+		{ProductID: 2, Quantity: 3, Price: 20},
+	}})
+
+	// 固定数据中已有来源采购单3
+	list, total, err := svc.List(repository.ProcurementFilter{Scope: allData})
+	if err != nil || total != 2 {
+		t.Fatalf("list = %d, %v", total, err)
+	}
+	if p := list[0]; p.ApplicantName != "采购员" || p.SupplierName != "五金店" || p.TotalAmount != 72 {
+		t.Errorf("listed procurement = %+v", p)
+	}
+
+	got, items, err := svc.Get(allData, procurement.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.ApplicantName != "采购员" || got.SupplierName != "五金店" {
+		t.Errorf("procurement = %+v", got)
+	}
+	if len(items) != 2 || items[0].ProductName != "螺丝" || items[0].Amount != 12 || items[1].Amount != 60 {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestProcurementDataScope(t *testing.T) {
+	_, svc := newProcurementFixture()
+	procurement, _ := svc.Create(8, ProcurementInput{Reason: "补货"})
+
+	other := repository.Scope{Level: repository.ScopeSelf, UserID: 9}
+	if _, total, _ := svc.List(repository.ProcurementFilter{Scope: other}); total != 0 {
+		t.Errorf("other applicant sees %d procurements", total)
+	}
+	_, _, err := svc.Get(other, procurement.ID)
+	wantKind(t, err, KindNotFound, "采购单不存在")
+	wantKind(t, svc.Update(other, procurement.ID, ProcurementUpdate{Status: "APPROVED"}), KindNotFound, "采购单不存在")
+	wantKind(t, svc.Delete(other, procurement.ID), KindNotFound, "采购单不存在")
+
+	own := repository.Scope{Level: repository.ScopeSelf, UserID: 8}
+	if _, _, err := svc.Get(own, procurement.ID); err != nil {
+		t.Errorf("applicant get: %v", err)
+	}
+}
+
+func TestProcurementUpdateKeepsUnspecifiedFields(t *testing.T) {
+	store, svc := newProcurementFixture()
+	expected := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	procurement, _ := svc.Create(8, ProcurementInput{SupplierID: ptr(int64(5)), ExpectedDate: &expected})
+
+	if err := svc.Update(allData, procurement.ID, ProcurementUpdate{Status: "APPROVED", Reason: "季度补货"}); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := svc.Update(allData, procurement.ID, ProcurementUpdate{Reason: "季度补货"}); err != nil {
+		t.Fatalf("update reason: %v", err)
+	}
+
+	saved := store.data.procurements[procurement.ID]
+	if saved.Status != "APPROVED" || saved.Reason != "季度补货" || *saved.SupplierID != 5 || !saved.ExpectedDate.Equal(expected) {
+		t.Errorf("procurement = %+v", saved)
+	}
+}
+
+func TestProcurementRestoreRejectsDeletedProduct(t *testing.T) {
+	store, svc := newProcurementFixture()
+	procurement, _ := svc.Create(8, ProcurementInput{Items: []ProcurementItemInput{{ProductID: 2, Quantity: 1}}})
+	if err := svc.Delete(allData, procurement.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	wantKind(t, svc.Delete(allData, procurement.ID), KindNotFound, "采购单不存在")
+
+	product := store.data.products[2]
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	store.data.products[2] = product
+	wantKind(t, svc.Restore(allData, procurement.ID), KindInvalid, "明细中的产品已删除: 扳手")
+
+	store.data.products[2] = model.Product{ID: 2, Name: "扳手", Unit: "件"}
+	if err := svc.Restore(allData, procurement.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	wantKind(t, svc.Restore(allData, procurement.ID), KindNotFound, "已删除的采购单不存在")
+}
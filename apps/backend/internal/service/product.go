@@ -0,0 +1,31 @@
+package service
+
+import (
+	"easywms/internal/model"
+	"easywms/internal/repository"
+)
+
+// productsByID 按 ID 查询产品（包括已删除的产品），用于填充明细的产品名称和编码
+func productsByID(store repository.Store, ids []int64) (map[int64]model.Product, error) {
+	products, err := store.Products().FindWithDeleted(ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64]model.Product, len(products))
+	for _, p := range products {
+		result[p.ID] = p
+	}
+	return result, nil
+}
+
+// checkDeletedProducts 单据明细中的产品已删除时返回业务错误，恢复单据前调用
+func checkDeletedProducts(store repository.Store, ids []int64) error {
+	name, deleted, err := store.Products().FirstDeletedName(ids)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		return invalid("明细中的产品已删除: " + name)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+)
+
+// PostStock 调整产品库存并记录库存流水，须在事务中调用
+func PostStock(tx repository.Store, productID int64, logType string, changeQty float64, relatedNo string, operatorID *int64) error {
+	snapshot, err := tx.Stock().Adjust(productID, changeQty)
+	if err != nil {
+		return err
+	}
+	return tx.Stock().AddLog(&model.StockLog{
+		ProductID:   productID,
+		Type:        logType,
+		ChangeQty:   changeQty,
+		SnapshotQty: snapshot,
+		RelatedNo:   relatedNo,
+		OperatorID:  operatorID,
+	})
+}
+
+// UnitFactor 返回单位折合基本单位的系数和实际记录的单位，unit 为空时按基本单位处理
+func UnitFactor(store repository.Store, productID int64, unit string) (float64, string, error) {
+	product, err := store.Products().Get(productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, "", invalid(fmt.Sprintf("产品不存在: %d", productID))
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if unit == "" || unit == product.Unit {
+		return 1, product.Unit, nil
+	}
+
+	productUnit, err := store.Products().Unit(productID, unit)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, "", invalid(fmt.Sprintf("产品%s未定义计量单位: %s", product.Name, unit))
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return productUnit.Factor, unit, nil
+}
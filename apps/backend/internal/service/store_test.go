@@ -0,0 +1,763 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"easywms/internal/model"
+	"easywms/internal/repository"
+)
+
+// fakeData 内存中的数据
+type fakeData struct {
+	inbounds     map[int64]model.Inbound
+	inboundItems map[int64][]model.InboundItem
+	outbounds    map[int64]model.Outbound
+	outItems     map[int64][]model.OutboundItem
+	kits         map[int64][]model.KitComponent
+	assemblies   map[int64]model.KitAssembly
+	deleted      map[int64]bool
+	products     map[int64]model.Product
+	units        map[string]model.ProductUnit
+	frozen       map[int64]string
+	logs         []model.StockLog
+	users        map[int64]string
+	userDepts    map[int64]int64
+	depts        map[int64]string
+	procurements map[int64]model.Procurement
+	procItems    map[int64][]model.ProcurementItem
+	suppliers    map[int64]string
+	checks       map[int64]model.InventoryCheck
+	checkItems   map[int64][]model.InventoryCheckItem
+	checkCounts  []model.InventoryCheckCount
+	nextID       int64
+	nextNo       int
+}
+
+// fakeStore 仓储的内存实现，事务在数据副本上执行，成功后替换原数据
+type fakeStore struct {
+	data *fakeData
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{data: &fakeData{
+		inbounds:     map[int64]model.Inbound{},
+		inboundItems: map[int64][]model.InboundItem{},
+		outbounds:    map[int64]model.Outbound{},
+		outItems:     map[int64][]model.OutboundItem{},
+		kits:         map[int64][]model.KitComponent{},
+		assemblies:   map[int64]model.KitAssembly{},
+		deleted:      map[int64]bool{},
+		products:     map[int64]model.Product{},
+		units:        map[string]model.ProductUnit{},
+		frozen:       map[int64]string{},
+		users:        map[int64]string{},
+		userDepts:    map[int64]int64{},
+		depts:        map[int64]string{},
+		procurements: map[int64]model.Procurement{},
+		procItems:    map[int64][]model.ProcurementItem{},
+		suppliers:    map[int64]string{},
+		checks:       map[int64]model.InventoryCheck{},
+		checkItems:   map[int64][]model.InventoryCheckItem{},
+		nextID:       100,
+	}}
+}
+
+func (d *fakeData) clone() *fakeData {
+	c := *d
+	c.inbounds = map[int64]model.Inbound{}
+	for k, v := range d.inbounds {
+		c.inbounds[k] = v
+	}
+	c.inboundItems = map[int64][]model.InboundItem{}
+	for k, v := range d.inboundItems {
+		c.inboundItems[k] = append([]model.InboundItem(nil), v...)
+	}
+	c.outbounds = map[int64]model.Outbound{}
+	for k, v := range d.outbounds {
+		c.outbounds[k] = v
+	}
+	c.outItems = map[int64][]model.OutboundItem{}
+	for k, v := range d.outItems {
+		c.outItems[k] = append([]model.OutboundItem(nil), v...)
+	}
+	c.kits = map[int64][]model.KitComponent{}
+	for k, v := range d.kits {
+		c.kits[k] = append([]model.KitComponent(nil), v...)
+	}
+	c.assemblies = map[int64]model.KitAssembly{}
+	for k, v := range d.assemblies {
+		c.assemblies[k] = v
+	}
+	c.deleted = map[int64]bool{}
+	for k, v := range d.deleted {
+		c.deleted[k] = v
+	}
+	c.procurements = map[int64]model.Procurement{}
+	for k, v := range d.procurements {
+		c.procurements[k] = v
+	}
+	c.procItems = map[int64][]model.ProcurementItem{}
+	for k, v := range d.procItems {
+		c.procItems[k] = append([]model.ProcurementItem(nil), v...)
+	}
+	c.products = map[int64]model.Product{}
+	for k, v := range d.products {
+		c.products[k] = v
+	}
+	c.checks = map[int64]model.InventoryCheck{}
+	for k, v := range d.checks {
+		c.checks[k] = v
+	}
+	c.checkItems = map[int64][]model.InventoryCheckItem{}
+	for k, v := range d.checkItems {
+		c.checkItems[k] = append([]model.InventoryCheckItem(nil), v...)
+	}
+	c.checkCounts = append([]model.InventoryCheckCount(nil), d.checkCounts...)
+	c.logs = append([]model.StockLog(nil), d.logs...)
+	return &c
+}
+
+func (d *fakeData) id() int64 {
+	d.nextID++
+	return d.nextID
+}
+
+func (s *fakeStore) Inbounds() repository.InboundRepository   { return fakeInbounds{s.data} }
+func (s *fakeStore) Outbounds() repository.OutboundRepository { return fakeOutbounds{s.data} }
+func (s *fakeStore) Products() repository.ProductRepository   { return fakeProducts{s.data} }
+func (s *fakeStore) Stock() repository.StockRepository        { return fakeStock{s.data} }
+func (s *fakeStore) Users() repository.UserRepository         { return fakeUsers{s.data} }
+func (s *fakeStore) Departments() repository.DepartmentRepository {
+	return fakeNames(s.data.depts)
+}
+func (s *fakeStore) Kits() repository.KitRepository            { return fakeKits{s.data} }
+func (s *fakeStore) Assemblies() repository.AssemblyRepository { return fakeAssemblies{s.data} }
+func (s *fakeStore) Procurements() repository.ProcurementRepository {
+	return fakeProcurements{s.data}
+}
+func (s *fakeStore) Suppliers() repository.SupplierRepository { return fakeNames(s.data.suppliers) }
+func (s *fakeStore) InventoryChecks() repository.InventoryCheckRepository {
+	return fakeChecks{s.data}
+}
+
+func (s *fakeStore) NextNumber(docType string) (string, error) {
+	s.data.nextNo++
+	return fmt.Sprintf("%s-%04d", strings.ToUpper(docType), s.data.nextNo), nil
+}
+
+func (s *fakeStore) Transaction(fn func(tx repository.Store) error) error {
+	tx := &fakeStore{data: s.data.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*s.data = *tx.data
+	return nil
+}
+
+type fakeInbounds struct{ d *fakeData }
+
+func (r fakeInbounds) List(filter repository.InboundFilter) ([]model.Inbound, int64, error) {
+	var result []model.Inbound
+	for id, inbound := range r.d.inbounds {
+		if r.d.deleted[id] != filter.Deleted {
+			continue
+		}
+		if filter.Status != nil && inbound.Status != *filter.Status {
+			continue
+		}
+		if !strings.Contains(inbound.InboundNo, filter.OrderNo) {
+			continue
+		}
+		result = append(result, inbound)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, int64(len(result)), nil
+}
+
+func (r fakeInbounds) Get(id int64) (*model.Inbound, error) {
+	inbound, ok := r.d.inbounds[id]
+	if !ok || r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &inbound, nil
+}
+
+func (r fakeInbounds) GetDeleted(id int64) (*model.Inbound, error) {
+	inbound, ok := r.d.inbounds[id]
+	if !ok || !r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &inbound, nil
+}
+
+func (r fakeInbounds) Items(inboundID int64) ([]model.InboundItem, error) {
+	return append([]model.InboundItem(nil), r.d.inboundItems[inboundID]...), nil
+}
+
+func (r fakeInbounds) TotalQuantities(inboundIDs []int64) (map[int64]float64, error) {
+	result := map[int64]float64{}
+	for _, id := range inboundIDs {
+		for _, item := range r.d.inboundItems[id] {
+			result[id] += item.ActualQty
+		}
+	}
+	return result, nil
+}
+
+func (r fakeInbounds) Create(inbound *model.Inbound, items []model.InboundItem) error {
+	inbound.ID = r.d.id()
+	r.d.inbounds[inbound.ID] = *inbound
+	return r.ReplaceItems(inbound.ID, items)
+}
+
+func (r fakeInbounds) Update(inbound *model.Inbound, status int) error {
+	current, ok := r.d.inbounds[inbound.ID]
+	if !ok || r.d.deleted[inbound.ID] || current.Status != status {
+		return repository.ErrConflict
+	}
+	r.d.inbounds[inbound.ID] = *inbound
+	return nil
+}
+
+func (r fakeInbounds) ReplaceItems(inboundID int64, items []model.InboundItem) error {
+	r.d.inboundItems[inboundID] = nil
+	for _, item := range items {
+		item.ID = r.d.id()
+		item.InboundID = inboundID
+		r.d.inboundItems[inboundID] = append(r.d.inboundItems[inboundID], item)
+	}
+	return nil
+}
+
+func (r fakeInbounds) Delete(id int64) error {
+	r.d.deleted[id] = true
+	return nil
+}
+
+func (r fakeInbounds) Restore(id int64) error {
+	delete(r.d.deleted, id)
+	return nil
+}
+
+type fakeProducts struct{ d *fakeData }
+
+func (r fakeProducts) Get(id int64) (*model.Product, error) {
+	product, ok := r.d.products[id]
+	if !ok || product.DeletedAt.Valid {
+		return nil, repository.ErrNotFound
+	}
+	return &product, nil
+}
+
+func (r fakeProducts) FindWithDeleted(ids []int64) ([]model.Product, error) {
+	var result []model.Product
+	for _, id := range ids {
+		if product, ok := r.d.products[id]; ok {
+			result = append(result, product)
+		}
+	}
+	return result, nil
+}
+
+func (r fakeProducts) FirstDeletedName(ids []int64) (string, bool, error) {
+	for _, id := range ids {
+		if product, ok := r.d.products[id]; ok && product.DeletedAt.Valid {
+			return product.Name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (r fakeProducts) Unit(productID int64, unit string) (*model.ProductUnit, error) {
+	productUnit, ok := r.d.units[fmt.Sprintf("%d/%s", productID, unit)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &productUnit, nil
+}
+
+// Match 内存实现只按分类筛选
+func (r fakeProducts) Match(filter repository.ProductMatch) ([]int64, error) {
+	var products []model.Product
+	for _, product := range r.d.products {
+		if !product.DeletedAt.Valid && (filter.CategoryID == 0 || product.CategoryID == filter.CategoryID) {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].SKUCode < products[j].SKUCode })
+	ids := make([]int64, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids, nil
+}
+
+type fakeStock struct{ d *fakeData }
+
+func (r fakeStock) FreezingCheck(productIDs []int64) (string, bool, error) {
+	for _, id := range productIDs {
+		if checkNo, ok := r.d.frozen[id]; ok {
+			return checkNo, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (r fakeStock) Adjust(productID int64, delta float64) (float64, error) {
+	product, ok := r.d.products[productID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	product.StockQty += delta
+	r.d.products[productID] = product
+	return product.StockQty, nil
+}
+
+func (r fakeStock) Deduct(productID int64, qty float64) (float64, error) {
+	product, ok := r.d.products[productID]
+	if !ok || product.StockQty < qty {
+		return 0, repository.ErrConflict
+	}
+	return r.Adjust(productID, -qty)
+}
+
+func (r fakeStock) AddLog(log *model.StockLog) error {
+	log.ID = r.d.id()
+	r.d.logs = append(r.d.logs, *log)
+	return nil
+}
+
+func (r fakeStock) LastLogID() (int64, error) {
+	return r.LastLogIDBefore(time.Now())
+}
+
+func (r fakeStock) LastLogIDBefore(t time.Time) (int64, error) {
+	var id int64
+	for _, log := range r.d.logs {
+		if !log.CreatedAt.After(t) && log.ID > id {
+			id = log.ID
+		}
+	}
+	return id, nil
+}
+
+func (r fakeStock) MovedSince(productID, logID int64) (float64, error) {
+	var moved float64
+	for _, log := range r.d.logs {
+		if log.ProductID == productID && log.ID > logID {
+			moved += log.ChangeQty
+		}
+	}
+	return moved, nil
+}
+
+type fakeUsers struct{ d *fakeData }
+
+func (r fakeUsers) RealNames(ids []int64) (map[int64]string, error) {
+	return fakeNames(r.d.users).pick(ids), nil
+}
+
+func (r fakeUsers) DeptID(userID int64) (int64, error) { return r.d.userDepts[userID], nil }
+
+type fakeNames map[int64]string
+
+func (m fakeNames) Names(ids []int64) (map[int64]string, error) { return m.pick(ids), nil }
+
+func (m fakeNames) pick(ids []int64) map[int64]string {
+	result := map[int64]string{}
+	for _, id := range ids {
+		if name, ok := m[id]; ok {
+			result[id] = name
+		}
+	}
+	return result
+}
+
+// visible 单据是否在数据权限内，内存实现不区分部门，DEPT 按全部数据处理
+func visible(scope repository.Scope, ownerID int64) bool {
+	return scope.Level != repository.ScopeSelf || scope.UserID == ownerID
+}
+
+type fakeProcurements struct{ d *fakeData }
+
+func (r fakeProcurements) List(filter repository.ProcurementFilter) ([]model.Procurement, int64, error) {
+	var result []model.Procurement
+	for id, p := range r.d.procurements {
+		if r.d.deleted[id] != filter.Deleted || !visible(filter.Scope, p.ApplicantID) {
+			continue
+		}
+		if filter.Status != "" && p.Status != filter.Status {
+			continue
+		}
+		if !strings.Contains(p.OrderNo, filter.OrderNo) {
+			continue
+		}
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, int64(len(result)), nil
+}
+
+func (r fakeProcurements) Get(scope repository.Scope, id int64) (*model.Procurement, error) {
+	p, ok := r.d.procurements[id]
+	if !ok || r.d.deleted[id] || !visible(scope, p.ApplicantID) {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r fakeProcurements) GetDeleted(scope repository.Scope, id int64) (*model.Procurement, error) {
+	p, ok := r.d.procurements[id]
+	if !ok || !r.d.deleted[id] || !visible(scope, p.ApplicantID) {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r fakeProcurements) Items(procurementID int64) ([]model.ProcurementItem, error) {
+	return append([]model.ProcurementItem(nil), r.d.procItems[procurementID]...), nil
+}
+
+func (r fakeProcurements) TotalAmounts(procurementIDs []int64) (map[int64]float64, error) {
+	result := map[int64]float64{}
+	for _, id := range procurementIDs {
+		for _, item := range r.d.procItems[id] {
+			if item.UnitPrice != nil {
+				result[id] += item.PlanQty * *item.UnitPrice
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r fakeProcurements) Create(procurement *model.Procurement, items []model.ProcurementItem) error {
+	procurement.ID = r.d.id()
+	r.d.procurements[procurement.ID] = *procurement
+	for _, item := range items {
+		item.ID = r.d.id()
+		item.ProcurementID = procurement.ID
+		r.d.procItems[procurement.ID] = append(r.d.procItems[procurement.ID], item)
+	}
+	return nil
+}
+
+func (r fakeProcurements) Update(procurement *model.Procurement) error {
+	r.d.procurements[procurement.ID] = *procurement
+	return nil
+}
+
+func (r fakeProcurements) Delete(id int64) error {
+	r.d.deleted[id] = true
+	return nil
+}
+
+func (r fakeProcurements) Restore(id int64) error {
+	delete(r.d.deleted, id)
+	return nil
+}
+
+func (r fakeProcurements) OrderNos(ids []int64) (map[int64]string, error) {
+	result := map[int64]string{}
+	for _, id := range ids {
+		if p, ok := r.d.procurements[id]; ok {
+			result[id] = p.OrderNo
+		}
+	}
+	return result, nil
+}
+
+type fakeOutbounds struct{ d *fakeData }
+
+func (r fakeOutbounds) List(filter repository.OutboundFilter) ([]model.Outbound, int64, error) {
+	var result []model.Outbound
+	for id, o := range r.d.outbounds {
+		if r.d.deleted[id] != filter.Deleted || !visible(filter.Scope, o.ApplicantID) {
+			continue
+		}
+		if filter.Status != "" && o.Status != filter.Status {
+			continue
+		}
+		if !strings.Contains(o.OutboundNo, filter.OrderNo) {
+			continue
+		}
+		result = append(result, o)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, int64(len(result)), nil
+}
+
+func (r fakeOutbounds) Get(scope repository.Scope, id int64) (*model.Outbound, error) {
+	o, ok := r.d.outbounds[id]
+	if !ok || r.d.deleted[id] || !visible(scope, o.ApplicantID) {
+		return nil, repository.ErrNotFound
+	}
+	return &o, nil
+}
+
+func (r fakeOutbounds) GetDeleted(scope repository.Scope, id int64) (*model.Outbound, error) {
+	o, ok := r.d.outbounds[id]
+	if !ok || !r.d.deleted[id] || !visible(scope, o.ApplicantID) {
+		return nil, repository.ErrNotFound
+	}
+	return &o, nil
+}
+
+func (r fakeOutbounds) Items(outboundID int64) ([]model.OutboundItem, error) {
+	return append([]model.OutboundItem(nil), r.d.outItems[outboundID]...), nil
+}
+
+func (r fakeOutbounds) TotalQuantities(outboundIDs []int64) (map[int64]float64, error) {
+	result := map[int64]float64{}
+	for _, id := range outboundIDs {
+		for _, item := range r.d.outItems[id] {
+			result[id] += item.ApplyQty
+		}
+	}
+	return result, nil
+}
+
+func (r fakeOutbounds) Create(outbound *model.Outbound, items []model.OutboundItem) error {
+	outbound.ID = r.d.id()
+	r.d.outbounds[outbound.ID] = *outbound
+	for _, item := range items {
+		item.ID = r.d.id()
+		item.OutboundID = outbound.ID
+		r.d.outItems[outbound.ID] = append(r.d.outItems[outbound.ID], item)
+	}
+	return nil
+}
+
+func (r fakeOutbounds) Update(outbound *model.Outbound) error {
+	current, ok := r.d.outbounds[outbound.ID]
+	if !ok || r.d.deleted[outbound.ID] || current.Status == "DONE" {
+		return repository.ErrConflict
+	}
+	r.d.outbounds[outbound.ID] = *outbound
+	return nil
+}
+
+func (r fakeOutbounds) SetActualQty(itemID int64, qty float64) error {
+	for outboundID, items := range r.d.outItems {
+		for i := range items {
+			if items[i].ID == itemID {
+				items[i].ActualQty = &qty
+				r.d.outItems[outboundID] = items
+				return nil
+			}
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r fakeOutbounds) Delete(id int64) error {
+	r.d.deleted[id] = true
+	return nil
+}
+
+func (r fakeOutbounds) Restore(id int64) error {
+	delete(r.d.deleted, id)
+	return nil
+}
+
+type fakeKits struct{ d *fakeData }
+
+func (r fakeKits) Components(kitID int64) ([]model.KitComponent, error) {
+	return append([]model.KitComponent(nil), r.d.kits[kitID]...), nil
+}
+
+func (r fakeKits) IsComponent(productID int64) (bool, error) {
+	for _, components := range r.d.kits {
+		for _, comp := range components {
+			if comp.ProductID == productID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r fakeKits) ReplaceComponents(kitID int64, components []model.KitComponent) error {
+	r.d.kits[kitID] = nil
+	for _, comp := range components {
+		comp.ID = r.d.id()
+		comp.KitID = kitID
+		r.d.kits[kitID] = append(r.d.kits[kitID], comp)
+	}
+	return nil
+}
+
+type fakeAssemblies struct{ d *fakeData }
+
+func (r fakeAssemblies) List(filter repository.AssemblyFilter) ([]model.KitAssembly, int64, error) {
+	var result []model.KitAssembly
+	for id, a := range r.d.assemblies {
+		if r.d.deleted[id] != filter.Deleted || (filter.Status != "" && a.Status != filter.Status) {
+			continue
+		}
+		if !strings.Contains(a.AssemblyNo, filter.OrderNo) {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, int64(len(result)), nil
+}
+
+func (r fakeAssemblies) Get(id int64) (*model.KitAssembly, error) {
+	a, ok := r.d.assemblies[id]
+	if !ok || r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (r fakeAssemblies) GetDeleted(id int64) (*model.KitAssembly, error) {
+	a, ok := r.d.assemblies[id]
+	if !ok || !r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (r fakeAssemblies) Create(assembly *model.KitAssembly) error {
+	assembly.ID = r.d.id()
+	r.d.assemblies[assembly.ID] = *assembly
+	return nil
+}
+
+func (r fakeAssemblies) Update(assembly *model.KitAssembly, status string) error {
+	current, ok := r.d.assemblies[assembly.ID]
+	if !ok || r.d.deleted[assembly.ID] || current.Status != status {
+		return repository.ErrConflict
+	}
+	r.d.assemblies[assembly.ID] = *assembly
+	return nil
+}
+
+func (r fakeAssemblies) Delete(id int64) error {
+	r.d.deleted[id] = true
+	return nil
+}
+
+func (r fakeAssemblies) Restore(id int64) error {
+	delete(r.d.deleted, id)
+	return nil
+}
+
+type fakeChecks struct{ d *fakeData }
+
+func (r fakeChecks) List(filter repository.InventoryCheckFilter) ([]model.InventoryCheck, int64, error) {
+	var result []model.InventoryCheck
+	for id, check := range r.d.checks {
+		if r.d.deleted[id] != filter.Deleted || (filter.Status != "" && check.Status != filter.Status) {
+			continue
+		}
+		if !strings.Contains(check.CheckNo, filter.CheckNo) {
+			continue
+		}
+		result = append(result, check)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, int64(len(result)), nil
+}
+
+func (r fakeChecks) Get(id int64) (*model.InventoryCheck, error) {
+	check, ok := r.d.checks[id]
+	if !ok || r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &check, nil
+}
+
+func (r fakeChecks) GetDeleted(id int64) (*model.InventoryCheck, error) {
+	check, ok := r.d.checks[id]
+	if !ok || !r.d.deleted[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &check, nil
+}
+
+func (r fakeChecks) Lock(id int64) (*model.InventoryCheck, error) { return r.Get(id) }
+
+func (r fakeChecks) Items(checkID int64) ([]model.InventoryCheckItem, error) {
+	return append([]model.InventoryCheckItem(nil), r.d.checkItems[checkID]...), nil
+}
+
+func (r fakeChecks) Create(check *model.InventoryCheck) error {
+	check.ID = r.d.id()
+	r.d.checks[check.ID] = *check
+	return nil
+}
+
+func (r fakeChecks) CreateItem(item *model.InventoryCheckItem) error {
+	item.ID = r.d.id()
+	r.d.checkItems[item.CheckID] = append(r.d.checkItems[item.CheckID], *item)
+	return nil
+}
+
+func (r fakeChecks) CountItem(checkID, productID int64, round int, qty float64) error {
+	items := r.d.checkItems[checkID]
+	for i := range items {
+		if items[i].ProductID != productID || (round > 1 && items[i].NeedRecount == 0) {
+			continue
+		}
+		items[i].ActualQty = qty
+		items[i].DiffQty = qty - items[i].BookQty
+		items[i].Counted = 1
+		items[i].Round = round
+		return nil
+	}
+	return repository.ErrNotFound
+}
+
+func (r fakeChecks) AddCount(count *model.InventoryCheckCount) error {
+	count.ID = r.d.id()
+	r.d.checkCounts = append(r.d.checkCounts, *count)
+	return nil
+}
+
+func (r fakeChecks) LastCount(checkID, productID int64) (*model.InventoryCheckCount, error) {
+	for i := len(r.d.checkCounts) - 1; i >= 0; i-- {
+		if count := r.d.checkCounts[i]; count.CheckID == checkID && count.ProductID == productID {
+			return &count, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r fakeChecks) StartRound(checkID int64, round int, itemIDs []int64) error {
+	recount := map[int64]bool{}
+	for _, id := range itemIDs {
+		recount[id] = true
+	}
+	items := r.d.checkItems[checkID]
+	for i := range items {
+		items[i].NeedRecount = 0
+		if recount[items[i].ID] {
+			items[i].NeedRecount = 1
+			items[i].Counted = 0
+		}
+	}
+	check := r.d.checks[checkID]
+	check.Round = round
+	r.d.checks[checkID] = check
+	return nil
+}
+
+func (r fakeChecks) Update(check *model.InventoryCheck) error {
+	r.d.checks[check.ID] = *check
+	return nil
+}
+
+func (r fakeChecks) Delete(id int64) error {
+	r.d.deleted[id] = true
+	return nil
+}
+
+func (r fakeChecks) Restore(id int64) error {
+	delete(r.d.deleted, id)
+	return nil
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -17,19 +20,32 @@ type Claims struct {
 
 // GenerateToken 生成JWT令牌
 func GenerateToken(userID int64, username, roleCode, secret string, expireHours int) (string, error) {
+	token, _, err := GenerateAccessToken(userID, username, roleCode, secret, time.Duration(expireHours)*time.Hour)
+	return token, err
+}
+
+// GenerateAccessToken 生成带jti的JWT访问令牌，返回令牌和jti
+func GenerateAccessToken(userID int64, username, roleCode, secret string, ttl time.Duration) (string, string, error) {
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
 		RoleCode: roleCode,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expireHours) * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	signed, err := token.SignedString([]byte(secret))
+	return signed, jti, err
 }
 
 // ParseToken 解析JWT令牌
@@ -48,3 +64,18 @@ func ParseToken(tokenString, secret string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// RandomToken 生成n字节随机数的十六进制字符串
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken 计算令牌的SHA-256摘要，用于服务端存储
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
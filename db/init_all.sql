@@ -14,6 +14,10 @@ USE easywms;
 -- =============================================
 SET FOREIGN_KEY_CHECKS = 0;
 
+DROP TABLE IF EXISTS `base_product_unit`;
+DROP TABLE IF EXISTS `biz_kit_assembly`;
+DROP TABLE IF EXISTS `base_product_kit`;
+DROP TABLE IF EXISTS `biz_inventory_check_count`;
 DROP TABLE IF EXISTS `biz_inventory_check_item`;
 DROP TABLE IF EXISTS `biz_inventory_check`;
 DROP TABLE IF EXISTS `biz_stock_log`;
@@ -27,6 +31,24 @@ DROP TABLE IF EXISTS `base_product`;
 DROP TABLE IF EXISTS `base_category`;
 DROP TABLE IF EXISTS `base_supplier`;
 DROP TABLE IF EXISTS `sys_role_permission`;
+DROP TABLE IF EXISTS `sys_role`;
+DROP TABLE IF EXISTS `sys_menu`;
+DROP TABLE IF EXISTS `sys_refresh_token`;
+DROP TABLE IF EXISTS `sys_token_denylist`;
+DROP TABLE IF EXISTS `sys_login_lock`;
+DROP TABLE IF EXISTS `sys_login_log`;
+DROP TABLE IF EXISTS `sys_user_mfa`;
+DROP TABLE IF EXISTS `sys_user_recovery_code`;
+DROP TABLE IF EXISTS `sys_password_history`;
+DROP TABLE IF EXISTS `sys_api_key_log`;
+DROP TABLE IF EXISTS `sys_api_key_permission`;
+DROP TABLE IF EXISTS `sys_api_key`;
+DROP TABLE IF EXISTS `sys_oidc_state`;
+DROP TABLE IF EXISTS `sys_user_identity`;
+DROP TABLE IF EXISTS `sys_audit_log`;
+DROP TABLE IF EXISTS `sys_doc_sequence`;
+DROP TABLE IF EXISTS `sys_idempotency_key`;
+DROP TABLE IF EXISTS `schema_migrations`;
 DROP TABLE IF EXISTS `sys_permission`;
 DROP TABLE IF EXISTS `sys_user`;
 DROP TABLE IF EXISTS `base_department`;
@@ -54,15 +76,18 @@ CREATE TABLE `sys_user` (
   `username` VARCHAR(64) NOT NULL COMMENT '登录账号',
   `password` VARCHAR(128) NOT NULL COMMENT '登录密码(BCrypt)',
   `real_name` VARCHAR(64) NOT NULL COMMENT '真实姓名',
+  `email` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '邮箱（单点登录按邮箱关联账号）',
   `dept_id` BIGINT NOT NULL COMMENT '部门ID',
   `role_code` VARCHAR(20) NOT NULL COMMENT '角色: ADMIN/W_MGR/BUYER/STAFF',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-禁用',
+  `password_changed_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '密码修改时间，为空须在下次登录时修改',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_username` (`username`),
   KEY `idx_dept_id` (`dept_id`),
-  KEY `idx_role_code` (`role_code`)
+  KEY `idx_role_code` (`role_code`),
+  KEY `idx_email` (`email`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系统用户表';
 
 -- 3. 权限码表
@@ -77,7 +102,7 @@ CREATE TABLE `sys_permission` (
   UNIQUE KEY `uk_code` (`code`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限码表';
 
--- 4. 角色权限关联表
+-- 4. 角色权限关联表（角色定义见 sys_role）
 CREATE TABLE `sys_role_permission` (
   `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   `role_code` VARCHAR(20) NOT NULL COMMENT '角色代码',
@@ -87,6 +112,267 @@ CREATE TABLE `sys_role_permission` (
   UNIQUE KEY `uk_role_perm` (`role_code`, `permission_code`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色权限关联表';
 
+-- 4.1 角色表
+CREATE TABLE `sys_role` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '角色ID',
+  `code` VARCHAR(20) NOT NULL COMMENT '角色代码',
+  `name` VARCHAR(64) NOT NULL COMMENT '角色名称',
+  `description` VARCHAR(255) DEFAULT NULL COMMENT '描述',
+  `builtin` TINYINT NOT NULL DEFAULT 0 COMMENT '1-内置角色(不可删除) 0-自定义角色',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `data_scope` VARCHAR(10) NOT NULL DEFAULT 'SELF' COMMENT '数据权限: ALL-全部 DEPT-本部门及下级 SELF-仅本人',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色表';
+
+-- 4.2 菜单表
+CREATE TABLE `sys_menu` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '菜单ID',
+  `parent_id` BIGINT NOT NULL DEFAULT 0 COMMENT '上级菜单ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '路由名称',
+  `path` VARCHAR(128) NOT NULL COMMENT '路由路径',
+  `component` VARCHAR(255) DEFAULT NULL COMMENT '页面组件，目录为空',
+  `title` VARCHAR(64) NOT NULL COMMENT '菜单标题',
+  `icon` VARCHAR(64) DEFAULT NULL COMMENT '图标',
+  `sort_order` INT NOT NULL DEFAULT 0 COMMENT '排序',
+  `affix_tab` TINYINT NOT NULL DEFAULT 0 COMMENT '1-固定标签页',
+  `permission_code` VARCHAR(50) DEFAULT NULL COMMENT '所需权限码，为空表示所有登录用户可见',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`),
+  KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='菜单表';
+
+-- 4.3 刷新令牌表
+CREATE TABLE `sys_refresh_token` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `token_hash` CHAR(64) NOT NULL COMMENT '刷新令牌SHA-256摘要',
+  `access_jti` VARCHAR(64) DEFAULT NULL COMMENT '同批签发的访问令牌jti',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `revoked_at` DATETIME DEFAULT NULL COMMENT '吊销/轮换时间',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '登录IP',
+  `user_agent` VARCHAR(255) DEFAULT NULL COMMENT '客户端UA',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='刷新令牌表';
+
+-- 4.4 访问令牌黑名单表
+CREATE TABLE `sys_token_denylist` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `jti` VARCHAR(64) NOT NULL COMMENT '访问令牌jti',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `expires_at` DATETIME NOT NULL COMMENT '令牌过期时间，过期后可清理',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_jti` (`jti`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='访问令牌黑名单表';
+
+-- 4.5 登录锁定表
+CREATE TABLE `sys_login_lock` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `subject_type` VARCHAR(16) NOT NULL COMMENT 'USERNAME/IP',
+  `subject` VARCHAR(64) NOT NULL COMMENT '账号或IP',
+  `failures` INT NOT NULL DEFAULT 0 COMMENT '窗口期内连续失败次数',
+  `lock_count` INT NOT NULL DEFAULT 0 COMMENT '累计锁定次数，用于指数退避',
+  `locked_until` DATETIME DEFAULT NULL COMMENT '锁定截止时间',
+  `last_failed_at` DATETIME DEFAULT NULL COMMENT '最近失败时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_subject` (`subject_type`, `subject`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录锁定表';
+
+-- 4.6 登录日志表
+CREATE TABLE `sys_login_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT DEFAULT NULL COMMENT '用户ID（账号不存在时为空）',
+  `username` VARCHAR(64) NOT NULL COMMENT '登录账号',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `user_agent` VARCHAR(255) DEFAULT NULL COMMENT '客户端UA',
+  `success` TINYINT NOT NULL DEFAULT 0 COMMENT '1-成功 0-失败',
+  `message` VARCHAR(255) DEFAULT NULL COMMENT '结果说明',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_username` (`username`),
+  KEY `idx_ip` (`ip`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录日志表';
+
+-- 4.7 两步验证表
+CREATE TABLE `sys_user_mfa` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `secret` VARCHAR(64) NOT NULL COMMENT 'TOTP密钥（Base32）',
+  `enabled` TINYINT NOT NULL DEFAULT 0 COMMENT '1-已启用 0-待确认',
+  `confirmed_at` DATETIME DEFAULT NULL COMMENT '启用时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证表';
+
+-- 4.8 两步验证恢复码表
+CREATE TABLE `sys_user_recovery_code` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `code_hash` CHAR(64) NOT NULL COMMENT '恢复码SHA-256摘要',
+  `used_at` DATETIME DEFAULT NULL COMMENT '使用时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+-- 4.9 历史密码表
+CREATE TABLE `sys_password_history` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `password` VARCHAR(128) NOT NULL COMMENT '历史密码(BCrypt)',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='历史密码表';
+
+-- 4.10 API密钥表
+CREATE TABLE `sys_api_key` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '名称（调用方）',
+  `key_prefix` VARCHAR(16) NOT NULL COMMENT '密钥前缀，用于辨认',
+  `key_hash` CHAR(64) NOT NULL COMMENT '密钥SHA-256摘要',
+  `user_id` BIGINT NOT NULL COMMENT '绑定用户ID（调用时的身份）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '过期时间，为空不过期',
+  `last_used_at` DATETIME DEFAULT NULL COMMENT '最近使用时间',
+  `last_used_ip` VARCHAR(64) DEFAULT NULL COMMENT '最近使用IP',
+  `created_by` BIGINT NOT NULL COMMENT '创建人ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_key_hash` (`key_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥表';
+
+-- 4.11 API密钥权限范围表
+CREATE TABLE `sys_api_key_permission` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `api_key_id` BIGINT NOT NULL COMMENT 'API密钥ID',
+  `permission_code` VARCHAR(50) NOT NULL COMMENT '权限码',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_key_permission` (`api_key_id`, `permission_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥权限范围表';
+
+-- 4.12 API密钥调用日志表
+CREATE TABLE `sys_api_key_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `api_key_id` BIGINT NOT NULL COMMENT 'API密钥ID',
+  `method` VARCHAR(10) NOT NULL COMMENT '请求方法',
+  `path` VARCHAR(255) NOT NULL COMMENT '请求路径',
+  `status_code` INT NOT NULL COMMENT '响应状态码',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_api_key_id` (`api_key_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API密钥调用日志表';
+
+-- 4.13 单点登录请求状态表
+CREATE TABLE `sys_oidc_state` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `state` VARCHAR(64) NOT NULL COMMENT 'state参数',
+  `nonce` VARCHAR(64) NOT NULL COMMENT 'ID令牌nonce',
+  `code_verifier` VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_state` (`state`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单点登录请求状态表';
+
+-- 4.14 外部身份关联表
+CREATE TABLE `sys_user_identity` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `provider` VARCHAR(20) NOT NULL COMMENT '身份来源: OIDC/LDAP',
+  `subject` VARCHAR(255) NOT NULL COMMENT '外部用户标识: OIDC为sub, LDAP为登录账号',
+  `user_id` BIGINT NOT NULL COMMENT '系统用户ID',
+  `email` VARCHAR(128) DEFAULT NULL COMMENT '身份提供商返回的邮箱',
+  `last_login_at` DATETIME DEFAULT NULL COMMENT '最近登录时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_provider_subject` (`provider`, `subject`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='外部身份关联表';
+
+-- 4.15 数据变更审计日志表
+CREATE TABLE `sys_audit_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
+  `username` VARCHAR(50) DEFAULT NULL COMMENT '操作人账号',
+  `api_key_id` BIGINT DEFAULT NULL COMMENT '通过API密钥调用时的密钥ID',
+  `action` VARCHAR(10) NOT NULL COMMENT '操作类型: CREATE/UPDATE/DELETE',
+  `entity` VARCHAR(50) NOT NULL COMMENT '实体类型',
+  `entity_id` VARCHAR(64) NOT NULL COMMENT '实体ID',
+  `before_data` LONGTEXT COMMENT '变更前快照(JSON)',
+  `after_data` LONGTEXT COMMENT '变更后快照(JSON)',
+  `diff` LONGTEXT COMMENT '变更字段(JSON)',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `request_id` VARCHAR(64) DEFAULT NULL COMMENT '请求ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_entity` (`entity`, `entity_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_request_id` (`request_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据变更审计日志表';
+
+-- 4.16 单据编号计数器表
+CREATE TABLE `sys_doc_sequence` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `doc_type` VARCHAR(32) NOT NULL COMMENT '单据类型',
+  `prefix` VARCHAR(64) NOT NULL COMMENT '编号前缀(含日期)',
+  `current_value` BIGINT NOT NULL DEFAULT 0 COMMENT '当前流水号',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_type_prefix` (`doc_type`, `prefix`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单据编号计数器表';
+
+-- 4.17 幂等请求记录表
+CREATE TABLE `sys_idempotency_key` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` BIGINT NOT NULL COMMENT '请求用户ID',
+  `idem_key` VARCHAR(128) NOT NULL COMMENT '幂等键',
+  `method` VARCHAR(10) NOT NULL COMMENT '请求方法',
+  `path` VARCHAR(255) NOT NULL COMMENT '请求路径',
+  `request_hash` CHAR(64) NOT NULL COMMENT '请求指纹(SHA-256)',
+  `status` VARCHAR(20) NOT NULL COMMENT '状态: PROCESSING/COMPLETED',
+  `status_code` INT DEFAULT NULL COMMENT '首次响应状态码',
+  `response_body` LONGTEXT COMMENT '首次响应内容',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_key` (`user_id`, `idem_key`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='幂等请求记录表';
+
+-- 4.18 数据库迁移记录表（迁移脚本见 apps/backend/internal/migrate/migrations）
+CREATE TABLE `schema_migrations` (
+  `version` BIGINT NOT NULL COMMENT '迁移版本',
+  `name` VARCHAR(255) NOT NULL COMMENT '迁移名称',
+  `applied_at` DATETIME NOT NULL COMMENT '执行时间',
+  PRIMARY KEY (`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据库迁移记录表';
+
+-- 本脚本的表结构对应的迁移版本，新增迁移时同步追加
+INSERT INTO `schema_migrations` (`version`, `name`, `applied_at`) VALUES
+(1, 'baseline', NOW()), (2, 'inventory_counting', NOW()), (3, 'kits_and_units', NOW()), (4, 'roles_and_menus', NOW()),
+(5, 'account_security', NOW()), (6, 'audit_and_soft_delete', NOW()), (7, 'document_numbering', NOW()),
+(8, 'procurement_entered_price', NOW()), (9, 'check_count_log_baseline', NOW());
+
 -- 5. 供应商表
 CREATE TABLE `base_supplier` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '供应商ID',
@@ -97,8 +383,10 @@ CREATE TABLE `base_supplier` (
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
-  KEY `idx_name` (`name`)
+  KEY `idx_name` (`name`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='供应商表';
 
 -- 6. 物资分类表
@@ -108,8 +396,10 @@ CREATE TABLE `base_category` (
   `parent_id` BIGINT NOT NULL DEFAULT 0 COMMENT '父分类ID',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
-  KEY `idx_parent_id` (`parent_id`)
+  KEY `idx_parent_id` (`parent_id`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='物资分类表';
 
 -- 7. 物资档案表
@@ -122,19 +412,22 @@ CREATE TABLE `base_product` (
   `unit` VARCHAR(20) NOT NULL COMMENT '计量单位',
   `stock_qty` DECIMAL(14,4) NOT NULL DEFAULT 0.0000 COMMENT '实时库存',
   `alert_threshold` DECIMAL(14,4) NOT NULL DEFAULT 0.0000 COMMENT '预警阈值',
+  `abc_class` CHAR(1) DEFAULT NULL COMMENT 'ABC分类: A/B/C',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1-启用 0-停用',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_sku_code` (`sku_code`),
   KEY `idx_category_id` (`category_id`),
-  KEY `idx_name` (`name`)
+  KEY `idx_name` (`name`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='物资档案表';
 
 -- 8. 采购订单主表
 CREATE TABLE `biz_procurement` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '订单ID',
-  `order_no` VARCHAR(32) NOT NULL COMMENT '采购单号',
+  `order_no` VARCHAR(64) NOT NULL COMMENT '采购单号',
   `applicant_id` BIGINT NOT NULL COMMENT '申请人ID',
   `supplier_id` BIGINT DEFAULT NULL COMMENT '供应商ID',
   `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/APPROVED/ORDERED/DONE',
@@ -142,11 +435,13 @@ CREATE TABLE `biz_procurement` (
   `expected_date` DATE DEFAULT NULL COMMENT '预计到货日期',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_order_no` (`order_no`),
   KEY `idx_applicant_id` (`applicant_id`),
   KEY `idx_supplier_id` (`supplier_id`),
-  KEY `idx_status` (`status`)
+  KEY `idx_status` (`status`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='采购订单主表';
 
 -- 9. 采购明细表
@@ -154,8 +449,11 @@ CREATE TABLE `biz_procurement_item` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
   `procurement_id` BIGINT NOT NULL COMMENT '采购单ID',
   `product_id` BIGINT NOT NULL COMMENT '物资ID',
-  `plan_qty` DECIMAL(14,4) NOT NULL COMMENT '计划数量',
-  `unit_price` DECIMAL(14,2) DEFAULT NULL COMMENT '单价',
+  `plan_qty` DECIMAL(14,4) NOT NULL COMMENT '计划数量（基本单位）',
+  `unit_price` DECIMAL(14,4) DEFAULT NULL COMMENT '单价（基本单位）',
+  `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位',
+  `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量',
+  `entered_price` DECIMAL(14,4) DEFAULT NULL COMMENT '录入单价（录入单位）',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `idx_procurement_id` (`procurement_id`),
@@ -165,7 +463,7 @@ CREATE TABLE `biz_procurement_item` (
 -- 10. 入库单主表
 CREATE TABLE `biz_inbound` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '入库ID',
-  `inbound_no` VARCHAR(32) NOT NULL COMMENT '入库单号',
+  `inbound_no` VARCHAR(64) NOT NULL COMMENT '入库单号',
   `source_id` BIGINT DEFAULT NULL COMMENT '来源采购单ID',
   `is_temporary` TINYINT NOT NULL DEFAULT 0 COMMENT '1-暂估 0-正常',
   `status` TINYINT NOT NULL DEFAULT 0 COMMENT '1-已完成 0-草稿',
@@ -174,10 +472,12 @@ CREATE TABLE `biz_inbound` (
   `remark` TEXT COMMENT '备注',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_inbound_no` (`inbound_no`),
   KEY `idx_source_id` (`source_id`),
-  KEY `idx_status` (`status`)
+  KEY `idx_status` (`status`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='入库单主表';
 
 -- 11. 入库明细表
@@ -185,7 +485,9 @@ CREATE TABLE `biz_inbound_item` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
   `inbound_id` BIGINT NOT NULL COMMENT '入库单ID',
   `product_id` BIGINT NOT NULL COMMENT '物资ID',
-  `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实收数量',
+  `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实收数量（基本单位）',
+  `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位',
+  `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量',
   `location` VARCHAR(64) DEFAULT NULL COMMENT '库位',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -196,7 +498,7 @@ CREATE TABLE `biz_inbound_item` (
 -- 12. 出库主表
 CREATE TABLE `biz_outbound` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '出库ID',
-  `outbound_no` VARCHAR(32) NOT NULL COMMENT '出库单号',
+  `outbound_no` VARCHAR(64) NOT NULL COMMENT '出库单号',
   `applicant_id` BIGINT NOT NULL COMMENT '申请人ID',
   `dept_id` BIGINT NOT NULL COMMENT '领用部门ID',
   `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/APPROVED/DONE/REJECT',
@@ -206,11 +508,13 @@ CREATE TABLE `biz_outbound` (
   `outbound_date` DATETIME DEFAULT NULL COMMENT '出库时间',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_outbound_no` (`outbound_no`),
   KEY `idx_applicant_id` (`applicant_id`),
   KEY `idx_dept_id` (`dept_id`),
-  KEY `idx_status` (`status`)
+  KEY `idx_status` (`status`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='出库主表';
 
 -- 13. 领用明细表
@@ -218,8 +522,11 @@ CREATE TABLE `biz_outbound_item` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '明细ID',
   `outbound_id` BIGINT NOT NULL COMMENT '出库单ID',
   `product_id` BIGINT NOT NULL COMMENT '物资ID',
-  `apply_qty` DECIMAL(14,4) NOT NULL COMMENT '申请数量',
+  `apply_qty` DECIMAL(14,4) NOT NULL COMMENT '申请数量（基本单位）',
   `actual_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '实发数量',
+  `unit` VARCHAR(20) DEFAULT NULL COMMENT '录入单位',
+  `unit_qty` DECIMAL(14,4) DEFAULT NULL COMMENT '录入数量',
+  `kit_id` BIGINT DEFAULT NULL COMMENT '来源套件ID（按套件领用展开）',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `idx_outbound_id` (`outbound_id`),
@@ -230,10 +537,10 @@ CREATE TABLE `biz_outbound_item` (
 CREATE TABLE `biz_stock_log` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '日志ID',
   `product_id` BIGINT NOT NULL COMMENT '物资ID',
-  `type` VARCHAR(10) NOT NULL COMMENT 'IN/OUT/ADJUST',
+  `type` VARCHAR(10) NOT NULL COMMENT 'IN/OUT/ADJUST/KIT_IN/KIT_OUT',
   `change_qty` DECIMAL(14,4) NOT NULL COMMENT '变动数量',
   `snapshot_qty` DECIMAL(14,4) NOT NULL COMMENT '变动后库存',
-  `related_no` VARCHAR(32) DEFAULT NULL COMMENT '关联单号',
+  `related_no` VARCHAR(64) DEFAULT NULL COMMENT '关联单号',
   `operator_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -246,17 +553,24 @@ CREATE TABLE `biz_stock_log` (
 -- 15. 盘点主表
 CREATE TABLE `biz_inventory_check` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '盘点ID',
-  `check_no` VARCHAR(32) NOT NULL COMMENT '盘点单号',
+  `check_no` VARCHAR(64) NOT NULL COMMENT '盘点单号',
   `status` VARCHAR(20) NOT NULL DEFAULT 'CHECKING' COMMENT 'CHECKING/FINISHED',
   `check_date` DATE NOT NULL COMMENT '盘点日期',
   `checker_id` BIGINT DEFAULT NULL COMMENT '盘点人ID',
+  `freeze` TINYINT NOT NULL DEFAULT 0 COMMENT '1-盘点期间冻结出入库 0-不冻结',
+  `snapshot_at` DATETIME DEFAULT NULL COMMENT '账面快照时间',
+  `round` INT NOT NULL DEFAULT 1 COMMENT '当前盘点轮次',
+  `tolerance` DECIMAL(14,4) DEFAULT NULL COMMENT '差异容差，超出需复盘；为空不要求复盘',
+  `cycle_class` CHAR(1) DEFAULT NULL COMMENT '循环盘点生成时的ABC分类',
   `remark` TEXT COMMENT '备注',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_check_no` (`check_no`),
   KEY `idx_status` (`status`),
-  KEY `idx_check_date` (`check_date`)
+  KEY `idx_check_date` (`check_date`),
+  KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点主表';
 
 -- 16. 盘点差异表
@@ -267,12 +581,72 @@ CREATE TABLE `biz_inventory_check_item` (
   `book_qty` DECIMAL(14,4) NOT NULL COMMENT '账面数量',
   `actual_qty` DECIMAL(14,4) NOT NULL COMMENT '实盘数量',
   `diff_qty` DECIMAL(14,4) NOT NULL COMMENT '盈亏数量',
+  `counted` TINYINT NOT NULL DEFAULT 1 COMMENT '1-本轮已盘 0-待盘',
+  `round` INT NOT NULL DEFAULT 1 COMMENT '最近一次盘点的轮次',
+  `need_recount` TINYINT NOT NULL DEFAULT 0 COMMENT '1-差异超出容差需复盘',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `idx_check_id` (`check_id`),
   KEY `idx_product_id` (`product_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点差异表';
 
+-- 17. 盘点计数记录表
+CREATE TABLE `biz_inventory_check_count` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `check_id` BIGINT NOT NULL COMMENT '盘点单ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `round` INT NOT NULL COMMENT '盘点轮次',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '计数数量',
+  `counter_id` BIGINT DEFAULT NULL COMMENT '盘点人ID',
+  `stock_log_id` BIGINT NOT NULL DEFAULT 0 COMMENT '录入时最新的库存流水ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_check_product` (`check_id`, `product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盘点计数记录表';
+
+-- 18. 套件组成表
+CREATE TABLE `base_product_kit` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `kit_id` BIGINT NOT NULL COMMENT '套件物资ID',
+  `product_id` BIGINT NOT NULL COMMENT '组件物资ID',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '每套数量',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_kit_product` (`kit_id`, `product_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='套件组成表';
+
+-- 19. 套件组装单表
+CREATE TABLE `biz_kit_assembly` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '组装单ID',
+  `assembly_no` VARCHAR(64) NOT NULL COMMENT '组装单号',
+  `kit_id` BIGINT NOT NULL COMMENT '套件物资ID',
+  `qty` DECIMAL(14,4) NOT NULL COMMENT '组装数量',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING/DONE/CANCELLED',
+  `operator_id` BIGINT DEFAULT NULL COMMENT '操作人ID',
+  `assembled_at` DATETIME DEFAULT NULL COMMENT '组装完成时间',
+  `remark` TEXT COMMENT '备注',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_assembly_no` (`assembly_no`),
+  KEY `idx_kit_id` (`kit_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='套件组装单表';
+
+-- 20. 产品辅助计量单位表
+CREATE TABLE `base_product_unit` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `product_id` BIGINT NOT NULL COMMENT '物资ID',
+  `unit` VARCHAR(20) NOT NULL COMMENT '辅助单位',
+  `factor` DECIMAL(14,4) NOT NULL COMMENT '换算系数（1辅助单位=多少基本单位）',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_product_unit` (`product_id`, `unit`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='产品辅助计量单位表';
+
 -- =============================================
 -- 第三部分: 插入权限数据
 -- =============================================
@@ -286,6 +660,10 @@ INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
 ('SUPPLIER_MANAGE', '供应商管理', '管理供应商档案', 'supplier'),
 ('DEPARTMENT_MANAGE', '部门管理', '管理部门架构', 'department'),
 ('USER_MANAGE', '用户管理', '管理系统用户', 'user'),
+('ROLE_MANAGE', '角色管理', '管理角色及角色权限', 'user'),
+('MENU_MANAGE', '菜单管理', '维护菜单及排序', 'user'),
+('API_KEY_MANAGE', 'API密钥管理', '管理外部系统调用的API密钥', 'user'),
+('AUDIT_VIEW', '审计日志查看', '查看数据变更审计日志', 'user'),
 ('INIT_STOCK', '期初库存录入', '录入期初库存', 'stock'),
 ('PROCUREMENT_VIEW', '采购单查看', '查看采购申请列表', 'procurement'),
 ('PROCUREMENT_CREATE', '采购申请', '发起采购申请', 'procurement'),
@@ -301,18 +679,42 @@ INSERT INTO `sys_permission` (`code`, `name`, `description`, `module`) VALUES
 ('INVENTORY_VIEW', '库存查看', '查看库存信息', 'inventory'),
 ('INVENTORY_CHECK', '库存盘点', '执行库存盘点', 'inventory'),
 ('INVENTORY_ADJUST', '库存调整', '调整库存数量', 'inventory'),
+('INVENTORY_SUPERVISE', '盘点监盘', '查看盘点账面数量、发起复盘', 'inventory'),
 ('REPORT_VIEW', '报表查看', '查看统计报表', 'report'),
 ('DASHBOARD_VIEW', '仪表盘查看', '查看仪表盘数据', 'dashboard');
 
+INSERT INTO `sys_role` (`code`, `name`, `description`, `builtin`, `data_scope`) VALUES
+('ADMIN', '系统管理员', '系统配置、基础数据管理、采购审批', 1, 'ALL'),
+('BUYER', '采购专员', '供应商管理、采购申请、订单生成', 1, 'ALL'),
+('W_MGR', '仓库管理员', '入库验收、出库审核、库存盘点', 1, 'ALL'),
+('STAFF', '部门员工', '库存查询、物资领用申请', 1, 'DEPT');
+
+-- 菜单（按权限码过滤可见性）
+INSERT INTO `sys_menu` (`id`, `parent_id`, `name`, `path`, `component`, `title`, `icon`, `sort_order`, `affix_tab`, `permission_code`) VALUES
+(1, 0, 'Dashboard', '/dashboard', NULL, '概览', 'lucide:layout-dashboard', -1, 0, NULL),
+(2, 1, 'Analytics', '/analytics', '#/views/dashboard/analytics/index.vue', '分析页', 'lucide:area-chart', 0, 1, NULL),
+(3, 1, 'Workspace', '/workspace', '#/views/dashboard/workspace/index.vue', '工作台', 'carbon:workspace', 0, 0, NULL),
+(4, 0, 'WmsBasicData', '/wms/basic', NULL, '基础数据', 'mdi:package-variant-closed', 10, 0, NULL),
+(5, 4, 'WmsProduct', '/wms/basic/product', '#/views/wms/product/list.vue', '产品管理', 'mdi:package-variant', 0, 0, 'PRODUCT_VIEW'),
+(6, 0, 'WmsProcurement', '/wms/procurement', NULL, '采购管理', 'mdi:cart-outline', 20, 0, NULL),
+(7, 6, 'WmsProcurementList', '/wms/procurement/list', '#/views/wms/procurement/list.vue', '采购单列表', 'mdi:clipboard-list-outline', 0, 0, 'PROCUREMENT_VIEW'),
+(8, 0, 'WmsInbound', '/wms/inbound', NULL, '入库管理', 'mdi:package-down', 30, 0, NULL),
+(9, 8, 'WmsInboundList', '/wms/inbound/list', '#/views/wms/inbound/list.vue', '入库单列表', 'mdi:clipboard-arrow-down-outline', 0, 0, 'INBOUND_VIEW'),
+(10, 0, 'WmsOutbound', '/wms/outbound', NULL, '出库管理', 'mdi:package-up', 40, 0, NULL),
+(11, 10, 'WmsOutboundList', '/wms/outbound/list', '#/views/wms/outbound/list.vue', '出库单列表', 'mdi:clipboard-arrow-up-outline', 0, 0, 'OUTBOUND_VIEW'),
+(12, 0, 'WmsInventory', '/wms/inventory', NULL, '库存管理', 'mdi:warehouse', 50, 0, NULL),
+(13, 12, 'WmsInventoryStock', '/wms/inventory/stock', '#/views/wms/inventory/stock/list.vue', '库存查询', 'mdi:cube-outline', 0, 0, 'INVENTORY_VIEW'),
+(14, 12, 'WmsInventoryCheck', '/wms/inventory/check', '#/views/wms/inventory/check/list.vue', '库存盘点', 'mdi:clipboard-check-outline', 1, 0, 'INVENTORY_CHECK');
+
 -- ADMIN (系统管理员) - 全部权限
 INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
 ('ADMIN', 'BASIC_VIEW'), ('ADMIN', 'BASIC_MANAGE'), ('ADMIN', 'PRODUCT_VIEW'), ('ADMIN', 'PRODUCT_CREATE'),
 ('ADMIN', 'PRODUCT_EDIT'), ('ADMIN', 'PRODUCT_DELETE'), ('ADMIN', 'SUPPLIER_MANAGE'), ('ADMIN', 'DEPARTMENT_MANAGE'),
-('ADMIN', 'USER_MANAGE'), ('ADMIN', 'INIT_STOCK'), ('ADMIN', 'PROCUREMENT_VIEW'), ('ADMIN', 'PROCUREMENT_CREATE'),
+('ADMIN', 'USER_MANAGE'), ('ADMIN', 'ROLE_MANAGE'), ('ADMIN', 'MENU_MANAGE'), ('ADMIN', 'API_KEY_MANAGE'), ('ADMIN', 'AUDIT_VIEW'), ('ADMIN', 'INIT_STOCK'), ('ADMIN', 'PROCUREMENT_VIEW'), ('ADMIN', 'PROCUREMENT_CREATE'),
 ('ADMIN', 'PROCUREMENT_APPROVE'), ('ADMIN', 'PROCUREMENT_ORDER'), ('ADMIN', 'INBOUND_VIEW'), ('ADMIN', 'INBOUND_CREATE'),
 ('ADMIN', 'INBOUND_APPROVE'), ('ADMIN', 'OUTBOUND_VIEW'), ('ADMIN', 'OUTBOUND_CREATE'), ('ADMIN', 'OUTBOUND_APPROVE'),
 ('ADMIN', 'OUTBOUND_EXECUTE'), ('ADMIN', 'INVENTORY_VIEW'), ('ADMIN', 'INVENTORY_CHECK'), ('ADMIN', 'INVENTORY_ADJUST'),
-('ADMIN', 'REPORT_VIEW'), ('ADMIN', 'DASHBOARD_VIEW');
+('ADMIN', 'INVENTORY_SUPERVISE'), ('ADMIN', 'REPORT_VIEW'), ('ADMIN', 'DASHBOARD_VIEW');
 
 -- BUYER (采购专员)
 INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
@@ -325,7 +727,8 @@ INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
 ('W_MGR', 'BASIC_VIEW'), ('W_MGR', 'PRODUCT_VIEW'), ('W_MGR', 'PRODUCT_CREATE'), ('W_MGR', 'PRODUCT_EDIT'),
 ('W_MGR', 'INIT_STOCK'), ('W_MGR', 'INBOUND_VIEW'), ('W_MGR', 'INBOUND_CREATE'), ('W_MGR', 'INBOUND_APPROVE'),
 ('W_MGR', 'OUTBOUND_VIEW'), ('W_MGR', 'OUTBOUND_APPROVE'), ('W_MGR', 'OUTBOUND_EXECUTE'),
-('W_MGR', 'INVENTORY_VIEW'), ('W_MGR', 'INVENTORY_CHECK'), ('W_MGR', 'INVENTORY_ADJUST'), ('W_MGR', 'DASHBOARD_VIEW');
+('W_MGR', 'INVENTORY_VIEW'), ('W_MGR', 'INVENTORY_CHECK'), ('W_MGR', 'INVENTORY_ADJUST'), ('W_MGR', 'INVENTORY_SUPERVISE'),
+('W_MGR', 'DASHBOARD_VIEW');
 
 -- STAFF (部门员工)
 INSERT INTO `sys_role_permission` (`role_code`, `permission_code`) VALUES
@@ -60,6 +60,12 @@ build() {
     success "镜像构建完成"
 }
 
+# 执行数据库迁移（up/down/status），服务启动前数据库结构必须是最新版本
+migrate() {
+    info "执行数据库迁移: ${1:-up}"
+    docker compose run --rm backend ./easywms migrate "${1:-up}"
+}
+
 # 启动服务
 start() {
     info "启动服务..."
@@ -132,6 +138,7 @@ deploy() {
     check_docker
     check_env
     build
+    migrate up
     start
     
     info "等待服务启动..."
@@ -159,6 +166,7 @@ help() {
     echo "命令:"
     echo "  deploy    构建并启动所有服务（首次部署推荐）"
     echo "  build     构建 Docker 镜像"
+    echo "  migrate   执行数据库迁移，可指定 up/down/status，默认 up"
     echo "  start     启动服务"
     echo "  stop      停止服务"
     echo "  restart   重启服务"
@@ -178,6 +186,9 @@ case "${1:-deploy}" in
     build)
         build
         ;;
+    migrate)
+        migrate "$2"
+        ;;
     start)
         start
         ;;